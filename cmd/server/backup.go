@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rvarun11/sqlite-mcp/internal/config"
+	"github.com/rvarun11/sqlite-mcp/internal/handlers"
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+func newBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back up the database into a backup directory",
+		Long: `Copy the database into a backup directory with SQLite's online backup API, or as a
+compacted copy with VACUUM INTO. A JSON file with the SHA-256 checksum is written beside
+each backup, and the oldest backups beyond --keep are removed. Suitable for cron.`,
+		Args: cobra.NoArgs,
+		RunE: runBackup,
+	}
+
+	cmd.Flags().StringP("database", "d", "", "Path to SQLite database file (required)")
+	cmd.Flags().String("dir", "backups", "Directory backups are written to")
+	cmd.Flags().Bool("vacuum", false, "Write a compacted copy with VACUUM INTO instead of using the online backup API")
+	cmd.Flags().String("label", "", "Label appended to the backup file name")
+	cmd.Flags().Int("keep", 0, "Number of backups of the database to keep, 0 keeps all")
+	if err := cmd.MarkFlagRequired("database"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	dbPath, _ := cmd.Flags().GetString("database")
+	dir, _ := cmd.Flags().GetString("dir")
+	vacuum, _ := cmd.Flags().GetBool("vacuum")
+	label, _ := cmd.Flags().GetString("label")
+	keep, _ := cmd.Flags().GetInt("keep")
+
+	debug, _ := cmd.Flags().GetBool("debug")
+	log, err := logger.NewLogger(&config.Config{Debug: debug})
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer syncLogger(log)
+
+	repo, err := repository.NewSQLiteDB(dbPath, log)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	repo.SetBackupDir(dir, keep)
+
+	method := models.BackupOnline
+	if vacuum {
+		method = models.BackupVacuum
+	}
+	result, err := repo.Backup(models.BackupOptions{Method: method, Label: label})
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(handlers.FormatBackupResult(result))
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rvarun11/sqlite-mcp/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the server configuration",
+		Args:  cobra.NoArgs,
+	}
+
+	printCmd := &cobra.Command{
+		Use:   "print",
+		Short: "Print the effective configuration",
+		Long: `Print the configuration the server would run with, merged from the config file,
+SQLITE_MCP_* environment variables and the flags given here, in the config file format.
+Each setting that is not at its default is commented with where it came from. The
+configuration is then validated like on server startup.`,
+		Args: cobra.NoArgs,
+		RunE: runConfigPrint,
+	}
+	addServerFlags(printCmd)
+	cmd.AddCommand(printCmd)
+
+	return cmd
+}
+
+func runConfigPrint(cmd *cobra.Command, args []string) error {
+	sources, err := config.Load(cmd)
+	if err != nil {
+		return err
+	}
+	out, err := config.Effective(cmd, sources)
+	if err != nil {
+		return fmt.Errorf("failed to render configuration: %w", err)
+	}
+	fmt.Print(string(out))
+
+	if _, err := config.NewConfig(cmd); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rvarun11/sqlite-mcp/internal/config"
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+func newCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a database and run seed scripts in it",
+		Long: `Create a new database file and run SQL seed scripts in it, the same way the server does
+for missing databases with --create. The database is removed again if a script fails.`,
+		Args: cobra.NoArgs,
+		RunE: runCreate,
+	}
+
+	cmd.Flags().StringP("database", "d", "", "Path to the SQLite database file to create (required)")
+	cmd.Flags().StringArray("init-sql", nil, "SQL script to run in the new database, repeatable")
+	cmd.Flags().String("init-dir", "", "Directory of .sql scripts to run in the new database in name order, after --init-sql")
+	if err := cmd.MarkFlagRequired("database"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+func runCreate(cmd *cobra.Command, args []string) error {
+	dbPath, _ := cmd.Flags().GetString("database")
+	initSQL, _ := cmd.Flags().GetStringArray("init-sql")
+	initDir, _ := cmd.Flags().GetString("init-dir")
+
+	scripts, err := config.InitScripts(initSQL, initDir)
+	if err != nil {
+		return err
+	}
+
+	debug, _ := cmd.Flags().GetBool("debug")
+	log, err := logger.NewLogger(&config.Config{Debug: debug})
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer syncLogger(log)
+
+	result, err := repository.CreateDatabase(dbPath, scripts, log)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created %s with %d tables, ran %d statements from %d scripts\n", dbPath, result.Tables, result.Statements, len(scripts))
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rvarun11/sqlite-mcp/internal/config"
+	"github.com/rvarun11/sqlite-mcp/internal/handlers"
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+func newDataDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "data-diff <source.db> <target.db>",
+		Short: "Compare the rows of two database files",
+		Long:  `Compare the rows of two SQLite database files table by table. Rows are matched by primary key and reported as inserted, deleted or updated with the changed columns.`,
+		Args:  cobra.ExactArgs(2),
+		RunE:  runDataDiff,
+	}
+
+	cmd.Flags().String("table", "", "Only compare this table")
+	cmd.Flags().Int("limit", 20, "Maximum rows listed per table and change kind")
+	cmd.Flags().String("format", "text", "Output format: text or json")
+
+	return cmd
+}
+
+func runDataDiff(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	if format != "text" && format != "json" {
+		return fmt.Errorf("invalid format %q, expected text or json", format)
+	}
+	table, _ := cmd.Flags().GetString("table")
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	debug, _ := cmd.Flags().GetBool("debug")
+	log, err := logger.NewLogger(&config.Config{Debug: debug})
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer syncLogger(log)
+
+	source, err := repository.NewReadOnlySQLiteDB(args[0], log)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	diff, err := source.DiffDataWithFile(args[1], models.DataDiffOptions{Table: table, Limit: limit})
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	}
+	fmt.Print(handlers.FormatDataDiff(diff))
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rvarun11/sqlite-mcp/internal/config"
+	"github.com/rvarun11/sqlite-mcp/internal/handlers"
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+func newDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <source.db> <target.db>",
+		Short: "Compare the schemas of two database files",
+		Long:  `Compare the tables, columns, indexes, foreign keys, triggers and views of two SQLite database files and print the migration that turns the source schema into the target schema.`,
+		Args:  cobra.ExactArgs(2),
+		RunE:  runDiff,
+	}
+
+	cmd.Flags().String("format", "text", "Output format: text, json or sql (migration statements only)")
+
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	if format != "text" && format != "json" && format != "sql" {
+		return fmt.Errorf("invalid format %q, expected text, json or sql", format)
+	}
+
+	debug, _ := cmd.Flags().GetBool("debug")
+	log, err := logger.NewLogger(&config.Config{Debug: debug})
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer syncLogger(log)
+
+	source, err := repository.NewReadOnlySQLiteDB(args[0], log)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := repository.NewReadOnlySQLiteDB(args[1], log)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	diff, err := source.DiffSchema(target)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	case "sql":
+		for _, statement := range diff.Migration {
+			fmt.Println(strings.TrimSpace(statement) + ";")
+		}
+	default:
+		fmt.Print(handlers.FormatSchemaDiff(diff))
+	}
+	return nil
+}
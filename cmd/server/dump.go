@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+func newDumpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Dump the database as SQL text",
+		Long: `Write the schema and data as SQL text in the format of the sqlite3 shell's .dump,
+e.g. to keep a small database under version control. Load it again with the load command.`,
+		Args: cobra.NoArgs,
+		RunE: runDump,
+	}
+
+	cmd.Flags().StringP("database", "d", "", "Path to SQLite database file (required)")
+	cmd.Flags().StringSlice("table", nil, "Only dump tables matching this LIKE pattern and their indexes and triggers, repeatable")
+	cmd.Flags().StringP("output", "o", "", "File to write the dump to (defaults to stdout)")
+	if err := cmd.MarkFlagRequired("database"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+func newLoadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "load <dump.sql>",
+		Short: "Load a SQL dump into a new database",
+		Long:  `Replay a SQL dump, as written by the dump command or the sqlite3 shell's .dump, into a new or empty database. Use - to read the dump from stdin.`,
+		Args:  cobra.ExactArgs(1),
+		RunE:  runLoad,
+	}
+
+	cmd.Flags().StringP("database", "d", "", "Path to the SQLite database file to create (required)")
+	if err := cmd.MarkFlagRequired("database"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+func runDump(cmd *cobra.Command, args []string) error {
+	dbPath, _ := cmd.Flags().GetString("database")
+	tables, _ := cmd.Flags().GetStringSlice("table")
+	output, _ := cmd.Flags().GetString("output")
+
+	// Opening a missing database would create it
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("database %s does not exist", dbPath)
+	}
+
+	return withRepository(cmd, dbPath, func(repo *repository.SQLiteDB) error {
+		var w io.Writer = os.Stdout
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		_, err := repo.Dump(w, models.DumpOptions{Tables: tables})
+		return err
+	})
+}
+
+func runLoad(cmd *cobra.Command, args []string) error {
+	dbPath, _ := cmd.Flags().GetString("database")
+
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	return withRepository(cmd, dbPath, func(repo *repository.SQLiteDB) error {
+		result, err := repo.LoadDump(r)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Loaded %d statements, %d tables into %s\n", result.Statements, result.Tables, dbPath)
+		return nil
+	})
+}
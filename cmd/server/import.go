@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rvarun11/sqlite-mcp/internal/config"
+	"github.com/rvarun11/sqlite-mcp/internal/handlers"
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+func newImportCSVCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-csv <file.csv>",
+		Short: "Import a CSV file into a table",
+		Long:  `Stream a CSV file into a table, creating the table with inferred column types if it does not exist. Rows that fail to insert are reported with reasons.`,
+		Args:  cobra.ExactArgs(1),
+		RunE:  runImportCSV,
+	}
+
+	cmd.Flags().StringP("database", "d", "", "Path to SQLite database file (required)")
+	cmd.Flags().String("table", "", "Table to import into (defaults to the file name)")
+	cmd.Flags().String("delimiter", ",", "Field delimiter, a single character or 'tab'")
+	cmd.Flags().Bool("no-header", false, "The first row is data, columns are named column_1, column_2, ...")
+	cmd.Flags().String("encoding", "utf-8", "File encoding: utf-8, latin1 or windows-1252")
+	cmd.Flags().Int("batch-size", 1000, "Rows inserted per transaction")
+	if err := cmd.MarkFlagRequired("database"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+func runImportCSV(cmd *cobra.Command, args []string) error {
+	dbPath, _ := cmd.Flags().GetString("database")
+	table, _ := cmd.Flags().GetString("table")
+	delimiterFlag, _ := cmd.Flags().GetString("delimiter")
+	noHeader, _ := cmd.Flags().GetBool("no-header")
+	encoding, _ := cmd.Flags().GetString("encoding")
+	batchSize, _ := cmd.Flags().GetInt("batch-size")
+
+	delimiter, err := handlers.ParseDelimiter(delimiterFlag)
+	if err != nil {
+		return err
+	}
+	if table == "" {
+		table = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+	}
+
+	debug, _ := cmd.Flags().GetBool("debug")
+	log, err := logger.NewLogger(&config.Config{Debug: debug})
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer syncLogger(log)
+
+	repo, err := repository.NewSQLiteDB(dbPath, log)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	// The file was named on the command line, so its directory is allowed
+	repo.SetImportDirs([]string{filepath.Dir(args[0])})
+
+	result, err := repo.ImportCSV(models.CSVImportOptions{
+		Path:      filepath.Base(args[0]),
+		Table:     table,
+		Delimiter: delimiter,
+		NoHeader:  noHeader,
+		Encoding:  encoding,
+		BatchSize: batchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(handlers.FormatImportResult(result))
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/rvarun11/sqlite-mcp/internal/config"
 	"github.com/rvarun11/sqlite-mcp/internal/handlers"
 	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
 	"github.com/rvarun11/sqlite-mcp/internal/repository"
 	"os"
 
@@ -17,18 +18,27 @@ import (
 	"syscall"
 )
 
-var dbPath string
-
 func main() {
 	var rootCmd = &cobra.Command{
-		Use:   "sqlite-mcp",
-		Short: "SQLite MCP Server - A Model Context Protocol server for SQLite operations",
-		Long:  `SQLite MCP Server provides a standardized interface for SQLite database operations through the Model Context Protocol (MCP). It supports schema introspection, query execution, and database modifications.`,
-		Run:   runServer,
+		Use:     "sqlite-mcp",
+		Short:   "SQLite MCP Server - A Model Context Protocol server for SQLite operations",
+		Long:    `SQLite MCP Server provides a standardized interface for SQLite database operations through the Model Context Protocol (MCP). It supports schema introspection, query execution, and database modifications.`,
+		PreRunE: loadConfig,
+		Run:     runServer,
 	}
 
-	rootCmd.Flags().StringVarP(&dbPath, "database", "d", "", "Path to SQLite database file (required)")
-	rootCmd.Flags().Bool("debug", false, "Enable debug mode")
+	rootCmd.PersistentFlags().Bool("debug", false, "Enable debug mode")
+	addServerFlags(rootCmd)
+
+	rootCmd.AddCommand(newDiffCmd())
+	rootCmd.AddCommand(newDataDiffCmd())
+	rootCmd.AddCommand(newMigrateCmd())
+	rootCmd.AddCommand(newImportCSVCmd())
+	rootCmd.AddCommand(newBackupCmd())
+	rootCmd.AddCommand(newDumpCmd())
+	rootCmd.AddCommand(newLoadCmd())
+	rootCmd.AddCommand(newCreateCmd())
+	rootCmd.AddCommand(newConfigCmd())
 
 	err := rootCmd.MarkFlagRequired("database")
 	if err != nil {
@@ -41,6 +51,47 @@ func main() {
 	}
 }
 
+// addServerFlags adds the server settings, which can also be given in the
+// config file and SQLITE_MCP_* environment variables
+func addServerFlags(cmd *cobra.Command) {
+	cmd.Flags().String(config.ConfigFlag, "", "YAML file with settings named like these flags, which the flags and SQLITE_MCP_* environment variables override")
+	cmd.Flags().StringArrayP("database", "d", nil, "SQLite database file as [name=]path, a glob such as 'data/*.db' or :memory:, repeatable; the first is the default (required)")
+	cmd.Flags().Bool("create", false, "Create databases whose file does not exist instead of failing")
+	cmd.Flags().StringArray("init-sql", nil, "SQL script to run in databases created with --create, repeatable")
+	cmd.Flags().String("init-dir", "", "Directory of .sql scripts to run in databases created with --create in name order, after --init-sql")
+	cmd.Flags().Bool("ephemeral", false, "Serve temporary copies of the databases, removed on exit, so the files given with -d stay unchanged")
+	cmd.Flags().StringArray("read-only", nil, "Open the databases whose name matches this pattern read-only, repeatable")
+	cmd.Flags().StringArray("policy", nil, "Limit statements on the databases whose name matches a pattern, as pattern=full|no-ddl|append-only, repeatable")
+	cmd.Flags().StringArray("attach", nil, "Attach another database file read-only to every database as alias=path, so queries can use alias.table, repeatable")
+	cmd.Flags().StringArray("attach-rw", nil, "Like --attach but writable, repeatable")
+	cmd.Flags().String("journal-mode", "", "Journal mode of the databases, e.g. WAL so reads do not wait for writes (default: keep the file's mode)")
+	cmd.Flags().String("synchronous", "", "Synchronous setting of the connections: OFF, NORMAL, FULL or EXTRA (default: SQLite's)")
+	cmd.Flags().Int("busy-timeout", 0, "Milliseconds a connection waits for a lock before failing with SQLITE_BUSY (default 5000)")
+	cmd.Flags().Int("cache-size", 0, "Page cache size per connection in pages, or in KiB when negative (default: SQLite's)")
+	cmd.Flags().Int64("mmap-size", 0, "Bytes of each database to access through memory-mapped I/O (default 0, disabled)")
+	cmd.Flags().Bool("foreign-keys", false, "Enforce foreign key constraints on every connection")
+	cmd.Flags().StringArray("pragma", nil, "Further pragma run on every connection as name=value, repeatable")
+	cmd.Flags().Int("readers", 0, "Connections for reads per database, beside the single writer connection (default 24)")
+	cmd.Flags().Int("max-rows", 0, "Rows the query tool returns at most, further rows are cut off (default 0, unlimited)")
+	cmd.Flags().Duration("query-timeout", 0, "Interrupt query and execute statements running longer than this, e.g. 30s (default 0, no timeout)")
+	cmd.Flags().StringArray("disable-tool", nil, "Hide the tools whose name matches this pattern, e.g. 'import_*', repeatable")
+	cmd.Flags().String("annotations", "", "Path to a YAML file with table and column annotations (defaults to the _mcp_annotations table)")
+	cmd.Flags().String("queries", "", "Path to a YAML file with saved queries (defaults to the _mcp_queries table)")
+	cmd.Flags().Bool("table-tools", false, "Generate insert_<table>, get_<table>_by_pk, update_<table>_by_pk and delete_<table>_by_pk tools for every table")
+	cmd.Flags().String("migrations", "", "Directory of numbered SQL migration files, enables the migration tools")
+	cmd.Flags().StringSlice("import-dir", nil, "Directory the import and diff tools may read files from, repeatable")
+	cmd.Flags().String("export-dir", "", "Directory export_query writes files to, enables the tool")
+	cmd.Flags().String("backup-dir", "", "Directory backups are written to, enables the backup tools")
+	cmd.Flags().Int("backup-keep", 0, "Number of backups of the database to keep in the backup directory, 0 keeps all")
+}
+
+// loadConfig fills the flags not given on the command line from the
+// environment and the config file
+func loadConfig(cmd *cobra.Command, args []string) error {
+	_, err := config.Load(cmd)
+	return err
+}
+
 func runServer(cmd *cobra.Command, args []string) {
 	// Initialize configuration
 	cfg, err := config.NewConfig(cmd)
@@ -57,31 +108,133 @@ func runServer(cmd *cobra.Command, args []string) {
 	}
 	defer syncLogger(logger)
 
-	logger.Infof("Starting SQLite MCP Server: %v", dbPath)
+	logger.Infof("Starting SQLite MCP Server: %v", cfg.DatabasePath)
 
-	// Initialize database
-	repo, err := repository.NewSQLiteDB(cfg.DatabasePath, logger)
-	if err != nil {
-		logger.Fatalf("Failed to initialize database: %v", err)
+	// Temporary copies are removed after the databases are closed
+	var ephemeral *repository.EphemeralDir
+	if cfg.Ephemeral {
+		ephemeral, err = repository.NewEphemeralDir()
+		if err != nil {
+			logger.Fatalf("Failed to set up ephemeral databases: %v", err)
+		}
+		defer ephemeral.Remove()
+	}
+
+	// Initialize databases
+	databases := repository.NewRegistry()
+	defer databases.Close()
+	// Fatalf skips the deferred calls, so remove the temporary copies first
+	fatalf := func(format string, args ...any) {
+		databases.Close()
+		if ephemeral != nil {
+			ephemeral.Remove()
+		}
+		logger.Fatalf(format, args...)
+	}
+	for _, db := range cfg.Databases {
+		inMemory := db.Path == models.MemoryDatabase
+		if ephemeral != nil && !inMemory {
+			template := db.Path
+			if db.Path, err = ephemeral.Copy(db.Name, template); err != nil {
+				fatalf("Failed to copy database %s: %v", db.Name, err)
+			}
+			logger.Infof("Serving a temporary copy of %s, removed on exit", template)
+		}
+		if _, err := os.Stat(db.Path); cfg.Create && !inMemory && os.IsNotExist(err) {
+			result, err := repository.CreateDatabase(db.Path, cfg.InitScripts, logger)
+			if err != nil {
+				fatalf("Failed to create database %s: %v", db.Name, err)
+			}
+			logger.Infof("Created database %s at %s, ran %d seed statements", db.Name, db.Path, result.Statements)
+		}
+		repo, err := repository.NewSQLiteDBWithOptions(db.Path, models.DatabaseOptions{ReadOnly: db.ReadOnly, Policy: db.Policy, Attach: cfg.Attachments, Connection: cfg.Connection}, logger)
+		if err != nil {
+			fatalf("Failed to initialize database %s: %v", db.Name, err)
+		}
+		// An in-memory database is new on every start
+		if inMemory && len(cfg.InitScripts) > 0 {
+			result, err := repo.Seed(cfg.InitScripts)
+			if err != nil {
+				repo.Close()
+				fatalf("Failed to seed database %s: %v", db.Name, err)
+			}
+			logger.Infof("Seeded in-memory database %s, ran %d statements", db.Name, result.Statements)
+		}
+		configureRepository(repo, cfg)
+		repo.SetLimits(cfg.Limits)
+		if err := databases.Add(db.Name, repo); err != nil {
+			repo.Close()
+			fatalf("Failed to register database %s: %v", db.Name, err)
+		}
+		logger.Infof("Serving database %s from %s (read-only: %t, policy: %s)", db.Name, db.Path, db.ReadOnly, db.Policy)
+	}
+	for _, attachment := range cfg.Attachments {
+		logger.Infof("Attaching %s as %s (writable: %t)", attachment.Path, attachment.Alias, attachment.Writable)
+	}
+
+	if cfg.Connection.JournalMode != "" {
+		logger.Infof("Using journal mode %s", cfg.Connection.JournalMode)
+	}
+
+	if cfg.AnnotationsPath != "" {
+		logger.Infof("Using annotation file: %s", cfg.AnnotationsPath)
+	}
+	if cfg.TableTools {
+		logger.Info("Generating table tools")
+	}
+	if cfg.QueriesPath != "" {
+		logger.Infof("Using saved query file: %s", cfg.QueriesPath)
+	}
+	if cfg.MigrationsDir != "" {
+		logger.Infof("Using migrations directory: %s", cfg.MigrationsDir)
+	}
+	if len(cfg.ImportDirs) > 0 {
+		logger.Infof("Using import directories: %v", cfg.ImportDirs)
+	}
+	if cfg.ExportDir != "" {
+		logger.Infof("Using export directory: %s", cfg.ExportDir)
+	}
+	if cfg.BackupDir != "" {
+		logger.Infof("Using backup directory: %s", cfg.BackupDir)
 	}
-	defer repo.Close()
 
 	// Initialize MCP handler
-	mcpHandler := handlers.NewMCPHandler(repo, logger)
+	mcpHandler := handlers.NewMCPHandler(databases, logger)
 
+	// Every tool is registered, the gate hides the disabled ones so that a
+	// reload can change them
+	tools := handlers.NewToolGate(cfg.DisabledTools)
 	mcpServer := server.NewMCPServer(
 		"sqlite-mcp",
 		"1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithToolFilter(tools.Filter),
+		server.WithToolHandlerMiddleware(tools.Middleware),
 	)
+	addTool := func(tool mcp.Tool, handler server.ToolHandlerFunc) {
+		tools.Track(tool.Name)
+		mcpServer.AddTool(tool, handler)
+	}
+
+	listDatabasesTool := mcp.NewTool("list_databases",
+		mcp.WithDescription("List the databases this server serves with their path, table count, size and access settings. Every other tool takes a database argument naming one of them and uses the default database without it."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(listDatabasesTool, mcpHandler.ListDatabases)
 
 	// Get Schema Tool - No parameters needed
 	listTablesTool := mcp.NewTool("get_schema",
-		mcp.WithDescription("List all tables in the SQLite database with their schema information including columns, types, constraints, and indexes"),
+		mcp.WithDescription("List all tables in the SQLite database with their schema information including columns, types, constraints, and indexes. Tables of attached databases are listed as alias.table and can be joined with the main tables in queries"),
+		mcp.WithBoolean("include_inferred",
+			mcp.Description("Also list likely relationships that are not declared as foreign keys, marked as inferred (default false)"),
+		),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithIdempotentHintAnnotation(true),
 	)
-	mcpServer.AddTool(listTablesTool, mcpHandler.GetSchema)
+	addTool(mcpHandler.WithDatabase(listTablesTool, mcpHandler.GetSchema))
 
 	// Query Database Tool
 	queryDatabaseTool := mcp.NewTool("query",
@@ -96,7 +249,7 @@ func runServer(cmd *cobra.Command, args []string) {
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithIdempotentHintAnnotation(true),
 	)
-	mcpServer.AddTool(queryDatabaseTool, mcpHandler.Query)
+	addTool(mcpHandler.WithDatabase(queryDatabaseTool, mcpHandler.Query))
 
 	// Execute Database Tool
 	executeDatabaseTool := mcp.NewTool("execute",
@@ -111,12 +264,631 @@ func runServer(cmd *cobra.Command, args []string) {
 		mcp.WithDestructiveHintAnnotation(true),
 		mcp.WithIdempotentHintAnnotation(false),
 	)
-	mcpServer.AddTool(executeDatabaseTool, mcpHandler.Execute)
+	addTool(mcpHandler.WithDatabase(executeDatabaseTool, mcpHandler.Execute))
+
+	// Insert Rows Tool
+	insertRowsTool := mcp.NewTool("insert_rows",
+		mcp.WithDescription("Insert rows given as JSON objects keyed by column name into a table, in one transaction so either all rows are written or none. Values are bound as parameters, objects and arrays are stored as JSON text. Returns the rowids of the written rows. Prefer this over building INSERT statements for execute."),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to insert into"),
+			mcp.MinLength(1),
+		),
+		mcp.WithArray("rows",
+			mcp.Required(),
+			mcp.Description("Rows as objects mapping column names to values, omitted columns take their default (max 1000)"),
+			mcp.Items(map[string]any{"type": "object"}),
+			mcp.MinItems(1),
+			mcp.MaxItems(1000),
+		),
+		mcp.WithString("on_conflict",
+			mcp.Description("What to do with rows that violate a unique constraint: ignore skips them, replace deletes the existing rows first, upsert updates the existing row with the given columns. Without it a conflict fails the insert."),
+			mcp.Enum(models.OnConflictIgnore, models.OnConflictReplace, models.OnConflictUpsert),
+		),
+		mcp.WithArray("conflict_columns",
+			mcp.Description("Primary key or unique index columns an upsert matches existing rows on, required for upsert"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(false),
+	)
+	addTool(mcpHandler.WithDatabase(insertRowsTool, mcpHandler.InsertRows))
+
+	// Alter Table Tool
+	alterTableTool := mcp.NewTool("alter_table",
+		mcp.WithDescription("Change a table in ways SQLite's ALTER TABLE cannot: drop or retype columns, change column constraints, add or drop table constraints. Performs SQLite's documented table rebuild (create new table, copy data, recreate indexes, triggers and views, foreign_key_check) in one transaction, so either all operations apply or none."),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to alter"),
+			mcp.MinLength(1),
+		),
+		mcp.WithArray("operations",
+			mcp.Required(),
+			mcp.Description("Operations applied in order. Renames run first using ALTER TABLE RENAME COLUMN."),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"op": map[string]any{
+						"type": "string",
+						"enum": []string{"add_column", "drop_column", "rename_column", "retype_column", "modify_column", "add_constraint", "drop_constraint"},
+					},
+					"column":     map[string]any{"type": "string", "description": "Column the operation applies to"},
+					"new_name":   map[string]any{"type": "string", "description": "New column name for rename_column"},
+					"type":       map[string]any{"type": "string", "description": "Column type for add_column, retype_column and modify_column"},
+					"definition": map[string]any{"type": "string", "description": "Column constraints for add_column and modify_column (e.g. 'NOT NULL DEFAULT 0'), or a table constraint for add_constraint (e.g. 'UNIQUE (a, b)')"},
+					"using":      map[string]any{"type": "string", "description": "Expression over the old row filling the column, e.g. 'CAST(price * 100 AS INTEGER)'"},
+					"name":       map[string]any{"type": "string", "description": "Constraint name or full constraint text for drop_constraint"},
+				},
+				"required": []string{"op"},
+			}),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Run the rebuild and roll it back, returning the statements (default false)"),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(false),
+	)
+	addTool(mcpHandler.WithDatabase(alterTableTool, mcpHandler.AlterTable))
+
+	// Profile Table Tool
+	profileTableTool := mcp.NewTool("profile_table",
+		mcp.WithDescription("Profile a table before writing queries: row count and, per column, null fraction, distinct count, min/max, most frequent values and the actual storage class distribution. Large tables are profiled on a sample with estimated distinct counts."),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Name of the table to profile"),
+			mcp.MinLength(1),
+		),
+		mcp.WithNumber("top_n",
+			mcp.Description("Number of most frequent values to report per column (default 5, max 50)"),
+			mcp.Min(1),
+			mcp.Max(50),
+		),
+		mcp.WithNumber("sample_rows",
+			mcp.Description("Maximum number of rows scanned for column statistics (default 100000)"),
+			mcp.Min(1),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(mcpHandler.WithDatabase(profileTableTool, mcpHandler.ProfileTable))
+
+	// Sample Rows Tool
+	sampleRowsTool := mcp.NewTool("sample_rows",
+		mcp.WithDescription("Return a random sample of rows from a table, optionally stratified by a column so every value of that column is represented. Long text values are truncated and BLOBs are summarized."),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Name of the table to sample"),
+			mcp.MinLength(1),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of rows to return (default 10, max 100)"),
+			mcp.Min(1),
+			mcp.Max(100),
+		),
+		mcp.WithString("stratify_by",
+			mcp.Description("Optional column whose values should be sampled proportionally"),
+		),
+		mcp.WithNumber("max_value_length",
+			mcp.Description("Text values longer than this many characters are truncated (default 200)"),
+			mcp.Min(1),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+	)
+	addTool(mcpHandler.WithDatabase(sampleRowsTool, mcpHandler.SampleRows))
+
+	// Schema Diff Tool
+	schemaDiffTool := mcp.NewTool("schema_diff",
+		mcp.WithDescription("Compare the schema of this database with another database file (tables, columns, types, indexes, foreign keys, triggers, views) and return the differences plus the migration DDL. Changes ALTER TABLE cannot express use SQLite's table rebuild procedure."),
+		mcp.WithString("other_database",
+			mcp.Required(),
+			mcp.Description("Name of a served database, or path of a served or attached database or of a file in an import directory, opened read-only"),
+			mcp.MinLength(1),
+		),
+		mcp.WithString("direction",
+			mcp.Description("'to_other' (default) generates the migration that turns this database into the other, 'from_other' the reverse"),
+			mcp.Enum("to_other", "from_other"),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(mcpHandler.WithDatabase(schemaDiffTool, mcpHandler.SchemaDiff))
+
+	// Data Diff Tool
+	dataDiffTool := mcp.NewTool("data_diff",
+		mcp.WithDescription("Compare the rows of this database with another database file, such as yesterday's copy. Per table, rows are matched by primary key and reported as inserted, deleted or updated with the changed columns. Tables without a primary key are matched on full row content."),
+		mcp.WithString("other_database",
+			mcp.Required(),
+			mcp.Description("Name of a served database, or path of a served or attached database or of a file in an import directory, attached read-only"),
+			mcp.MinLength(1),
+		),
+		mcp.WithString("table",
+			mcp.Description("Only compare this table (default: all tables)"),
+		),
+		mcp.WithString("direction",
+			mcp.Description("'to_other' (default) reports how the other database differs from this one, 'from_other' the reverse"),
+			mcp.Enum("to_other", "from_other"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum rows listed per table and change kind (default 20, max 1000); counts are always complete"),
+			mcp.Min(1),
+			mcp.Max(1000),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(mcpHandler.WithDatabase(dataDiffTool, mcpHandler.DataDiff))
+
+	// Annotate Tool
+	annotateTool := mcp.NewTool("annotate",
+		mcp.WithDescription("Add or update human descriptions for a table or column, such as what a cryptic column means, its unit, allowed values and sensitivity. Annotations are shown by get_schema. Only provided fields are changed."),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to annotate"),
+			mcp.MinLength(1),
+		),
+		mcp.WithString("column",
+			mcp.Description("Column to annotate, omit to annotate the table itself"),
+		),
+		mcp.WithString("description",
+			mcp.Description("Human readable description"),
+			mcp.MaxLength(2000),
+		),
+		mcp.WithString("unit",
+			mcp.Description("Unit of measure, e.g. cents, ms, kg"),
+		),
+		mcp.WithArray("enum_values",
+			mcp.Description("Allowed values for coded columns"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString("sensitivity",
+			mcp.Description("Sensitivity tag, e.g. public, internal, pii, secret"),
+		),
+		mcp.WithBoolean("remove",
+			mcp.Description("Remove the annotation instead of updating it (default false)"),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(mcpHandler.WithDatabase(annotateTool, mcpHandler.Annotate))
+
+	// Saved Query Tools
+	listSavedQueriesTool := mcp.NewTool("list_saved_queries",
+		mcp.WithDescription("List the saved queries with their SQL and parameters. Every saved query is also a tool of its own named query_<name>, prefer calling those over writing SQL."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(mcpHandler.WithDatabase(listSavedQueriesTool, mcpHandler.ListSavedQueries))
+
+	saveQueryTool := mcp.NewTool("save_query",
+		mcp.WithDescription("Save a vetted SELECT query under a name, replacing a saved query of the same name. It becomes the tool query_<name> taking the parameters as arguments. The SQL refers to parameters as :name and is checked against the database before saving."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the query: lower case letters, digits and '_', starting with a letter"),
+			mcp.Pattern("^[a-z][a-z0-9_]*$"),
+			mcp.MaxLength(48),
+		),
+		mcp.WithString("description",
+			mcp.Required(),
+			mcp.Description("What the query returns, shown as the description of its tool"),
+			mcp.MaxLength(2000),
+		),
+		mcp.WithString("sql",
+			mcp.Required(),
+			mcp.Description("SELECT query using :name placeholders for the parameters"),
+		),
+		mcp.WithArray("parameters",
+			mcp.Description("Parameters of the query"),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":        map[string]any{"type": "string", "description": "Parameter name as used in the SQL without ':'"},
+					"type":        map[string]any{"type": "string", "enum": []string{models.ParameterString, models.ParameterInteger, models.ParameterNumber, models.ParameterBoolean}},
+					"description": map[string]any{"type": "string"},
+					"required":    map[string]any{"type": "boolean", "description": "Whether callers must pass the parameter (default false)"},
+					"default":     map[string]any{"description": "Value bound when the argument is omitted, NULL without one"},
+				},
+				"required": []string{"name", "type"},
+			}),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(mcpHandler.WithDatabase(saveQueryTool, mcpHandler.SaveQuery))
+
+	removeSavedQueryTool := mcp.NewTool("remove_saved_query",
+		mcp.WithDescription("Remove a saved query and its tool"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the saved query"),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(mcpHandler.WithDatabase(removeSavedQueryTool, mcpHandler.RemoveSavedQuery))
+
+	// Every saved query is a tool of its own, registered again when they change
+	savedQueries := handlers.NewToolSync(mcpServer, tools)
+	syncSavedQueries := func() { savedQueries.Set(mcpHandler.SavedQueryTools()) }
+	mcpHandler.OnSavedQueriesChanged(syncSavedQueries)
+
+	// Infer Relationships Tool
+	inferRelationshipsTool := mcp.NewTool("infer_relationships",
+		mcp.WithDescription("Propose likely relationships for databases without declared foreign keys, using column naming, type compatibility and sampled value containment. Each relationship has a confidence score between 0 and 1."),
+		mcp.WithString("table",
+			mcp.Description("Only report relationships from or to this table"),
+		),
+		mcp.WithNumber("sample_size",
+			mcp.Description("Distinct values checked for containment per candidate (default 1000)"),
+			mcp.Min(1),
+		),
+		mcp.WithNumber("min_confidence",
+			mcp.Description("Drop candidates scoring below this confidence (default 0.5)"),
+			mcp.Min(0),
+			mcp.Max(1),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(mcpHandler.WithDatabase(inferRelationshipsTool, mcpHandler.InferRelationships))
+
+	// Find Join Path Tool
+	findJoinPathTool := mcp.NewTool("find_join_path",
+		mcp.WithDescription("Find the shortest join chains between two tables by following foreign keys, returning ready-to-use FROM ... JOIN ... ON ... clauses. Multi-column foreign keys are joined on all their columns."),
+		mcp.WithString("from_table",
+			mcp.Required(),
+			mcp.Description("Table to start the join from"),
+			mcp.MinLength(1),
+		),
+		mcp.WithString("to_table",
+			mcp.Required(),
+			mcp.Description("Table to reach"),
+			mcp.MinLength(1),
+		),
+		mcp.WithBoolean("include_inferred",
+			mcp.Description("Also follow relationships proposed by infer_relationships, e.g. user_id -> users.id (default false)"),
+		),
+		mcp.WithNumber("max_depth",
+			mcp.Description("Maximum number of joins in a path (default 4, max 6)"),
+			mcp.Min(1),
+			mcp.Max(6),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of paths to return (default 3)"),
+			mcp.Min(1),
+			mcp.Max(20),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(mcpHandler.WithDatabase(findJoinPathTool, mcpHandler.FindJoinPath))
+
+	// Migration Tools, only available with a migrations directory
+	if cfg.MigrationsDir != "" {
+		listMigrationsTool := mcp.NewTool("list_migrations",
+			mcp.WithDescription("List the versioned migrations of the migrations directory with their status: pending, applied, modified (file changed after it was applied) or missing (applied but file deleted)."),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+		)
+		addTool(mcpHandler.WithDatabase(listMigrationsTool, mcpHandler.ListMigrations))
+
+		createMigrationTool := mcp.NewTool("create_migration",
+			mcp.WithDescription("Record schema changes as the next numbered migration file instead of running DDL through execute, so they have a history and can be reverted. The SQL is validated but not applied; use apply_migrations to apply it. Do not include BEGIN/COMMIT, each migration already runs in a transaction."),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("Short description used in the file name, e.g. 'add orders status'"),
+				mcp.MinLength(1),
+			),
+			mcp.WithString("up_sql",
+				mcp.Required(),
+				mcp.Description("SQL statements applying the change"),
+				mcp.MinLength(1),
+			),
+			mcp.WithString("down_sql",
+				mcp.Description("SQL statements reverting the change"),
+			),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(false),
+		)
+		addTool(mcpHandler.WithDatabase(createMigrationTool, mcpHandler.CreateMigration))
+
+		applyMigrationsTool := mcp.NewTool("apply_migrations",
+			mcp.WithDescription("Apply pending migrations in version order. Each migration runs in its own transaction and is recorded in schema_migrations with its checksum. Nothing is applied if an applied migration file was modified."),
+			mcp.WithNumber("target_version",
+				mcp.Description("Only apply migrations up to this version (default: all pending)"),
+				mcp.Min(1),
+			),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+		)
+		addTool(mcpHandler.WithDatabase(applyMigrationsTool, mcpHandler.ApplyMigrations))
+	}
+
+	// Dump Tool
+	dumpTool := mcp.NewTool("dump",
+		mcp.WithDescription("Dump the database as SQL text compatible with the sqlite3 shell's .dump: table definitions, rows as INSERT statements, sqlite_sequence, indexes, triggers and views in one transaction. Small dumps are returned inline; pass path to write the dump to a file in the export directory."),
+		mcp.WithArray("tables",
+			mcp.Description("Only dump tables matching these LIKE patterns, with their indexes and triggers"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("path",
+			mcp.Description("File in the export directory to write the dump to, requires --export-dir"),
+		),
+		mcp.WithBoolean("overwrite",
+			mcp.Description("Replace the file if it already exists (default false)"),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(mcpHandler.WithDatabase(dumpTool, mcpHandler.Dump))
+
+	// Branch Tools
+	createBranchTool := mcp.NewTool("create_branch",
+		mcp.WithDescription("Snapshot the database into a scratch copy and switch this session to it. Every tool then reads and writes the branch while the main database stays untouched, so changes can be tried out, inspected with diff_branch and applied with merge_branch or thrown away with discard_branch. A session works on one branch at a time."),
+		mcp.WithString("name",
+			mcp.Description("Branch name of letters, digits, '_' and '-' (default branch_<n>)"),
+			mcp.MaxLength(64),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+	)
+	addTool(mcpHandler.WithDatabase(createBranchTool, mcpHandler.CreateBranch))
+
+	listBranchesTool := mcp.NewTool("list_branches",
+		mcp.WithDescription("List the open branches with their creation time and size, marking the one this session works on"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(mcpHandler.WithDatabase(listBranchesTool, mcpHandler.ListBranches))
+
+	diffBranchTool := mcp.NewTool("diff_branch",
+		mcp.WithDescription("Show the schema and row changes a branch makes against the main database"),
+		mcp.WithString("name",
+			mcp.Description("Branch to compare (defaults to this session's branch)"),
+		),
+		mcp.WithString("table",
+			mcp.Description("Only compare this table"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum rows listed per change type and table (default 20)"),
+			mcp.Min(1),
+			mcp.Max(1000),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addTool(mcpHandler.WithDatabase(diffBranchTool, mcpHandler.DiffBranch))
+
+	mergeBranchTool := mcp.NewTool("merge_branch",
+		mcp.WithDescription("Apply the changes made on a branch to the main database in one transaction and remove the branch. Rows are merged against the state the branch was created from, so changes made to main in the meantime are kept; rows changed on both sides are reported as conflicts and nothing is merged. A branch that changes the schema can only be merged while main is unchanged."),
+		mcp.WithString("name",
+			mcp.Description("Branch to merge (defaults to this session's branch)"),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(false),
+	)
+	addTool(mcpHandler.WithDatabase(mergeBranchTool, mcpHandler.MergeBranch))
+
+	discardBranchTool := mcp.NewTool("discard_branch",
+		mcp.WithDescription("Remove a branch without merging it; its session works on the main database again"),
+		mcp.WithString("name",
+			mcp.Description("Branch to discard (defaults to this session's branch)"),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(false),
+	)
+	addTool(mcpHandler.WithDatabase(discardBranchTool, mcpHandler.DiscardBranch))
+
+	// Import Tools, only available with import directories
+	if len(cfg.ImportDirs) > 0 {
+		importCSVTool := mcp.NewTool("import_csv",
+			mcp.WithDescription("Load a CSV file from an import directory into a table. A missing table is created with column types inferred from the data; for an existing table the header must match its columns. Rows are inserted in batched transactions and rows that fail are reported with reasons instead of aborting the import."),
+			mcp.WithString("path",
+				mcp.Required(),
+				mcp.Description("Path of the CSV file, relative paths are resolved against the first import directory"),
+				mcp.MinLength(1),
+			),
+			mcp.WithString("table",
+				mcp.Required(),
+				mcp.Description("Table to import into, created if missing"),
+				mcp.MinLength(1),
+			),
+			mcp.WithString("delimiter",
+				mcp.Description("Field delimiter, a single character or 'tab' (default ',')"),
+			),
+			mcp.WithBoolean("header",
+				mcp.Description("Whether the first row holds column names (default true)"),
+			),
+			mcp.WithString("encoding",
+				mcp.Description("File encoding (default utf-8)"),
+				mcp.Enum("utf-8", "latin1", "windows-1252"),
+			),
+			mcp.WithNumber("batch_size",
+				mcp.Description("Rows inserted per transaction (default 1000)"),
+				mcp.Min(1),
+				mcp.Max(50000),
+			),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(false),
+		)
+		addTool(mcpHandler.WithDatabase(importCSVTool, mcpHandler.ImportCSV))
+
+		importJSONTool := mcp.NewTool("import_json",
+			mcp.WithDescription("Load a JSON array of objects or newline delimited JSON objects from an import directory into a table. Nested objects are flattened into prefixed columns (address_city) or stored as JSON text, and nested arrays are stored as JSON text or split into child tables with a foreign key back to the parent row. A missing table is created with column types inferred from the data; records that fail are reported with reasons instead of aborting the import."),
+			mcp.WithString("path",
+				mcp.Required(),
+				mcp.Description("Path of the JSON file, relative paths are resolved against the first import directory"),
+				mcp.MinLength(1),
+			),
+			mcp.WithString("table",
+				mcp.Required(),
+				mcp.Description("Table to import into, created if missing"),
+				mcp.MinLength(1),
+			),
+			mcp.WithString("root",
+				mcp.Description("Dot separated path of the array inside a top-level object, e.g. 'data.items'"),
+			),
+			mcp.WithString("flatten",
+				mcp.Description("How nested objects are stored: 'columns' as prefixed columns (default) or 'json' as JSON text"),
+				mcp.Enum("columns", "json"),
+			),
+			mcp.WithBoolean("split_arrays",
+				mcp.Description("Import nested arrays into child tables named <table>_<field> instead of JSON text (default false)"),
+			),
+			mcp.WithNumber("batch_size",
+				mcp.Description("Records inserted per transaction (default 1000)"),
+				mcp.Min(1),
+				mcp.Max(50000),
+			),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(false),
+		)
+		addTool(mcpHandler.WithDatabase(importJSONTool, mcpHandler.ImportJSON))
+	}
+
+	if cfg.ExportDir != "" {
+		exportQueryTool := mcp.NewTool("export_query",
+			mcp.WithDescription("Write the full result of a SELECT query to a file in the export directory as CSV, JSON lines, a JSON array or SQL INSERT statements. Use this instead of query when the result is large or needed as a file; rows are streamed, not loaded into memory. Returns the file path, row count and size."),
+			mcp.WithString("sql",
+				mcp.Required(),
+				mcp.Description("SELECT query whose result is exported"),
+				mcp.MinLength(1),
+				mcp.MaxLength(10000),
+			),
+			mcp.WithString("path",
+				mcp.Required(),
+				mcp.Description("File to write, relative paths are resolved against the export directory"),
+				mcp.MinLength(1),
+			),
+			mcp.WithString("format",
+				mcp.Description("Output format (defaults to the file extension, then csv)"),
+				mcp.Enum("csv", "jsonl", "json", "sql"),
+			),
+			mcp.WithString("table",
+				mcp.Description("Table named in SQL INSERT statements (defaults to the file name)"),
+			),
+			mcp.WithBoolean("overwrite",
+				mcp.Description("Replace the file if it already exists (default false)"),
+			),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+		)
+		addTool(mcpHandler.WithDatabase(exportQueryTool, mcpHandler.ExportQuery))
+	}
+
+	if cfg.BackupDir != "" {
+		backupTool := mcp.NewTool("backup",
+			mcp.WithDescription("Snapshot the database into the backup directory, e.g. before a risky change. The online method copies pages with SQLite's backup API while other connections keep working; the vacuum method writes a compacted copy with VACUUM INTO. A SHA-256 checksum is stored beside each backup and the oldest backups beyond the retention limit are removed."),
+			mcp.WithString("method",
+				mcp.Description("'online' (default) or 'vacuum' for a compacted copy"),
+				mcp.Enum("online", "vacuum"),
+			),
+			mcp.WithString("label",
+				mcp.Description("Short label appended to the backup file name, e.g. 'before_cleanup'"),
+			),
+			mcp.WithNumber("keep",
+				mcp.Description("Backups of this database to keep, overriding the configured retention"),
+				mcp.Min(1),
+			),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(false),
+		)
+		addTool(mcpHandler.WithDatabase(backupTool, mcpHandler.Backup))
+
+		listBackupsTool := mcp.NewTool("list_backups",
+			mcp.WithDescription("List the backups of the selected database in the backup directory, newest first, with their creation time, method, size and source database"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+		)
+		addTool(mcpHandler.WithDatabase(listBackupsTool, mcpHandler.ListBackups))
+
+		restoreTool := mcp.NewTool("restore",
+			mcp.WithDescription("Replace the database contents with a backup after verifying its checksum. The current state is backed up first, so the restore can be undone by restoring that backup."),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("File name of the backup, as returned by backup or list_backups"),
+				mcp.MinLength(1),
+			),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(false),
+		)
+		addTool(mcpHandler.WithDatabase(restoreTool, mcpHandler.Restore))
+	}
+
+	// Generated tools come last, so they cannot take the name of a built-in one
+	syncSavedQueries()
+
+	// Generated table tools, registered again when a schema changes
+	syncTools := syncSavedQueries
+	var syncTableTools func()
+	if cfg.TableTools {
+		tableTools := handlers.NewToolSync(mcpServer, tools)
+		syncTableTools = func() { tableTools.Set(mcpHandler.TableTools()) }
+		syncTableTools()
+		mcpHandler.OnSchemaChanged(syncTableTools)
+		syncTools = func() {
+			syncSavedQueries()
+			syncTableTools()
+		}
+	}
 
 	//Setup graceful shutdown
-	_, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	if syncTableTools != nil {
+		go watchSchema(ctx, databases, schemaPollInterval, syncTableTools)
+	}
+
+	// Reload the configuration on SIGHUP and when the config file changes
+	reload := &reloader{
+		args:      os.Args[1:],
+		cfg:       cfg,
+		databases: databases,
+		tools:     tools,
+		server:    mcpServer,
+		syncTools: syncTools,
+		logger:    logger,
+	}
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			logger.Info("Received SIGHUP, reloading configuration")
+			reload.reload()
+		}
+	}()
+	if cfg.File != "" {
+		go reload.watch(ctx, cfg.File, configPollInterval)
+	}
+
 	// Handle shutdown signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -137,8 +909,48 @@ func runServer(cmd *cobra.Command, args []string) {
 	logger.Info("SQLite MCP Server stopped")
 }
 
+// configureRepository applies the settings shared by all databases
+func configureRepository(repo *repository.SQLiteDB, cfg *config.Config) {
+	if cfg.AnnotationsPath != "" {
+		repo.SetAnnotationStore(repository.NewFileAnnotationStore(cfg.AnnotationsPath))
+	}
+	if cfg.QueriesPath != "" {
+		repo.SetQueryStore(repository.NewFileQueryStore(cfg.QueriesPath))
+	}
+	if cfg.MigrationsDir != "" {
+		repo.SetMigrationsDir(cfg.MigrationsDir)
+	}
+	if len(cfg.ImportDirs) > 0 {
+		repo.SetImportDirs(cfg.ImportDirs)
+	}
+	if cfg.ExportDir != "" {
+		repo.SetExportDir(cfg.ExportDir)
+	}
+	if cfg.BackupDir != "" {
+		repo.SetBackupDir(cfg.BackupDir, cfg.BackupKeep)
+	}
+}
+
 func syncLogger(logger *zap.SugaredLogger) {
 	if err := logger.Sync(); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to sync logger: %v\n", err)
 	}
 }
+
+// withRepository opens the database with a logger configured from the debug flag
+func withRepository(cmd *cobra.Command, dbPath string, fn func(repo *repository.SQLiteDB) error) error {
+	debug, _ := cmd.Flags().GetBool("debug")
+	log, err := logger.NewLogger(&config.Config{Debug: debug})
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer syncLogger(log)
+
+	repo, err := repository.NewSQLiteDB(dbPath, log)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	return fn(repo)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rvarun11/sqlite-mcp/internal/handlers"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply, revert and inspect versioned migrations",
+		Long: `Manage versioned migrations stored as numbered SQL files, e.g. 0001_create_users.up.sql
+and 0001_create_users.down.sql. Applied migrations are recorded with their checksum in the
+schema_migrations table, and each migration runs in its own transaction.`,
+	}
+
+	cmd.PersistentFlags().StringP("database", "d", "", "Path to SQLite database file (required)")
+	cmd.PersistentFlags().String("dir", "migrations", "Directory of numbered SQL migration files")
+	if err := cmd.MarkPersistentFlagRequired("database"); err != nil {
+		panic(err)
+	}
+
+	up := &cobra.Command{
+		Use:   "up",
+		Short: "Apply pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, _ := cmd.Flags().GetInt64("to")
+			return withMigrations(cmd, func(repo *repository.SQLiteDB) error {
+				applied, err := repo.ApplyMigrations(target)
+				for _, m := range applied {
+					fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+				}
+				if err == nil && len(applied) == 0 {
+					fmt.Println("No pending migrations.")
+				}
+				return err
+			})
+		},
+	}
+	up.Flags().Int64("to", 0, "Only apply migrations up to this version")
+
+	down := &cobra.Command{
+		Use:   "down",
+		Short: "Revert the most recently applied migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			steps, _ := cmd.Flags().GetInt("steps")
+			return withMigrations(cmd, func(repo *repository.SQLiteDB) error {
+				reverted, err := repo.RevertMigrations(steps)
+				for _, m := range reverted {
+					fmt.Printf("Reverted %04d_%s\n", m.Version, m.Name)
+				}
+				if err == nil && len(reverted) == 0 {
+					fmt.Println("No applied migrations.")
+				}
+				return err
+			})
+		},
+	}
+	down.Flags().Int("steps", 1, "Number of migrations to revert")
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of all migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withMigrations(cmd, func(repo *repository.SQLiteDB) error {
+				migrations, err := repo.ListMigrations()
+				if err != nil {
+					return err
+				}
+				fmt.Print(handlers.FormatMigrations(migrations))
+				return nil
+			})
+		},
+	}
+
+	cmd.AddCommand(up, down, status)
+	return cmd
+}
+
+func withMigrations(cmd *cobra.Command, run func(repo *repository.SQLiteDB) error) error {
+	dbPath, _ := cmd.Flags().GetString("database")
+	dir, _ := cmd.Flags().GetString("dir")
+
+	return withRepository(cmd, dbPath, func(repo *repository.SQLiteDB) error {
+		repo.SetMigrationsDir(dir)
+		return run(repo)
+	})
+}
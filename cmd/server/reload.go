@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rvarun11/sqlite-mcp/internal/config"
+	"github.com/rvarun11/sqlite-mcp/internal/handlers"
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// configPollInterval is how often the config file is checked for changes
+const configPollInterval = 2 * time.Second
+
+// reloader re-reads the configuration and applies the settings that can change
+// while the server runs: policies, disabled tools, limits and the log level.
+// The new configuration is validated as a whole first, so a reload applies
+// completely or not at all. Other settings only take effect on restart.
+// Generated tools are registered again too, as their definitions may have
+// been edited outside the server, e.g. in the saved query file.
+type reloader struct {
+	mu        sync.Mutex
+	args      []string // Command line the server was started with
+	cfg       *config.Config
+	databases *repository.Registry
+	tools     *handlers.ToolGate
+	server    *server.MCPServer
+	syncTools func() // Registers the generated tools again
+	logger    *zap.SugaredLogger
+}
+
+// reload reads the configuration again from the command line, the environment
+// and the config file and applies it
+func (r *reloader) reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := loadServerConfig(r.args)
+	if err != nil {
+		r.logger.Errorf("Failed to reload configuration, keeping the current one: %v", err)
+		return
+	}
+
+	// Policies are the only settings that can fail to apply, they change first
+	policies := make(map[string]string)
+	var changes []string
+	for _, db := range cfg.Databases {
+		repo, err := r.databases.Get(db.Name)
+		if err != nil {
+			continue // Added databases are only opened on restart
+		}
+		if previous := repo.Policy(); previous != db.Policy {
+			policies[db.Name] = db.Policy
+			changes = append(changes, fmt.Sprintf("%s from %s to %s", db.Name, previous, db.Policy))
+		}
+	}
+	if err := r.databases.SetPolicies(policies); err != nil {
+		r.logger.Errorf("Failed to reload configuration, keeping the current one: %v", err)
+		return
+	}
+	for _, change := range changes {
+		r.logger.Infof("Changed the policy of %s", change)
+	}
+
+	logger.SetDebug(cfg.Debug)
+	for _, db := range cfg.Databases {
+		if repo, err := r.databases.Get(db.Name); err == nil {
+			repo.SetLimits(cfg.Limits)
+		}
+	}
+	if r.tools.SetDisabled(cfg.DisabledTools) {
+		r.logger.Infof("Changed the exposed tools, disabled: %v", cfg.DisabledTools)
+		r.server.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+	}
+	r.syncTools()
+
+	if restartRequired(r.cfg, cfg) {
+		r.logger.Warn("Reloaded configuration, changes to settings other than policies, disabled tools, limits and debug take effect on restart")
+	} else {
+		r.logger.Info("Reloaded configuration")
+	}
+	r.cfg = cfg
+}
+
+// watch reloads the configuration whenever the config file changes
+func (r *reloader) watch(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := fileVersion(path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if version := fileVersion(path); version != last {
+				last = version
+				r.logger.Infof("Config file %s changed, reloading configuration", path)
+				r.reload()
+			}
+		}
+	}
+}
+
+// fileVersion identifies the content of a file by its modification time and
+// size, empty when the file does not exist
+func fileVersion(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+}
+
+// loadServerConfig parses the server's command line into a new command and
+// builds its configuration, as on startup
+func loadServerConfig(args []string) (*config.Config, error) {
+	cmd := &cobra.Command{Use: "sqlite-mcp"}
+	cmd.Flags().Bool("debug", false, "Enable debug mode")
+	addServerFlags(cmd)
+	if err := cmd.ParseFlags(args); err != nil {
+		return nil, err
+	}
+	if _, err := config.Load(cmd); err != nil {
+		return nil, err
+	}
+	return config.NewConfig(cmd)
+}
+
+// restartRequired reports whether settings other than the ones a reload
+// applies differ between two configurations
+func restartRequired(current, next *config.Config) bool {
+	a, b := *current, *next
+	for _, cfg := range []*config.Config{&a, &b} {
+		cfg.Limits = models.Limits{}
+		cfg.DisabledTools = nil
+		cfg.Debug = false
+		cfg.Databases = slices.Clone(cfg.Databases)
+		for i := range cfg.Databases {
+			cfg.Databases[i].Policy = ""
+		}
+	}
+	return !reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+)
+
+// schemaPollInterval is how often the databases are checked for schema changes
+const schemaPollInterval = time.Second
+
+// watchSchema calls sync whenever the schema of a served database changes
+// through another connection to the file. Tools changing the schema call
+// sync themselves, see MCPHandler.OnSchemaChanged.
+func watchSchema(ctx context.Context, databases *repository.Registry, interval time.Duration, sync func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := schemaVersions(databases)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if versions := schemaVersions(databases); !maps.Equal(versions, last) {
+				last = versions
+				sync()
+			}
+		}
+	}
+}
+
+// schemaVersions returns the schema version of every database by name
+func schemaVersions(databases *repository.Registry) map[string]int64 {
+	versions := make(map[string]int64)
+	for _, name := range databases.Names() {
+		db, err := databases.Get(name)
+		if err != nil {
+			continue
+		}
+		if version, err := db.SchemaVersion(); err == nil {
+			versions[name] = version
+		}
+	}
+	return versions
+}
@@ -2,51 +2,357 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
 	"github.com/spf13/cobra"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
 )
 
 type Config struct {
-	DatabasePath string
-	Debug        bool
+	DatabasePath    string // Path of the default database
+	Databases       []DatabaseConfig
+	Attachments     []models.Attachment // Attached to every database
+	Create          bool                // Create missing databases
+	Ephemeral       bool                // Serve temporary copies of the databases
+	InitScripts     []string            // Seed scripts run in databases created on startup
+	Connection      models.ConnectionOptions
+	Limits          models.Limits
+	DisabledTools   []string // Patterns of tool names hidden from clients
+	File            string   // Config file the settings were read from, if any
+	Debug           bool
+	AnnotationsPath string
+	QueriesPath     string
+	TableTools      bool
+	MigrationsDir   string
+	ImportDirs      []string
+	ExportDir       string
+	BackupDir       string
+	BackupKeep      int
+}
+
+// DatabaseConfig is a database served under a name
+type DatabaseConfig struct {
+	Name     string
+	Path     string
+	ReadOnly bool
+	Policy   string
 }
 
 func NewConfig(cmd *cobra.Command) (*Config, error) {
-	dbPath, _ := cmd.Flags().GetString("database")
-	if dbPath == "" {
+	entries, _ := cmd.Flags().GetStringArray("database")
+	databases, err := parseDatabases(entries)
+	if err != nil {
+		return nil, err
+	}
+	if len(databases) == 0 {
 		return nil, errors.New("database path is required")
 	}
 
-	if err := validateDatabasePath(dbPath); err != nil {
+	create, _ := cmd.Flags().GetBool("create")
+	initSQL, _ := cmd.Flags().GetStringArray("init-sql")
+	initDir, _ := cmd.Flags().GetString("init-dir")
+	initScripts, err := InitScripts(initSQL, initDir)
+	if err != nil {
+		return nil, err
+	}
+	inMemory := slices.ContainsFunc(databases, func(db DatabaseConfig) bool { return db.Path == models.MemoryDatabase })
+	if len(initScripts) > 0 && !create && !inMemory {
+		return nil, errors.New("--init-sql and --init-dir seed new databases and require --create or a :memory: database")
+	}
+	if !create {
+		for _, db := range databases {
+			if _, err := os.Stat(db.Path); os.IsNotExist(err) && db.Path != models.MemoryDatabase {
+				return nil, fmt.Errorf("database %s does not exist at %s, pass --create to create it", db.Name, db.Path)
+			}
+		}
+	}
+
+	readOnly, _ := cmd.Flags().GetStringArray("read-only")
+	policies, _ := cmd.Flags().GetStringArray("policy")
+	if err := applyAccess(databases, readOnly, policies); err != nil {
 		return nil, err
 	}
 
+	attach, _ := cmd.Flags().GetStringArray("attach")
+	attachWritable, _ := cmd.Flags().GetStringArray("attach-rw")
+	attachments, err := parseAttachments(attach, attachWritable)
+	if err != nil {
+		return nil, err
+	}
+
+	connection := connectionOptions(cmd)
+	maxRows, _ := cmd.Flags().GetInt("max-rows")
+	queryTimeout, _ := cmd.Flags().GetDuration("query-timeout")
+	if maxRows < 0 || queryTimeout < 0 {
+		return nil, errors.New("--max-rows and --query-timeout cannot be negative")
+	}
+	disabledTools, _ := cmd.Flags().GetStringArray("disable-tool")
+	for _, pattern := range disabledTools {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("--disable-tool: invalid pattern %s: %w", pattern, err)
+		}
+	}
+
+	ephemeral, _ := cmd.Flags().GetBool("ephemeral")
 	debug, _ := cmd.Flags().GetBool("debug")
+	annotationsPath, _ := cmd.Flags().GetString("annotations")
+	queriesPath, _ := cmd.Flags().GetString("queries")
+	tableTools, _ := cmd.Flags().GetBool("table-tools")
+	migrationsDir, _ := cmd.Flags().GetString("migrations")
+	importDirs, _ := cmd.Flags().GetStringSlice("import-dir")
+	exportDir, _ := cmd.Flags().GetString("export-dir")
+	backupDir, _ := cmd.Flags().GetString("backup-dir")
+	backupKeep, _ := cmd.Flags().GetInt("backup-keep")
 
 	return &Config{
-		DatabasePath: dbPath,
-		Debug:        debug,
+		DatabasePath:    databases[0].Path,
+		Databases:       databases,
+		Attachments:     attachments,
+		Create:          create,
+		Ephemeral:       ephemeral,
+		InitScripts:     initScripts,
+		Connection:      connection,
+		Limits:          models.Limits{MaxRows: maxRows, QueryTimeout: queryTimeout},
+		DisabledTools:   disabledTools,
+		File:            configFile(cmd.Flags()),
+		Debug:           debug,
+		AnnotationsPath: annotationsPath,
+		QueriesPath:     queriesPath,
+		TableTools:      tableTools,
+		MigrationsDir:   migrationsDir,
+		ImportDirs:      importDirs,
+		ExportDir:       exportDir,
+		BackupDir:       backupDir,
+		BackupKeep:      backupKeep,
 	}, nil
 }
 
-func validateDatabasePath(dbPath string) error {
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		dir := dbPath[:len(dbPath)-len(dbPath[findLastSlash(dbPath):])]
-		if dir == "" {
-			dir = "."
+// connectionOptions reads the connection tuning flags, the pragmas are
+// validated when the databases are opened
+func connectionOptions(cmd *cobra.Command) models.ConnectionOptions {
+	journalMode, _ := cmd.Flags().GetString("journal-mode")
+	synchronous, _ := cmd.Flags().GetString("synchronous")
+	busyTimeout, _ := cmd.Flags().GetInt("busy-timeout")
+	cacheSize, _ := cmd.Flags().GetInt("cache-size")
+	mmapSize, _ := cmd.Flags().GetInt64("mmap-size")
+	foreignKeys, _ := cmd.Flags().GetBool("foreign-keys")
+	pragmas, _ := cmd.Flags().GetStringArray("pragma")
+	readers, _ := cmd.Flags().GetInt("readers")
+
+	return models.ConnectionOptions{
+		JournalMode: journalMode,
+		Synchronous: synchronous,
+		BusyTimeout: busyTimeout,
+		CacheSize:   cacheSize,
+		MmapSize:    mmapSize,
+		ForeignKeys: foreignKeys,
+		Pragmas:     pragmas,
+		Readers:     readers,
+	}
+}
+
+// parseDatabases parses --database entries of the form [name=]path, where path
+// may be a glob matching several files or :memory:. Unnamed databases are
+// named after their file without the extension, in-memory ones "memory".
+func parseDatabases(entries []string) ([]DatabaseConfig, error) {
+	var databases []DatabaseConfig
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		name, dbPath := "", entry
+		if i := strings.Index(entry, "="); i > 0 && validDatabaseName(entry[:i]) {
+			name, dbPath = entry[:i], entry[i+1:]
 		}
-		if _, err := os.Stat(dir); os.IsNotExist(err) {
-			return errors.New("database directory does not exist")
+		if dbPath == "" {
+			return nil, fmt.Errorf("database %s has no path", entry)
+		}
+
+		paths := []string{dbPath}
+		if dbPath == models.MemoryDatabase {
+			if name == "" {
+				name = "memory"
+			}
+		} else if strings.ContainsAny(dbPath, "*?[") {
+			if name != "" {
+				return nil, fmt.Errorf("database %s: a glob cannot be named, its databases are named after their files", entry)
+			}
+			matches, err := filepath.Glob(dbPath)
+			if err != nil {
+				return nil, fmt.Errorf("invalid database glob %s: %w", dbPath, err)
+			}
+			paths = paths[:0]
+			for _, match := range matches {
+				if info, err := os.Stat(match); err == nil && info.Mode().IsRegular() {
+					paths = append(paths, match)
+				}
+			}
+			if len(paths) == 0 {
+				return nil, fmt.Errorf("no database files match %s", dbPath)
+			}
+		}
+
+		for _, p := range paths {
+			dbName := name
+			if dbName == "" {
+				dbName = strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))
+			}
+			if !validDatabaseName(dbName) {
+				return nil, fmt.Errorf("database name %q of %s is invalid, name it with name=path using letters, digits, '_' and '-'", dbName, p)
+			}
+			if seen[dbName] {
+				return nil, fmt.Errorf("database %s is configured twice, name the databases with name=path", dbName)
+			}
+			if err := validateDatabasePath(p); err != nil {
+				return nil, fmt.Errorf("database %s: %w", dbName, err)
+			}
+			seen[dbName] = true
+			databases = append(databases, DatabaseConfig{Name: dbName, Path: p, Policy: models.PolicyFull})
+		}
+	}
+	return databases, nil
+}
+
+// applyAccess marks the databases matching --read-only patterns read-only and
+// applies --policy pattern=policy entries in order, patterns matching names
+func applyAccess(databases []DatabaseConfig, readOnly, policies []string) error {
+	for _, pattern := range readOnly {
+		if err := matchDatabases(databases, pattern, func(db *DatabaseConfig) { db.ReadOnly = true }); err != nil {
+			return fmt.Errorf("--read-only: %w", err)
+		}
+	}
+	for _, entry := range policies {
+		pattern, policy, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("--policy %s: expected pattern=policy", entry)
+		}
+		switch policy {
+		case models.PolicyFull, models.PolicyNoDDL, models.PolicyAppendOnly:
+		default:
+			return fmt.Errorf("--policy %s: invalid policy %s, expected %s, %s or %s", entry, policy, models.PolicyFull, models.PolicyNoDDL, models.PolicyAppendOnly)
+		}
+		if err := matchDatabases(databases, pattern, func(db *DatabaseConfig) { db.Policy = policy }); err != nil {
+			return fmt.Errorf("--policy %s: %w", entry, err)
 		}
 	}
 	return nil
 }
 
-func findLastSlash(path string) int {
-	for i := len(path) - 1; i >= 0; i-- {
-		if path[i] == '/' || path[i] == '\\' {
-			return i
+// InitScripts lists the seed scripts to run in a new database: the given
+// files in order, then the .sql files of dir sorted by name
+func InitScripts(files []string, dir string) ([]string, error) {
+	scripts := make([]string, 0, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("seed script %s does not exist", file)
 		}
+		scripts = append(scripts, file)
 	}
-	return -1
+	if dir == "" {
+		return scripts, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed directory: %w", err)
+	}
+	found := false
+	for _, entry := range entries { // ReadDir sorts by name
+		if entry.Type().IsRegular() && strings.EqualFold(filepath.Ext(entry.Name()), ".sql") {
+			scripts = append(scripts, filepath.Join(dir, entry.Name()))
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("seed directory %s has no .sql files", dir)
+	}
+	return scripts, nil
+}
+
+// parseAttachments parses --attach and --attach-rw entries of the form
+// alias=path. Aliases are used unquoted in SQL, so they must be identifiers.
+func parseAttachments(readOnly, writable []string) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	seen := make(map[string]bool)
+	for _, entries := range []struct {
+		flag     string
+		values   []string
+		writable bool
+	}{{"--attach", readOnly, false}, {"--attach-rw", writable, true}} {
+		for _, entry := range entries.values {
+			alias, dbPath, ok := strings.Cut(entry, "=")
+			if !ok || dbPath == "" {
+				return nil, fmt.Errorf("%s %s: expected alias=path", entries.flag, entry)
+			}
+			if !validAlias(alias) {
+				return nil, fmt.Errorf("%s %s: alias %q is invalid, use letters, digits and '_' not starting with a digit", entries.flag, entry, alias)
+			}
+			key := strings.ToLower(alias)
+			if key == "main" || key == "temp" || strings.HasPrefix(key, "_mcp_") {
+				return nil, fmt.Errorf("%s %s: alias %s is reserved", entries.flag, entry, alias)
+			}
+			if seen[key] {
+				return nil, fmt.Errorf("%s %s: alias %s is used twice", entries.flag, entry, alias)
+			}
+			if info, err := os.Stat(dbPath); err != nil || !info.Mode().IsRegular() {
+				return nil, fmt.Errorf("%s %s: database file %s does not exist", entries.flag, entry, dbPath)
+			}
+			seen[key] = true
+			attachments = append(attachments, models.Attachment{Alias: alias, Path: dbPath, Writable: entries.writable})
+		}
+	}
+	return attachments, nil
+}
+
+func matchDatabases(databases []DatabaseConfig, pattern string, apply func(db *DatabaseConfig)) error {
+	matched := false
+	for i := range databases {
+		ok, err := path.Match(pattern, databases[i].Name)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+		if ok {
+			apply(&databases[i])
+			matched = true
+		}
+	}
+	if !matched {
+		return fmt.Errorf("no database is named like %s", pattern)
+	}
+	return nil
+}
+
+func validDatabaseName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func validAlias(alias string) bool {
+	if alias == "" || alias[0] >= '0' && alias[0] <= '9' {
+		return false
+	}
+	for _, r := range alias {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func validateDatabasePath(dbPath string) error {
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		if _, err := os.Stat(filepath.Dir(dbPath)); os.IsNotExist(err) {
+			return errors.New("database directory does not exist")
+		}
+	}
+	return nil
 }
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts the environment variables overriding settings, e.g.
+// SQLITE_MCP_JOURNAL_MODE for --journal-mode
+const EnvPrefix = "SQLITE_MCP_"
+
+// ConfigFlag names the flag, and with EnvPrefix the environment variable,
+// giving the config file
+const ConfigFlag = "config"
+
+// Sources records where each setting that is not at its default came from
+type Sources map[string]string
+
+// Load fills the flags not given on the command line, first from SQLITE_MCP_*
+// environment variables and then from the YAML config file named by --config
+// or SQLITE_MCP_CONFIG, whose keys are the flag names. The command line wins
+// over the environment, which wins over the file.
+func Load(cmd *cobra.Command) (Sources, error) {
+	flags := cmd.Flags()
+	sources := make(Sources)
+	flags.Visit(func(flag *pflag.Flag) {
+		sources[flag.Name] = "command line"
+	})
+
+	path := configFile(flags)
+	if !flags.Changed(ConfigFlag) && path != "" {
+		sources[ConfigFlag] = "environment " + EnvName(ConfigFlag)
+	}
+
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || !configurable(flag) || flag.Changed {
+			return
+		}
+		name := EnvName(flag.Name)
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return
+		}
+		values := []string{value}
+		if isList(flag) {
+			values = strings.Split(value, ",")
+		}
+		for _, v := range values {
+			if setErr := flags.Set(flag.Name, strings.TrimSpace(v)); setErr != nil {
+				err = fmt.Errorf("environment %s: %v", name, setErr)
+				return
+			}
+		}
+		sources[flag.Name] = "environment " + name
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if path != "" {
+		if err := loadFile(flags, path, sources); err != nil {
+			return nil, err
+		}
+	}
+	return sources, nil
+}
+
+// configFile returns the config file given with --config or SQLITE_MCP_CONFIG
+func configFile(flags *pflag.FlagSet) string {
+	if path, _ := flags.GetString(ConfigFlag); path != "" {
+		return path
+	}
+	return os.Getenv(EnvName(ConfigFlag))
+}
+
+// EnvName returns the environment variable overriding a flag
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// loadFile sets the flags still at their default from a config file
+func loadFile(flags *pflag.FlagSet, path string, sources Sources) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s line %d: expected settings as name: value", path, root.Line)
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		name := strings.ReplaceAll(key.Value, "_", "-")
+		flag := flags.Lookup(name)
+		if flag == nil || !configurable(flag) {
+			return fmt.Errorf("%s line %d: unknown setting %s, the settings are the flags listed by --help", path, key.Line, key.Value)
+		}
+		if seen[name] {
+			return fmt.Errorf("%s line %d: %s is set twice", path, key.Line, key.Value)
+		}
+		seen[name] = true
+		if flag.Changed {
+			continue // Set on the command line or in the environment
+		}
+
+		var values []string
+		switch value.Kind {
+		case yaml.ScalarNode:
+			if value.Tag != "!!null" {
+				values = []string{value.Value}
+			}
+		case yaml.SequenceNode:
+			if !isList(flag) {
+				return fmt.Errorf("%s line %d: %s takes a single value, not a list", path, value.Line, key.Value)
+			}
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode {
+					return fmt.Errorf("%s line %d: the items of %s must be plain values", path, item.Line, key.Value)
+				}
+				values = append(values, item.Value)
+			}
+		default:
+			return fmt.Errorf("%s line %d: %s must be a value or a list of values", path, value.Line, key.Value)
+		}
+		for _, v := range values {
+			if err := flags.Set(name, v); err != nil {
+				return fmt.Errorf("%s line %d: %v", path, value.Line, err)
+			}
+		}
+		if len(values) > 0 {
+			sources[name] = "config file " + path
+		}
+	}
+	return nil
+}
+
+// Effective renders the settings of a command as a config file, each setting
+// that is not at its default commented with where it came from
+func Effective(cmd *cobra.Command, sources Sources) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || !configurable(flag) {
+			return
+		}
+		var value any = flag.Value.String()
+		switch {
+		case isList(flag):
+			value = flag.Value.(pflag.SliceValue).GetSlice()
+		case flag.Value.Type() == "bool":
+			value, _ = strconv.ParseBool(flag.Value.String())
+		case flag.Value.Type() == "int" || flag.Value.Type() == "int64":
+			value, _ = strconv.ParseInt(flag.Value.String(), 10, 64)
+		}
+
+		valueNode := &yaml.Node{}
+		if err = valueNode.Encode(value); err != nil {
+			return
+		}
+		if valueNode.Kind == yaml.SequenceNode {
+			valueNode.Style = yaml.FlowStyle
+		}
+		if source, ok := sources[flag.Name]; ok {
+			valueNode.LineComment = "from " + source
+		}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: flag.Name}, valueNode)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(root)
+}
+
+// configurable reports whether a flag can be set from the environment or the
+// config file
+func configurable(flag *pflag.Flag) bool {
+	return flag.Name != "help" && flag.Name != ConfigFlag
+}
+
+func isList(flag *pflag.Flag) bool {
+	_, ok := flag.Value.(pflag.SliceValue)
+	return ok
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func (h *MCPHandler) AlterTable(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling alterTable request")
+
+	var spec models.AlterTableSpec
+	if err := request.BindArguments(&spec); err != nil {
+		return toolError("Invalid arguments, expected 'table' and an 'operations' array"), nil
+	}
+	if spec.Table == "" || len(spec.Operations) == 0 {
+		return toolError("Missing or invalid 'table' or 'operations' argument"), nil
+	}
+
+	result, err := h.sessionRepo(ctx).AlterTable(spec)
+	h.notifySchemaChanged()
+	if err != nil {
+		h.logger.Error("Alter table failed: ", err)
+		return toolError(fmt.Sprintf("Failed to alter table, no changes were made: %v", err)), nil
+	}
+
+	var b strings.Builder
+	if result.DryRun {
+		fmt.Fprintf(&b, "Dry run for table %s, no changes were made.\n", result.Table)
+	} else {
+		fmt.Fprintf(&b, "Altered table %s.\n", result.Table)
+	}
+	for _, index := range result.DroppedIndexes {
+		fmt.Fprintf(&b, "Dropped index %s on a dropped column.\n", index)
+	}
+	fmt.Fprintf(&b, "\nNew definition:\n%s\n\nStatements:\n", result.CreateSQL)
+	for _, statement := range result.Statements {
+		b.WriteString(strings.TrimSpace(statement) + ";\n")
+	}
+
+	return toolText(b.String()), nil
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_AlterTable(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "alter_table",
+			Arguments: map[string]any{
+				"table": "users",
+				"operations": []any{
+					map[string]any{"op": "drop_column", "column": "age"},
+					map[string]any{"op": "modify_column", "column": "name", "type": "TEXT", "definition": "NOT NULL DEFAULT 'anonymous'"},
+				},
+			},
+		},
+	}
+
+	result, err := handler.AlterTable(context.Background(), request)
+	if err != nil {
+		t.Fatalf("AlterTable failed: %v", err)
+	}
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+	if result.IsError {
+		t.Fatalf("Expected successful result, got: %s", textContent.Text)
+	}
+	if !containsString(textContent.Text, "Altered table users") || !containsString(textContent.Text, "DEFAULT 'anonymous'") {
+		t.Errorf("Unexpected output: %s", textContent.Text)
+	}
+}
+
+func TestMCPHandler_AlterTable_Invalid(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "alter_table",
+			Arguments: map[string]any{
+				"table":      "users",
+				"operations": []any{map[string]any{"op": "drop_column", "column": "missing"}},
+			},
+		},
+	}
+
+	result, err := handler.AlterTable(context.Background(), request)
+	if err != nil {
+		t.Fatalf("AlterTable failed: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected error result")
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !containsString(text, "column missing does not exist") {
+		t.Errorf("Unexpected error: %s", text)
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func (h *MCPHandler) Annotate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling annotate request")
+
+	table := request.GetString("table", "")
+	if table == "" {
+		return toolError("Missing or invalid 'table' argument"), nil
+	}
+	column := request.GetString("column", "")
+
+	if request.GetBool("remove", false) {
+		if err := h.sessionRepo(ctx).RemoveAnnotation(table, column); err != nil {
+			h.logger.Error("Removing annotation failed: ", err)
+			return toolError("Failed to remove annotation. Please try again."), nil
+		}
+		h.notifySchemaChanged()
+		return toolText(fmt.Sprintf("Removed annotation for %s.", annotationTarget(table, column))), nil
+	}
+
+	update := models.Annotation{
+		Table:       table,
+		Column:      column,
+		Description: request.GetString("description", ""),
+		Unit:        request.GetString("unit", ""),
+		EnumValues:  request.GetStringSlice("enum_values", nil),
+		Sensitivity: request.GetString("sensitivity", ""),
+	}
+	if update.Description == "" && update.Unit == "" && update.EnumValues == nil && update.Sensitivity == "" {
+		return toolError("Provide at least one of 'description', 'unit', 'enum_values' or 'sensitivity'"), nil
+	}
+
+	annotation, err := h.sessionRepo(ctx).Annotate(update)
+	if err != nil {
+		h.logger.Error("Annotation failed: ", err)
+		return toolError("Failed to store annotation. Please check the table and column names and try again."), nil
+	}
+	h.notifySchemaChanged()
+
+	return toolText(formatAnnotationResponse(annotation)), nil
+}
+
+func formatAnnotationResponse(a *models.Annotation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Annotation for %s:\n", annotationTarget(a.Table, a.Column))
+	if a.Description != "" {
+		fmt.Fprintf(&b, "Description: %s\n", a.Description)
+	}
+	if a.Unit != "" {
+		fmt.Fprintf(&b, "Unit: %s\n", a.Unit)
+	}
+	if len(a.EnumValues) > 0 {
+		fmt.Fprintf(&b, "Values: %s\n", strings.Join(a.EnumValues, ", "))
+	}
+	if a.Sensitivity != "" {
+		fmt.Fprintf(&b, "Sensitivity: %s\n", a.Sensitivity)
+	}
+	return b.String()
+}
+
+func annotationTarget(table, column string) string {
+	if column == "" {
+		return "table " + table
+	}
+	return "column " + table + "." + column
+}
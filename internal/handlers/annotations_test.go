@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_Annotate(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "annotate",
+			Arguments: map[string]any{
+				"table":       "orders",
+				"column":      "price",
+				"description": "Unit price",
+				"unit":        "USD",
+			},
+		},
+	}
+
+	result, err := handler.Annotate(ctx, request)
+	if err != nil {
+		t.Fatalf("Annotate failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected successful result, got error")
+	}
+
+	schema, err := handler.GetSchema(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "get_schema"}})
+	if err != nil {
+		t.Fatalf("GetSchema failed: %v", err)
+	}
+
+	textContent, ok := schema.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+
+	if !containsString(textContent.Text, "-- Unit price [unit: USD]") {
+		t.Errorf("Expected annotation in schema output, got: %s", textContent.Text)
+	}
+}
+
+func TestMCPHandler_Annotate_NoFields(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "annotate",
+			Arguments: map[string]any{
+				"table": "orders",
+			},
+		},
+	}
+
+	result, err := handler.Annotate(ctx, request)
+	if err != nil {
+		t.Fatalf("Annotate failed: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected error result when no annotation fields are given")
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func (h *MCPHandler) Backup(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling backup request")
+
+	result, err := h.mainRepo(ctx).Backup(models.BackupOptions{
+		Method: request.GetString("method", models.BackupOnline),
+		Label:  request.GetString("label", ""),
+		Keep:   request.GetInt("keep", 0),
+	})
+	if err != nil {
+		h.logger.Error("Backup failed: ", err)
+		return toolError(fmt.Sprintf("Failed to back up database: %v", err)), nil
+	}
+
+	return toolText(FormatBackupResult(result)), nil
+}
+
+func (h *MCPHandler) ListBackups(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling listBackups request")
+
+	backups, err := h.mainRepo(ctx).ListBackups()
+	if err != nil {
+		h.logger.Error("Failed to list backups: ", err)
+		return toolError(fmt.Sprintf("Failed to list backups: %v", err)), nil
+	}
+
+	return toolText(FormatBackups(backups)), nil
+}
+
+func (h *MCPHandler) Restore(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling restore request")
+
+	name := request.GetString("name", "")
+	if name == "" {
+		return toolError("Missing or invalid 'name' argument"), nil
+	}
+
+	result, err := h.mainRepo(ctx).Restore(name)
+	h.notifySchemaChanged()
+	if err != nil {
+		h.logger.Error("Restore failed: ", err)
+		return toolError(fmt.Sprintf("Failed to restore backup: %v", err)), nil
+	}
+
+	text := fmt.Sprintf("Restored %s (created %s, sha256 verified)\n", result.Restored.Name, result.Restored.CreatedAt)
+	if result.Safety != nil {
+		text += fmt.Sprintf("The previous state was backed up as %s, restore it to undo.\n", result.Safety.Name)
+	}
+	return toolText(text), nil
+}
+
+// FormatBackupResult renders a new backup and the backups pruned after it
+func FormatBackupResult(result *models.BackupResult) string {
+	var b strings.Builder
+
+	backup := result.Backup
+	fmt.Fprintf(&b, "Backed up database with %s method to %s\nSize: %d bytes\nSHA-256: %s\n", backup.Method, backup.Path, backup.Size, backup.Checksum)
+	if len(result.Pruned) > 0 {
+		fmt.Fprintf(&b, "Removed by retention: %s\n", strings.Join(result.Pruned, ", "))
+	}
+
+	return b.String()
+}
+
+// FormatBackups renders backups, newest first
+func FormatBackups(backups []models.Backup) string {
+	if len(backups) == 0 {
+		return "No backups found."
+	}
+
+	var b strings.Builder
+	b.WriteString("Backups:\n")
+	for _, backup := range backups {
+		fmt.Fprintf(&b, "%s: %s, %s, %d bytes", backup.Name, backup.CreatedAt, backup.Method, backup.Size)
+		if backup.Label != "" {
+			fmt.Fprintf(&b, ", %s", backup.Label)
+		}
+		fmt.Fprintf(&b, "\n  source: %s\n", backup.Source)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_BackupAndRestore(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	handler.repo.SetBackupDir(t.TempDir(), 0)
+
+	call := func(name string, fn func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) (string, bool) {
+		t.Helper()
+		result, err := fn(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: args}})
+		if err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		return result.Content[0].(*mcp.TextContent).Text, result.IsError
+	}
+
+	text, isError := call("backup", handler.Backup, map[string]any{"method": "vacuum", "label": "nightly"})
+	if isError || !containsString(text, "Backed up database with vacuum method") || !containsString(text, "SHA-256:") {
+		t.Fatalf("Unexpected backup output: %s", text)
+	}
+
+	backups, err := handler.repo.ListBackups()
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("Expected one backup, got %v (%v)", backups, err)
+	}
+	text, isError = call("list_backups", handler.ListBackups, nil)
+	if isError || !containsString(text, backups[0].Name) || !containsString(text, "nightly") {
+		t.Errorf("Unexpected list output: %s", text)
+	}
+
+	text, isError = call("restore", handler.Restore, map[string]any{"name": backups[0].Name})
+	if isError || !containsString(text, "sha256 verified") || !containsString(text, "pre_restore") {
+		t.Errorf("Unexpected restore output: %s", text)
+	}
+
+	text, isError = call("restore", handler.Restore, map[string]any{"name": "missing.db"})
+	if !isError || !containsString(text, "does not exist") {
+		t.Errorf("Expected an error for a missing backup, got: %s", text)
+	}
+}
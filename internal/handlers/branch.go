@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func (h *MCPHandler) CreateBranch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling createBranch request")
+
+	branch, err := h.sessionBranches(ctx).Create(sessionID(ctx), request.GetString("name", ""))
+	if err != nil {
+		h.logger.Error("Failed to create branch: ", err)
+		return toolError(fmt.Sprintf("Failed to create branch: %v", err)), nil
+	}
+
+	return toolText(fmt.Sprintf("Created branch %s and switched this session to it.\n"+
+		"All tools now work on the copy at %s, the main database is untouched until merge_branch.\n", branch.Name, branch.Path)), nil
+}
+
+func (h *MCPHandler) ListBranches(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling listBranches request")
+
+	return toolText(FormatBranches(h.sessionBranches(ctx).List(sessionID(ctx)))), nil
+}
+
+func (h *MCPHandler) DiffBranch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling diffBranch request")
+
+	diff, err := h.sessionBranches(ctx).Diff(sessionID(ctx), request.GetString("name", ""), models.DataDiffOptions{
+		Table: request.GetString("table", ""),
+		Limit: request.GetInt("limit", 0),
+	})
+	if err != nil {
+		h.logger.Error("Branch diff failed: ", err)
+		return toolError(fmt.Sprintf("Failed to compare branch: %v", err)), nil
+	}
+
+	return toolText(fmt.Sprintf("Changes on branch %s:\n\n%s\n%s", diff.Branch, FormatSchemaDiff(diff.Schema), FormatDataDiff(diff.Data))), nil
+}
+
+func (h *MCPHandler) MergeBranch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling mergeBranch request")
+
+	result, err := h.sessionBranches(ctx).Merge(sessionID(ctx), request.GetString("name", ""))
+	h.notifySchemaChanged()
+	if err != nil {
+		h.logger.Error("Branch merge failed: ", err)
+		return toolError(fmt.Sprintf("Failed to merge branch: %v", err)), nil
+	}
+	if !result.Merged {
+		return toolError(FormatBranchMergeResult(result)), nil
+	}
+
+	return toolText(FormatBranchMergeResult(result)), nil
+}
+
+func (h *MCPHandler) DiscardBranch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling discardBranch request")
+
+	branch, err := h.sessionBranches(ctx).Discard(sessionID(ctx), request.GetString("name", ""))
+	if err != nil {
+		h.logger.Error("Failed to discard branch: ", err)
+		return toolError(fmt.Sprintf("Failed to discard branch: %v", err)), nil
+	}
+
+	text := fmt.Sprintf("Discarded branch %s, its changes were not merged.\n", branch.Name)
+	if branch.Active {
+		text += "This session works on the main database again.\n"
+	}
+	return toolText(text), nil
+}
+
+// FormatBranches renders the open branches, marking the session's own
+func FormatBranches(branches []models.Branch) string {
+	if len(branches) == 0 {
+		return "No branches. This session works on the main database."
+	}
+
+	var b strings.Builder
+	b.WriteString("Branches:\n")
+	for _, branch := range branches {
+		marker := " "
+		if branch.Active {
+			marker = "*"
+		}
+		fmt.Fprintf(&b, "%s %s: created %s, %d bytes\n  path: %s\n", marker, branch.Name, branch.CreatedAt, branch.Size, branch.Path)
+	}
+	b.WriteString("(* the branch this session works on)\n")
+
+	return b.String()
+}
+
+// FormatBranchMergeResult renders the merged changes or the conflicts that
+// stopped the merge
+func FormatBranchMergeResult(result *models.BranchMergeResult) string {
+	var b strings.Builder
+
+	if !result.Merged {
+		fmt.Fprintf(&b, "Branch %s was not merged, these rows were changed on the branch and in main:\n", result.Branch)
+		for _, conflict := range result.Conflicts {
+			keys := make([]string, 0, len(conflict.Key))
+			for col := range conflict.Key {
+				keys = append(keys, col)
+			}
+			sort.Strings(keys)
+			fmt.Fprintf(&b, "  %s %s: %s\n", conflict.Table, formatRow(keys, conflict.Key), conflict.Reason)
+		}
+		b.WriteString("Resolve them on the branch or in main and merge again, or discard the branch.\n")
+		return b.String()
+	}
+
+	if result.FastForward {
+		fmt.Fprintf(&b, "Merged branch %s by replacing main with it, main was unchanged since the branch was created.\n", result.Branch)
+		if len(result.Schema) > 0 {
+			b.WriteString("Schema changes:\n")
+			for _, statement := range result.Schema {
+				fmt.Fprintf(&b, "  %s\n", statement)
+			}
+		}
+	} else {
+		fmt.Fprintf(&b, "Merged branch %s into main.\n", result.Branch)
+		if len(result.Tables) == 0 {
+			b.WriteString("The branch had no changes.\n")
+		}
+		for _, table := range result.Tables {
+			fmt.Fprintf(&b, "  %s: %d inserted, %d updated, %d deleted\n", table.Table, table.Inserted, table.Updated, table.Deleted)
+		}
+	}
+	b.WriteString("The branch was removed, the session that worked on it is back on the main database.\n")
+
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_Branches(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	call := func(name string, fn func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) (string, bool) {
+		t.Helper()
+		result, err := fn(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: args}})
+		if err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		return result.Content[0].(*mcp.TextContent).Text, result.IsError
+	}
+
+	text, isError := call("create_branch", handler.CreateBranch, map[string]any{"name": "cleanup"})
+	if isError || !containsString(text, "Created branch cleanup") {
+		t.Fatalf("Unexpected create output: %s", text)
+	}
+	if _, isError := call("execute", handler.Execute, map[string]any{"sql": "INSERT INTO users (name, email) VALUES ('Branch', 'branch@example.com')"}); isError {
+		t.Fatal("Execute on the branch failed")
+	}
+	main, err := handler.repo.Query("SELECT COUNT(*) AS n FROM users WHERE name = 'Branch'")
+	if err != nil || main.Rows[0]["n"] != int64(0) {
+		t.Fatalf("Expected main to be untouched, got %v (%v)", main, err)
+	}
+
+	text, isError = call("list_branches", handler.ListBranches, nil)
+	if isError || !containsString(text, "* cleanup") {
+		t.Errorf("Unexpected list output: %s", text)
+	}
+	text, isError = call("diff_branch", handler.DiffBranch, nil)
+	if isError || !containsString(text, "Table: users") || !containsString(text, "branch@example.com") {
+		t.Errorf("Unexpected diff output: %s", text)
+	}
+
+	text, isError = call("merge_branch", handler.MergeBranch, nil)
+	if isError || !containsString(text, "users: 1 inserted, 0 updated, 0 deleted") {
+		t.Fatalf("Unexpected merge output: %s", text)
+	}
+	main, err = handler.repo.Query("SELECT COUNT(*) AS n FROM users WHERE name = 'Branch'")
+	if err != nil || main.Rows[0]["n"] != int64(1) {
+		t.Errorf("Expected the merged row in main, got %v (%v)", main, err)
+	}
+
+	if text, isError = call("discard_branch", handler.DiscardBranch, nil); !isError || !containsString(text, "does not work on a branch") {
+		t.Errorf("Expected an error without a branch, got: %s", text)
+	}
+}
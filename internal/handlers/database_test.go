@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+)
+
+func TestMCPHandler_WithDatabase(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	archivePath := filepath.Join(t.TempDir(), "archive.db")
+	setup, err := repository.NewSQLiteDB(archivePath, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if _, err := setup.Execute("CREATE TABLE events (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	setup.Close()
+	archive, err := repository.NewSQLiteDBWithOptions(archivePath, models.DatabaseOptions{ReadOnly: true}, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := handler.databases.Add("archive", archive); err != nil {
+		t.Fatalf("Failed to register database: %v", err)
+	}
+
+	queryTool, query := handler.WithDatabase(mcp.NewTool("query", mcp.WithReadOnlyHintAnnotation(true)), handler.Query)
+	_, execute := handler.WithDatabase(mcp.NewTool("execute", mcp.WithReadOnlyHintAnnotation(false)), handler.Execute)
+	if _, ok := queryTool.InputSchema.Properties["database"]; !ok {
+		t.Error("Expected a database argument with two databases")
+	}
+
+	call := func(fn func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) (string, bool) {
+		t.Helper()
+		result, err := fn(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}})
+		if err != nil {
+			t.Fatalf("Call failed: %v", err)
+		}
+		return result.Content[0].(*mcp.TextContent).Text, result.IsError
+	}
+
+	if text, isError := call(query, map[string]any{"sql": "SELECT * FROM events", "database": "archive"}); isError {
+		t.Errorf("Expected the query to run on archive, got: %s", text)
+	}
+	if text, isError := call(query, map[string]any{"sql": "SELECT * FROM users"}); isError {
+		t.Errorf("Expected the query to run on the default database, got: %s", text)
+	}
+	if text, isError := call(execute, map[string]any{"sql": "INSERT INTO events VALUES (1)", "database": "archive"}); !isError || !containsString(text, "archive is read-only") {
+		t.Errorf("Expected a read-only error, got: %s", text)
+	}
+	if text, isError := call(query, map[string]any{"sql": "SELECT 1", "database": "missing"}); !isError || !containsString(text, "does not exist") {
+		t.Errorf("Expected an unknown database error, got: %s", text)
+	}
+
+	text, _ := call(handler.ListDatabases, nil)
+	if !containsString(text, "test: ") || !containsString(text, "archive: "+archivePath) || !containsString(text, "read-only") {
+		t.Errorf("Unexpected list output: %s", text)
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func (h *MCPHandler) DataDiff(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling dataDiff request")
+
+	other := request.GetString("other_database", "")
+	if other == "" {
+		return toolError("Missing or invalid 'other_database' argument"), nil
+	}
+	other, err := h.otherDatabase(ctx, other)
+	if err != nil {
+		return toolError(fmt.Sprintf("Invalid 'other_database' argument: %v", err)), nil
+	}
+
+	opts := models.DataDiffOptions{
+		Table: request.GetString("table", ""),
+		Limit: request.GetInt("limit", 0),
+	}
+	switch direction := request.GetString("direction", "to_other"); direction {
+	case "to_other":
+	case "from_other":
+		opts.FromOther = true
+	default:
+		return toolError("Invalid 'direction' argument, expected 'to_other' or 'from_other'"), nil
+	}
+
+	diff, err := h.sessionRepo(ctx).DiffDataWithFile(other, opts)
+	if err != nil {
+		h.logger.Error("Data diff failed: ", err)
+		return toolError("Failed to compare data. Please check that the other database file and the table name exist and try again."), nil
+	}
+
+	return toolText(FormatDataDiff(diff)), nil
+}
+
+// FormatDataDiff renders a data diff with its summary counts and changed rows
+func FormatDataDiff(diff *models.DataDiff) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Data Diff:\nSource: %s\nTarget: %s\n\n", diff.Source, diff.Target)
+	if diff.Identical {
+		b.WriteString("Data is identical.\n")
+		return b.String()
+	}
+
+	for _, table := range diff.Tables {
+		fmt.Fprintf(&b, "Table: %s", table.Table)
+		if len(table.Key) > 0 {
+			fmt.Fprintf(&b, " (key: %s)", strings.Join(table.Key, ", "))
+		}
+		fmt.Fprintf(&b, "\n  %d inserted, %d deleted, %d updated\n", table.Inserted, table.Deleted, table.Updated)
+		if table.Note != "" {
+			fmt.Fprintf(&b, "  Note: %s\n", table.Note)
+		}
+
+		for _, row := range table.InsertedRows {
+			fmt.Fprintf(&b, "  + %s\n", formatRow(table.Columns, row))
+		}
+		for _, row := range table.DeletedRows {
+			fmt.Fprintf(&b, "  - %s\n", formatRow(table.Columns, row))
+		}
+		for _, row := range table.UpdatedRows {
+			changes := make([]string, len(row.Changes))
+			for i, change := range row.Changes {
+				changes[i] = fmt.Sprintf("%s: %v -> %v", change.Column, formatValue(change.From), formatValue(change.To))
+			}
+			fmt.Fprintf(&b, "  ~ %s: %s\n", formatRow(table.Key, row.Key), strings.Join(changes, ", "))
+		}
+		if table.Truncated {
+			b.WriteString("  (row lists truncated, increase limit to see more)\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(diff.SkippedTables) > 0 {
+		b.WriteString("Skipped tables:\n")
+		for _, table := range diff.SkippedTables {
+			fmt.Fprintf(&b, "  %s: %s\n", table.Table, table.Reason)
+		}
+	}
+
+	return b.String()
+}
+
+func formatRow(columns []string, row map[string]any) string {
+	values := make([]string, len(columns))
+	for i, col := range columns {
+		values[i] = fmt.Sprintf("%s=%v", col, formatValue(row[col]))
+	}
+	return "(" + strings.Join(values, ", ") + ")"
+}
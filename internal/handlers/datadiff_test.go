@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+)
+
+func TestMCPHandler_DataDiff(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	otherPath := filepath.Join(dir, "other.db")
+	other, err := repository.NewSQLiteDB(otherPath, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to initialize other database: %v", err)
+	}
+	if _, err := other.Execute(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)`); err != nil {
+		t.Fatalf("Failed to create users table: %v", err)
+	}
+	if _, err := other.Execute(`INSERT INTO users (id, name) VALUES (1, 'Johnny')`); err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+	other.Close()
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "data_diff",
+			Arguments: map[string]any{
+				"other_database": otherPath,
+				"table":          "users",
+			},
+		},
+	}
+
+	// Only served and attached databases and files in import directories
+	result, err := handler.DataDiff(context.Background(), request)
+	if err != nil {
+		t.Fatalf("DataDiff failed: %v", err)
+	}
+	if !result.IsError || !containsString(result.Content[0].(*mcp.TextContent).Text, "not served, attached or inside an import directory") {
+		t.Fatalf("Expected a file outside the import directories to be refused, got %+v", result)
+	}
+
+	handler.repo.SetImportDirs([]string{dir})
+	request.Params.Arguments.(map[string]any)["other_database"] = "other.db"
+	result, err = handler.DataDiff(context.Background(), request)
+	if err != nil {
+		t.Fatalf("DataDiff failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected successful result, got error")
+	}
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+
+	for _, expected := range []string{"Table: users (key: id)", "0 inserted, 2 deleted, 1 updated", "~ (id=1): name: John Doe -> Johnny", "columns only in source"} {
+		if !containsString(textContent.Text, expected) {
+			t.Errorf("Expected %q in diff output, got: %s", expected, textContent.Text)
+		}
+	}
+}
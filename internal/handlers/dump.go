@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+// maxInlineDumpBytes caps dumps returned in the tool result instead of a file
+const maxInlineDumpBytes = 1 << 20
+
+var errDumpTooLarge = errors.New("dump is too large to return inline")
+
+func (h *MCPHandler) Dump(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling dump request")
+
+	opts := models.DumpOptions{Tables: request.GetStringSlice("tables", nil)}
+
+	if path := request.GetString("path", ""); path != "" {
+		result, err := h.sessionRepo(ctx).DumpToFile(path, request.GetBool("overwrite", false), opts)
+		if err != nil {
+			h.logger.Error("Dump failed: ", err)
+			return toolError(fmt.Sprintf("Failed to dump database: %v", err)), nil
+		}
+		return toolText(FormatDumpResult(result)), nil
+	}
+
+	var b strings.Builder
+	if _, err := h.sessionRepo(ctx).Dump(&limitedWriter{w: &b, remaining: maxInlineDumpBytes}, opts); err != nil {
+		h.logger.Error("Dump failed: ", err)
+		if errors.Is(err, errDumpTooLarge) {
+			return toolError(fmt.Sprintf("The dump exceeds %d bytes. Pass 'path' to write it to a file in the export directory, or limit it with 'tables'.", maxInlineDumpBytes)), nil
+		}
+		return toolError(fmt.Sprintf("Failed to dump database: %v", err)), nil
+	}
+
+	return toolText(b.String()), nil
+}
+
+// FormatDumpResult renders where a dump was written and what it contains
+func FormatDumpResult(result *models.DumpResult) string {
+	return fmt.Sprintf("Dumped %d tables with %d rows and %d indexes, triggers and views to %s\nSize: %d bytes\n",
+		result.Tables, result.Rows, result.Objects, result.Path, result.Bytes)
+}
+
+// limitedWriter fails once more than remaining bytes are written
+type limitedWriter struct {
+	w         io.Writer
+	remaining int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.remaining {
+		return 0, errDumpTooLarge
+	}
+	l.remaining -= len(p)
+	return l.w.Write(p)
+}
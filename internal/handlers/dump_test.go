@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_Dump(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	call := func(args map[string]any) (string, bool) {
+		t.Helper()
+		result, err := handler.Dump(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "dump", Arguments: args}})
+		if err != nil {
+			t.Fatalf("Dump failed: %v", err)
+		}
+		return result.Content[0].(*mcp.TextContent).Text, result.IsError
+	}
+
+	text, isError := call(map[string]any{"tables": []any{"users"}})
+	if isError || !strings.HasPrefix(text, "PRAGMA foreign_keys=OFF;\nBEGIN TRANSACTION;\nCREATE TABLE users") || !strings.HasSuffix(text, "COMMIT;\n") {
+		t.Errorf("Unexpected inline dump: %s", text)
+	}
+	if strings.Contains(text, "CREATE TABLE orders") {
+		t.Errorf("Expected only the users table, got: %s", text)
+	}
+
+	text, isError = call(map[string]any{"path": "dump.sql"})
+	if !isError || !containsString(text, "no export directory") {
+		t.Errorf("Expected an export directory error, got: %s", text)
+	}
+
+	handler.repo.SetExportDir(t.TempDir())
+	text, isError = call(map[string]any{"path": "dump.sql"})
+	if isError || !containsString(text, "dump.sql") || !containsString(text, "Size:") {
+		t.Errorf("Unexpected file dump output: %s", text)
+	}
+}
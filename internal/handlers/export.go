@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func (h *MCPHandler) ExportQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling exportQuery request")
+
+	sql := request.GetString("sql", "")
+	path := request.GetString("path", "")
+	if sql == "" || path == "" {
+		return toolError("Missing or invalid 'sql' or 'path' argument"), nil
+	}
+
+	result, err := h.sessionRepo(ctx).ExportQuery(models.ExportOptions{
+		SQL:       sql,
+		Path:      path,
+		Format:    request.GetString("format", ""),
+		Table:     request.GetString("table", ""),
+		Overwrite: request.GetBool("overwrite", false),
+	})
+	if err != nil {
+		h.logger.Error("Export failed: ", err)
+		return toolError(fmt.Sprintf("Failed to export query: %v", err)), nil
+	}
+
+	return toolText(FormatExportResult(result)), nil
+}
+
+// FormatExportResult renders where an export was written and its size
+func FormatExportResult(result *models.ExportResult) string {
+	return fmt.Sprintf("Exported %d rows (%d columns) as %s to %s\nSize: %d bytes\n",
+		result.Rows, len(result.Columns), result.Format, result.Path, result.Bytes)
+}
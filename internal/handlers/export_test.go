@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_ExportQuery(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	handler.repo.SetExportDir(dir)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "export_query",
+			Arguments: map[string]any{
+				"sql":    "SELECT 1 AS a, 'x' AS b UNION ALL SELECT 2, 'y'",
+				"path":   "out.jsonl",
+				"format": "jsonl",
+			},
+		},
+	}
+
+	result, err := handler.ExportQuery(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ExportQuery failed: %v", err)
+	}
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+	if result.IsError {
+		t.Fatalf("Expected successful result, got: %s", textContent.Text)
+	}
+	for _, expected := range []string{"Exported 2 rows (2 columns) as jsonl", "out.jsonl", "Size: 32 bytes"} {
+		if !containsString(textContent.Text, expected) {
+			t.Errorf("Expected %q in output, got: %s", expected, textContent.Text)
+		}
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "out.jsonl")); err != nil || string(content) != "{\"a\":1,\"b\":\"x\"}\n{\"a\":2,\"b\":\"y\"}\n" {
+		t.Errorf("Unexpected file content %q (%v)", content, err)
+	}
+
+	// The file exists now and overwrite is not set
+	result, err = handler.ExportQuery(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ExportQuery failed: %v", err)
+	}
+	if !result.IsError || !containsString(result.Content[0].(*mcp.TextContent).Text, "already exists") {
+		t.Errorf("Expected an already exists error, got: %+v", result.Content)
+	}
+}
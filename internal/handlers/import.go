@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func (h *MCPHandler) ImportCSV(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling importCSV request")
+
+	path := request.GetString("path", "")
+	table := request.GetString("table", "")
+	if path == "" || table == "" {
+		return toolError("Missing or invalid 'path' or 'table' argument"), nil
+	}
+
+	delimiter, err := ParseDelimiter(request.GetString("delimiter", ","))
+	if err != nil {
+		return toolError(err.Error()), nil
+	}
+
+	result, err := h.sessionRepo(ctx).ImportCSV(models.CSVImportOptions{
+		Path:      path,
+		Table:     table,
+		Delimiter: delimiter,
+		NoHeader:  !request.GetBool("header", true),
+		Encoding:  request.GetString("encoding", ""),
+		BatchSize: request.GetInt("batch_size", 0),
+	})
+	h.notifySchemaChanged()
+	if err != nil {
+		h.logger.Error("CSV import failed: ", err)
+		return toolError(fmt.Sprintf("Failed to import CSV: %v", err)), nil
+	}
+
+	return toolText(FormatImportResult(result)), nil
+}
+
+func (h *MCPHandler) ImportJSON(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling importJSON request")
+
+	path := request.GetString("path", "")
+	table := request.GetString("table", "")
+	if path == "" || table == "" {
+		return toolError("Missing or invalid 'path' or 'table' argument"), nil
+	}
+
+	result, err := h.sessionRepo(ctx).ImportJSON(models.JSONImportOptions{
+		Path:        path,
+		Table:       table,
+		Root:        request.GetString("root", ""),
+		Flatten:     request.GetString("flatten", models.FlattenColumns),
+		SplitArrays: request.GetBool("split_arrays", false),
+		BatchSize:   request.GetInt("batch_size", 0),
+	})
+	h.notifySchemaChanged()
+	if err != nil {
+		h.logger.Error("JSON import failed: ", err)
+		return toolError(fmt.Sprintf("Failed to import JSON: %v", err)), nil
+	}
+
+	return toolText(FormatImportResult(result)), nil
+}
+
+// ParseDelimiter accepts a single character or 'tab'
+func ParseDelimiter(value string) (rune, error) {
+	switch value {
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	if utf8.RuneCountInString(value) != 1 {
+		return 0, fmt.Errorf("invalid delimiter %q, expected a single character or 'tab'", value)
+	}
+	r, _ := utf8.DecodeRuneInString(value)
+	return r, nil
+}
+
+// FormatImportResult renders import counts, columns, child tables and rejected rows
+func FormatImportResult(result *models.ImportResult) string {
+	var b strings.Builder
+	writeImportTable(&b, result)
+
+	if len(result.Rejected) > 0 {
+		b.WriteString("\nRejected rows:\n")
+		for _, row := range result.Rejected {
+			if row.Line > 0 {
+				fmt.Fprintf(&b, "  line %d: %s\n", row.Line, row.Reason)
+			} else {
+				fmt.Fprintf(&b, "  record %d: %s\n", row.Record, row.Reason)
+			}
+		}
+		if int64(len(result.Rejected)) < result.RowsRejected {
+			fmt.Fprintf(&b, "  ... and %d more\n", result.RowsRejected-int64(len(result.Rejected)))
+		}
+	}
+
+	return b.String()
+}
+
+func writeImportTable(b *strings.Builder, result *models.ImportResult) {
+	if result.Created {
+		fmt.Fprintf(b, "Created table %s\n", result.Table)
+	}
+	fmt.Fprintf(b, "Imported %d rows into %s, rejected %d\n\nColumns:\n", result.RowsInserted, result.Table, result.RowsRejected)
+	for _, col := range result.Columns {
+		fmt.Fprintf(b, "  %s %s\n", col.Name, col.Type)
+	}
+
+	for i := range result.ChildTables {
+		b.WriteString("\n")
+		writeImportTable(b, &result.ChildTables[i])
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_ImportCSV(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "products.csv"), []byte("sku,price\nA1,9.99\nB2,oops,extra\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+	handler.repo.SetImportDirs([]string{dir})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "import_csv",
+			Arguments: map[string]any{
+				"path":  "products.csv",
+				"table": "products",
+			},
+		},
+	}
+
+	result, err := handler.ImportCSV(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+	if result.IsError {
+		t.Fatalf("Expected successful result, got: %s", textContent.Text)
+	}
+
+	for _, expected := range []string{"Created table products", "Imported 1 rows into products, rejected 1", "price REAL", "line 3: expected 2 fields, got 3"} {
+		if !containsString(textContent.Text, expected) {
+			t.Errorf("Expected %q in output, got: %s", expected, textContent.Text)
+		}
+	}
+}
+
+func TestParseDelimiter(t *testing.T) {
+	if r, err := ParseDelimiter("tab"); err != nil || r != '\t' {
+		t.Errorf("Expected tab, got %q, %v", r, err)
+	}
+	if r, err := ParseDelimiter("|"); err != nil || r != '|' {
+		t.Errorf("Expected |, got %q, %v", r, err)
+	}
+	if _, err := ParseDelimiter(",,"); err == nil {
+		t.Error("Expected error for multi-character delimiter")
+	}
+}
+
+func TestMCPHandler_ImportJSON(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	content := `[{"id": 1, "name": "Ann", "pets": [{"kind": "cat"}]}, 5]`
+	if err := os.WriteFile(filepath.Join(dir, "owners.json"), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write JSON: %v", err)
+	}
+	handler.repo.SetImportDirs([]string{dir})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "import_json",
+			Arguments: map[string]any{
+				"path":         "owners.json",
+				"table":        "owners",
+				"split_arrays": true,
+			},
+		},
+	}
+
+	result, err := handler.ImportJSON(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+	if result.IsError {
+		t.Fatalf("Expected successful result, got: %s", textContent.Text)
+	}
+
+	for _, expected := range []string{"Created table owners", "Imported 1 rows into owners, rejected 1", "Created table owners_pets", "owners_id INTEGER REFERENCES", "record 2: expected a JSON object"} {
+		if !containsString(textContent.Text, expected) {
+			t.Errorf("Expected %q in output, got: %s", expected, textContent.Text)
+		}
+	}
+
+	request.Params.Arguments = map[string]any{"path": "owners.json", "table": "t", "root": "missing"}
+	result, err = handler.ImportJSON(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected an error for a root without a top-level object")
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+)
+
+func (h *MCPHandler) InsertRows(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling insertRows request")
+
+	var opts models.InsertRowsOptions
+	if err := request.BindArguments(&opts); err != nil {
+		return toolError("Invalid arguments, expected 'table' and a 'rows' array of objects"), nil
+	}
+	if opts.Table == "" || len(opts.Rows) == 0 {
+		return toolError("Missing or invalid 'table' or 'rows' argument"), nil
+	}
+
+	result, err := h.sessionRepo(ctx).InsertRows(opts)
+	if err != nil {
+		h.logger.Error("Insert rows failed: ", err)
+		if errors.Is(err, repository.ErrNotAllowed) {
+			return toolError(fmt.Sprintf("Insert failed: %v", err)), nil
+		}
+		return toolError(fmt.Sprintf("Failed to insert rows, no rows were written: %v", err)), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Wrote %d rows to %s.\n", result.Written, result.Table)
+	if len(result.Skipped) > 0 {
+		fmt.Fprintf(&b, "Skipped %d conflicting rows: %s\n", len(result.Skipped), joinInts(result.Skipped))
+	}
+	if len(result.RowIDs) > 0 {
+		fmt.Fprintf(&b, "Rowids: %s\n", joinInts(result.RowIDs))
+	}
+	return toolText(b.String()), nil
+}
+
+func joinInts[T int | int64](values []T) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_InsertRows(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "insert_rows",
+			Arguments: map[string]any{
+				"table": "users",
+				"rows": []any{
+					map[string]any{"name": "Ann", "email": "ann@example.com", "age": float64(41)},
+					map[string]any{"name": "John", "email": "john@example.com"},
+				},
+				"on_conflict": "ignore",
+			},
+		},
+	}
+
+	result, err := handler.InsertRows(ctx, request)
+	if err != nil {
+		t.Fatalf("InsertRows failed: %v", err)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if result.IsError || !containsString(text, "Wrote 1 rows to users") || !containsString(text, "Skipped 1 conflicting rows: 1") || !containsString(text, "Rowids: 4") {
+		t.Errorf("Unexpected result: %s", text)
+	}
+
+	request.Params.Arguments = map[string]any{"table": "users", "rows": []any{map[string]any{"nickname": "x"}}}
+	result, err = handler.InsertRows(ctx, request)
+	if err != nil {
+		t.Fatalf("InsertRows failed: %v", err)
+	}
+	if !result.IsError || !containsString(result.Content[0].(*mcp.TextContent).Text, "no column nickname") {
+		t.Errorf("Expected an unknown column error, got %+v", result)
+	}
+}
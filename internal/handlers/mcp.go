@@ -2,31 +2,141 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/rvarun11/sqlite-mcp/internal/models"
 	"github.com/rvarun11/sqlite-mcp/internal/repository"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 	"go.uber.org/zap"
 )
 
 type MCPHandler struct {
-	repo   *repository.SQLiteDB
-	logger *zap.SugaredLogger
+	repo      *repository.SQLiteDB // The default database
+	databases *repository.Registry
+	logger    *zap.SugaredLogger
+
+	savedQueriesChanged func() // Called after a saved query is added or removed
+	schemaChanged       func() // Called after a tool call that may have changed the schema
 }
 
-func NewMCPHandler(repo *repository.SQLiteDB, logger *zap.SugaredLogger) *MCPHandler {
+func NewMCPHandler(databases *repository.Registry, logger *zap.SugaredLogger) *MCPHandler {
+	repo, _ := databases.Get("")
 	return &MCPHandler{
-		repo:   repo,
-		logger: logger,
+		repo:      repo,
+		databases: databases,
+		logger:    logger,
+	}
+}
+
+// databaseKey is the context key of the database a tool call selected
+type databaseKey struct{}
+
+// WithDatabase adds the database argument to a tool when several databases
+// are served and wraps its handler to run against the selected database.
+// Tools not marked read-only are refused on read-only databases.
+func (h *MCPHandler) WithDatabase(tool mcp.Tool, handler server.ToolHandlerFunc) (mcp.Tool, server.ToolHandlerFunc) {
+	if names := h.databases.Names(); len(names) > 1 {
+		mcp.WithString("database",
+			mcp.Description(fmt.Sprintf("Database to use (default %s), see list_databases", names[0])),
+			mcp.Enum(names...),
+		)(&tool)
+	}
+	readOnly := tool.Annotations.ReadOnlyHint != nil && *tool.Annotations.ReadOnlyHint
+
+	return tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name := request.GetString("database", h.databases.Default())
+		db, err := h.databases.Get(name)
+		if err != nil {
+			return toolError(fmt.Sprintf("Invalid 'database' argument: %v", err)), nil
+		}
+		if db.ReadOnly() && !readOnly {
+			return toolError(fmt.Sprintf("Database %s is read-only, %s is not allowed on it", name, tool.Name)), nil
+		}
+		return handler(context.WithValue(ctx, databaseKey{}, name), request)
 	}
 }
 
+// selectedDatabase returns the name of the database a tool call selected,
+// empty for the default database
+func selectedDatabase(ctx context.Context) string {
+	name, _ := ctx.Value(databaseKey{}).(string)
+	return name
+}
+
+// mainRepo returns the selected database itself, ignoring session branches
+func (h *MCPHandler) mainRepo(ctx context.Context) *repository.SQLiteDB {
+	if db, err := h.databases.Get(selectedDatabase(ctx)); err == nil {
+		return db
+	}
+	return h.repo
+}
+
+// sessionBranches returns the branches of the selected database
+func (h *MCPHandler) sessionBranches(ctx context.Context) *repository.BranchManager {
+	branches, err := h.databases.Branches(selectedDatabase(ctx))
+	if err != nil {
+		branches, _ = h.databases.Branches("")
+	}
+	return branches
+}
+
+// sessionRepo returns the database the calling session works on, its branch
+// of the selected database when it created one and the database otherwise
+func (h *MCPHandler) sessionRepo(ctx context.Context) *repository.SQLiteDB {
+	return h.sessionBranches(ctx).Current(sessionID(ctx))
+}
+
+// otherDatabase resolves the database file a tool compares with, which must
+// be served, attached or inside an import directory
+func (h *MCPHandler) otherDatabase(ctx context.Context, other string) (string, error) {
+	return h.databases.ResolveFile(other, h.mainRepo(ctx).ImportDirs())
+}
+
+// sessionID identifies the client session of a request, empty outside of one
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+func (h *MCPHandler) ListDatabases(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling listDatabases request")
+
+	return toolText(FormatDatabases(h.databases.List())), nil
+}
+
+// FormatDatabases renders the served databases with their access settings
+func FormatDatabases(databases []models.Database) string {
+	var b strings.Builder
+	b.WriteString("Databases:\n")
+	for _, db := range databases {
+		fmt.Fprintf(&b, "%s: %s\n  %d tables, %d bytes, policy %s", db.Name, db.Path, db.Tables, db.Size, db.Policy)
+		if db.ReadOnly {
+			b.WriteString(", read-only")
+		}
+		if db.Default {
+			b.WriteString(", default")
+		}
+		b.WriteString("\n")
+		for _, attachment := range db.Attached {
+			mode := "read-only"
+			if attachment.Writable {
+				mode = "writable"
+			}
+			fmt.Fprintf(&b, "  attached as %s: %s, %s\n", attachment.Alias, attachment.Path, mode)
+		}
+	}
+	return b.String()
+}
+
 func (h *MCPHandler) GetSchema(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	h.logger.Info("Handling listTables request")
 
-	tables, err := h.repo.GetSchema()
+	tables, err := h.sessionRepo(ctx).GetSchema()
 	if err != nil {
 		h.logger.Error("Failed to list tables", err)
 		return &mcp.CallToolResult{
@@ -40,6 +150,15 @@ func (h *MCPHandler) GetSchema(ctx context.Context, request mcp.CallToolRequest)
 		}, nil
 	}
 
+	if request.GetBool("include_inferred", false) {
+		relationships, err := h.sessionRepo(ctx).InferRelationships(models.InferenceOptions{})
+		if err != nil {
+			h.logger.Error("Failed to infer relationships", err)
+			return toolError("Failed to infer relationships. Please try again without include_inferred."), nil
+		}
+		attachInferredRelationships(tables, relationships)
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
@@ -66,9 +185,12 @@ func (h *MCPHandler) Query(ctx context.Context, request mcp.CallToolRequest) (*m
 		}, nil
 	}
 
-	result, err := h.repo.Query(sql)
+	result, err := h.sessionRepo(ctx).Query(sql)
 	if err != nil {
 		h.logger.Error("Query execution failed: ", err)
+		if errors.Is(err, repository.ErrTimeout) {
+			return toolError(fmt.Sprintf("Query execution failed: %v", err)), nil
+		}
 		return &mcp.CallToolResult{
 			IsError: true,
 			Content: []mcp.Content{
@@ -107,9 +229,13 @@ func (h *MCPHandler) Execute(ctx context.Context, request mcp.CallToolRequest) (
 		}, nil
 	}
 
-	result, err := h.repo.Execute(sql)
+	result, err := h.sessionRepo(ctx).Execute(sql)
+	h.notifySchemaChanged()
 	if err != nil {
 		h.logger.Error("Statement execution failed: ", err)
+		if errors.Is(err, repository.ErrNotAllowed) || errors.Is(err, repository.ErrTimeout) {
+			return toolError(fmt.Sprintf("Statement execution failed: %v", err)), nil
+		}
 		return &mcp.CallToolResult{
 			IsError: true,
 			Content: []mcp.Content{
@@ -131,6 +257,31 @@ func (h *MCPHandler) Execute(ctx context.Context, request mcp.CallToolRequest) (
 	}, nil
 }
 
+// toolError builds an error result carrying a single text message
+func toolError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// toolText builds a successful result carrying a single text message
+func toolText(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
 // Helper functions for formatting responses
 func formatTablesResponse(tables []models.Table) string {
 	if len(tables) == 0 {
@@ -140,6 +291,12 @@ func formatTablesResponse(tables []models.Table) string {
 	response := "Database Tables:\n\n"
 	for _, table := range tables {
 		response += "Table: " + table.Name + "\n"
+		if table.Description != "" {
+			response += "Description: " + table.Description + "\n"
+		}
+		if table.Sensitivity != "" {
+			response += "Sensitivity: " + table.Sensitivity + "\n"
+		}
 
 		if len(table.Columns) > 0 {
 			response += "Columns:\n"
@@ -154,6 +311,18 @@ func formatTablesResponse(tables []models.Table) string {
 				if col.DefaultValue != nil {
 					response += " DEFAULT " + *col.DefaultValue
 				}
+				if col.Description != "" {
+					response += " -- " + col.Description
+				}
+				if col.Unit != "" {
+					response += " [unit: " + col.Unit + "]"
+				}
+				if len(col.EnumValues) > 0 {
+					response += " [values: " + strings.Join(col.EnumValues, ", ") + "]"
+				}
+				if col.Sensitivity != "" {
+					response += " [sensitivity: " + col.Sensitivity + "]"
+				}
 				response += "\n"
 			}
 		}
@@ -178,16 +347,39 @@ func formatTablesResponse(tables []models.Table) string {
 				response += "\n"
 			}
 		}
+
+		if len(table.InferredRelationships) > 0 {
+			response += "Inferred Relationships:\n"
+			for _, r := range table.InferredRelationships {
+				response += fmt.Sprintf("  - %s -> %s(%s) (inferred, confidence %.2f)\n",
+					strings.Join(r.FromColumns, ", "), r.ToTable, strings.Join(r.ToColumns, ", "), r.Confidence)
+			}
+		}
 		response += "\n"
 	}
 
 	return response
 }
 
+// attachInferredRelationships adds each relationship to its referencing table
+func attachInferredRelationships(tables []models.Table, relationships []models.Relationship) {
+	for i := range tables {
+		for _, r := range relationships {
+			if strings.EqualFold(r.FromTable, tables[i].Name) {
+				tables[i].InferredRelationships = append(tables[i].InferredRelationships, r)
+			}
+		}
+	}
+}
+
 func formatQueryResponse(result *models.QueryResult) string {
-	response := fmt.Sprintf("Query Results:\nColumns: %s\nRow Count: %d\n\n",
+	response := fmt.Sprintf("Query Results:\nColumns: %s\nRow Count: %d\n",
 		strings.Join(result.Columns, ", "),
 		result.Count)
+	if result.Truncated {
+		response += "More rows matched, the result is cut off at the configured row limit\n"
+	}
+	response += "\n"
 
 	if result.Count > 0 {
 		response += "Data:\n"
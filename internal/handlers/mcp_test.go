@@ -78,10 +78,14 @@ func setupTestMCPHandler(t *testing.T) (*MCPHandler, func()) {
 		t.Fatalf("Failed to insert test data: %v", err)
 	}
 
-	handler := NewMCPHandler(repo, logger)
+	databases := repository.NewRegistry()
+	if err := databases.Add("test", repo); err != nil {
+		t.Fatalf("Failed to register database: %v", err)
+	}
+	handler := NewMCPHandler(databases, logger)
 
 	cleanup := func() {
-		repo.Close()
+		databases.Close()
 		os.Remove(tmpfile.Name())
 	}
 
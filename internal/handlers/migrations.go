@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func (h *MCPHandler) ListMigrations(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling listMigrations request")
+
+	migrations, err := h.sessionRepo(ctx).ListMigrations()
+	if err != nil {
+		h.logger.Error("Failed to list migrations: ", err)
+		return toolError(fmt.Sprintf("Failed to list migrations: %v", err)), nil
+	}
+
+	return toolText(FormatMigrations(migrations)), nil
+}
+
+func (h *MCPHandler) CreateMigration(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling createMigration request")
+
+	name := request.GetString("name", "")
+	up := request.GetString("up_sql", "")
+	if name == "" || strings.TrimSpace(up) == "" {
+		return toolError("Missing or invalid 'name' or 'up_sql' argument"), nil
+	}
+
+	migration, err := h.sessionRepo(ctx).CreateMigration(name, up, request.GetString("down_sql", ""))
+	if err != nil {
+		h.logger.Error("Failed to create migration: ", err)
+		return toolError(fmt.Sprintf("Failed to create migration: %v", err)), nil
+	}
+
+	text := fmt.Sprintf("Created migration %04d_%s\nUp: %s\n", migration.Version, migration.Name, migration.UpPath)
+	if migration.HasDown {
+		text += fmt.Sprintf("Down: %s\n", migration.DownPath)
+	}
+	text += "\nThe migration is pending. Run apply_migrations to apply it."
+
+	return toolText(text), nil
+}
+
+func (h *MCPHandler) ApplyMigrations(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling applyMigrations request")
+
+	applied, err := h.sessionRepo(ctx).ApplyMigrations(int64(request.GetInt("target_version", 0)))
+	h.notifySchemaChanged()
+
+	var b strings.Builder
+	for _, m := range applied {
+		fmt.Fprintf(&b, "Applied %04d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		h.logger.Error("Failed to apply migrations: ", err)
+		fmt.Fprintf(&b, "Failed to apply migrations: %v", err)
+		return toolError(b.String()), nil
+	}
+	if len(applied) == 0 {
+		b.WriteString("No pending migrations.")
+	}
+
+	return toolText(b.String()), nil
+}
+
+// FormatMigrations renders migrations with their status
+func FormatMigrations(migrations []models.Migration) string {
+	if len(migrations) == 0 {
+		return "No migrations found."
+	}
+
+	var b strings.Builder
+	b.WriteString("Migrations:\n")
+	for _, m := range migrations {
+		fmt.Fprintf(&b, "%04d_%s: %s", m.Version, m.Name, m.Status)
+		if m.AppliedAt != "" {
+			fmt.Fprintf(&b, " at %s", m.AppliedAt)
+		}
+		if !m.HasDown && m.Status != models.MigrationMissing {
+			b.WriteString(" (no down migration)")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_Migrations(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	handler.repo.SetMigrationsDir(t.TempDir())
+	ctx := context.Background()
+
+	create := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "create_migration",
+			Arguments: map[string]any{
+				"name":     "add orders status",
+				"up_sql":   "ALTER TABLE orders ADD COLUMN status TEXT",
+				"down_sql": "ALTER TABLE orders DROP COLUMN status",
+			},
+		},
+	}
+	result, err := handler.CreateMigration(ctx, create)
+	if err != nil {
+		t.Fatalf("CreateMigration failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected successful result, got error: %v", result.Content[0].(*mcp.TextContent).Text)
+	}
+
+	result, err = handler.ApplyMigrations(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "apply_migrations"}})
+	if err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !containsString(text, "Applied 0001_add_orders_status") {
+		t.Errorf("Expected applied migration, got: %s", text)
+	}
+
+	result, err = handler.ListMigrations(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "list_migrations"}})
+	if err != nil {
+		t.Fatalf("ListMigrations failed: %v", err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !containsString(text, "0001_add_orders_status: applied") {
+		t.Errorf("Expected applied status, got: %s", text)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func (h *MCPHandler) ProfileTable(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling profileTable request")
+
+	table := request.GetString("table", "")
+	if table == "" {
+		return toolError("Missing or invalid 'table' argument"), nil
+	}
+
+	opts := models.ProfileOptions{
+		TopN:       request.GetInt("top_n", 0),
+		SampleRows: request.GetInt("sample_rows", 0),
+	}
+
+	profile, err := h.sessionRepo(ctx).ProfileTable(table, opts)
+	if err != nil {
+		h.logger.Error("Table profiling failed: ", err)
+		return toolError("Failed to profile table. Please check the table name and try again."), nil
+	}
+
+	return toolText(formatProfileResponse(profile)), nil
+}
+
+func formatProfileResponse(profile *models.TableProfile) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Table Profile: %s\n", profile.Table)
+	fmt.Fprintf(&b, "Row Count: %d\n", profile.RowCount)
+	if profile.Sampled {
+		fmt.Fprintf(&b, "Column statistics based on a sample of %d rows (method: %s)\n", profile.SampleRows, profile.SampleMethod)
+	}
+	if profile.Cached {
+		b.WriteString("(cached, data unchanged since last profile)\n")
+	}
+	b.WriteString("\n")
+
+	for _, col := range profile.Columns {
+		fmt.Fprintf(&b, "Column: %s (%s)\n", col.Name, col.DeclaredType)
+		fmt.Fprintf(&b, "  Null Fraction: %.4f\n", col.NullFraction)
+		if col.DistinctApproximate {
+			fmt.Fprintf(&b, "  Distinct: ~%d (estimated)\n", col.DistinctCount)
+		} else {
+			fmt.Fprintf(&b, "  Distinct: %d\n", col.DistinctCount)
+		}
+		if col.Min != nil || col.Max != nil {
+			fmt.Fprintf(&b, "  Min: %v\n  Max: %v\n", formatValue(col.Min), formatValue(col.Max))
+		}
+
+		classes := make([]string, 0, len(col.StorageClasses))
+		for class := range col.StorageClasses {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		pairs := make([]string, 0, len(classes))
+		for _, class := range classes {
+			pairs = append(pairs, fmt.Sprintf("%s=%d", class, col.StorageClasses[class]))
+		}
+		fmt.Fprintf(&b, "  Storage Classes: %s\n", strings.Join(pairs, ", "))
+
+		if len(col.TopValues) > 0 {
+			b.WriteString("  Top Values:\n")
+			for _, tv := range col.TopValues {
+				fmt.Fprintf(&b, "    - %v (%d)\n", formatValue(tv.Value), tv.Count)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// formatValue renders a database value for text responses
+func formatValue(value any) any {
+	if value == nil {
+		return "<NULL>"
+	}
+	return value
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_ProfileTable(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "profile_table",
+			Arguments: map[string]any{
+				"table": "users",
+			},
+		},
+	}
+
+	result, err := handler.ProfileTable(ctx, request)
+	if err != nil {
+		t.Fatalf("ProfileTable failed: %v", err)
+	}
+
+	if result.IsError {
+		t.Fatal("Expected successful result, got error")
+	}
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+
+	response := textContent.Text
+	if !containsString(response, "Row Count: 3") {
+		t.Error("Expected response to contain the row count")
+	}
+
+	if !containsString(response, "Storage Classes") {
+		t.Error("Expected response to contain storage class distribution")
+	}
+
+	if !containsString(response, "Column: email") {
+		t.Error("Expected response to contain column profiles")
+	}
+}
+
+func TestMCPHandler_ProfileTable_MissingTable(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "profile_table",
+			Arguments: map[string]any{
+				"table": "nonexistent_table",
+			},
+		},
+	}
+
+	result, err := handler.ProfileTable(ctx, request)
+	if err != nil {
+		t.Fatalf("ProfileTable failed: %v", err)
+	}
+
+	if !result.IsError {
+		t.Error("Expected error result for missing table")
+	}
+}
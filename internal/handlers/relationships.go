@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func (h *MCPHandler) InferRelationships(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling inferRelationships request")
+
+	opts := models.InferenceOptions{
+		Table:      request.GetString("table", ""),
+		SampleSize: request.GetInt("sample_size", 0),
+	}
+	if _, ok := request.GetArguments()["min_confidence"]; ok {
+		minConfidence := request.GetFloat("min_confidence", 0)
+		opts.MinConfidence = &minConfidence
+	}
+
+	relationships, err := h.sessionRepo(ctx).InferRelationships(opts)
+	if err != nil {
+		h.logger.Error("Relationship inference failed: ", err)
+		return toolError("Failed to infer relationships. Please check the table name and try again."), nil
+	}
+
+	return toolText(formatRelationshipsResponse(relationships)), nil
+}
+
+func (h *MCPHandler) FindJoinPath(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling findJoinPath request")
+
+	fromTable := request.GetString("from_table", "")
+	toTable := request.GetString("to_table", "")
+	if fromTable == "" || toTable == "" {
+		return toolError("Missing or invalid 'from_table' or 'to_table' argument"), nil
+	}
+
+	opts := models.JoinPathOptions{
+		IncludeInferred: request.GetBool("include_inferred", false),
+		MaxDepth:        request.GetInt("max_depth", 0),
+		Limit:           request.GetInt("limit", 0),
+	}
+
+	paths, err := h.sessionRepo(ctx).FindJoinPaths(fromTable, toTable, opts)
+	if err != nil {
+		h.logger.Error("Join path discovery failed: ", err)
+		return toolError("Failed to find join paths. Please check the table names and try again."), nil
+	}
+
+	return toolText(formatJoinPathsResponse(fromTable, toTable, paths, opts.IncludeInferred)), nil
+}
+
+func formatRelationshipsResponse(relationships []models.Relationship) string {
+	if len(relationships) == 0 {
+		return "No likely undeclared relationships found."
+	}
+
+	var b strings.Builder
+	b.WriteString("Inferred Relationships:\n\n")
+	for _, r := range relationships {
+		fmt.Fprintf(&b, "%s(%s) -> %s(%s)\n", r.FromTable, strings.Join(r.FromColumns, ", "),
+			r.ToTable, strings.Join(r.ToColumns, ", "))
+		fmt.Fprintf(&b, "  Confidence: %.2f\n", r.Confidence)
+		fmt.Fprintf(&b, "  Evidence: %s\n\n", r.Reason)
+	}
+	return b.String()
+}
+
+func formatJoinPathsResponse(fromTable, toTable string, paths []models.JoinPath, includeInferred bool) string {
+	if len(paths) == 0 {
+		response := fmt.Sprintf("No join path found between %s and %s.", fromTable, toTable)
+		if !includeInferred {
+			response += " Try again with include_inferred to follow relationships inferred from column names."
+		}
+		return response
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Join Paths from %s to %s:\n\n", fromTable, toTable)
+	for i, path := range paths {
+		fmt.Fprintf(&b, "Path %d: %s (%d joins)", i+1, strings.Join(path.Tables, " -> "), len(path.Steps))
+		if path.Inferred {
+			b.WriteString(" [uses inferred relationships]")
+		}
+		b.WriteString("\n")
+		b.WriteString(path.SQL + "\n")
+		for _, step := range path.Steps {
+			if step.Relationship.Inferred {
+				fmt.Fprintf(&b, "  - inferred: %s\n", step.Relationship.Reason)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_FindJoinPath(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "find_join_path",
+			Arguments: map[string]any{
+				"from_table": "users",
+				"to_table":   "orders",
+			},
+		},
+	}
+
+	result, err := handler.FindJoinPath(ctx, request)
+	if err != nil {
+		t.Fatalf("FindJoinPath failed: %v", err)
+	}
+
+	if result.IsError {
+		t.Fatal("Expected successful result, got error")
+	}
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+
+	if !containsString(textContent.Text, "JOIN orders ON orders.user_id = users.id") {
+		t.Errorf("Expected ready-to-use join clause, got: %s", textContent.Text)
+	}
+}
+
+func TestMCPHandler_GetSchema_IncludeInferred(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	_, err := handler.repo.Execute("CREATE TABLE reviews (id INTEGER PRIMARY KEY, user_id INTEGER, body TEXT)")
+	if err != nil {
+		t.Fatalf("Failed to create reviews table: %v", err)
+	}
+
+	ctx := context.Background()
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "get_schema",
+			Arguments: map[string]any{
+				"include_inferred": true,
+			},
+		},
+	}
+
+	result, err := handler.GetSchema(ctx, request)
+	if err != nil {
+		t.Fatalf("GetSchema failed: %v", err)
+	}
+
+	if result.IsError {
+		t.Fatal("Expected successful result, got error")
+	}
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+
+	if !containsString(textContent.Text, "user_id -> users(id) (inferred") {
+		t.Errorf("Expected inferred relationship in schema, got: %s", textContent.Text)
+	}
+}
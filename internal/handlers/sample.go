@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func (h *MCPHandler) SampleRows(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling sampleRows request")
+
+	table := request.GetString("table", "")
+	if table == "" {
+		return toolError("Missing or invalid 'table' argument"), nil
+	}
+
+	opts := models.SampleOptions{
+		Limit:          request.GetInt("limit", 0),
+		StratifyBy:     request.GetString("stratify_by", ""),
+		MaxValueLength: request.GetInt("max_value_length", 0),
+	}
+
+	result, err := h.sessionRepo(ctx).SampleRows(table, opts)
+	if err != nil {
+		h.logger.Error("Row sampling failed: ", err)
+		return toolError("Failed to sample rows. Please check the table and column names and try again."), nil
+	}
+
+	return toolText(formatSampleResponse(result)), nil
+}
+
+func formatSampleResponse(result *models.SampleResult) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Sample of %s:\n", result.Table)
+	fmt.Fprintf(&b, "Columns: %s\n", strings.Join(result.Columns, ", "))
+	fmt.Fprintf(&b, "Rows Returned: %d of %d (method: %s)\n", result.Count, result.TotalRows, result.Method)
+
+	if result.StratifiedBy != "" {
+		fmt.Fprintf(&b, "Stratified By: %s\n", result.StratifiedBy)
+		for _, stratum := range result.Strata {
+			fmt.Fprintf(&b, "  - %v: %d rows\n", formatValue(stratum.Value), stratum.Count)
+		}
+	}
+
+	if result.Count > 0 {
+		b.WriteString("\nData:\n")
+		for i, row := range result.Rows {
+			pairs := make([]string, 0, len(result.Columns))
+			for _, col := range result.Columns {
+				pairs = append(pairs, fmt.Sprintf("%s=%v", col, formatValue(row[col])))
+			}
+			fmt.Fprintf(&b, "Row %d: %s\n", i+1, strings.Join(pairs, ", "))
+		}
+	}
+
+	return b.String()
+}
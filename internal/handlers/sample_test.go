@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_SampleRows(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "sample_rows",
+			Arguments: map[string]any{
+				"table": "users",
+				"limit": float64(2),
+			},
+		},
+	}
+
+	result, err := handler.SampleRows(ctx, request)
+	if err != nil {
+		t.Fatalf("SampleRows failed: %v", err)
+	}
+
+	if result.IsError {
+		t.Fatal("Expected successful result, got error")
+	}
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+
+	if !containsString(textContent.Text, "Rows Returned: 2 of 3") {
+		t.Errorf("Expected 2 of 3 rows, got: %s", textContent.Text)
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+// savedQueryToolPrefix prefixes the tool names of saved queries, so they
+// cannot clash with the built-in tools
+const savedQueryToolPrefix = "query_"
+
+// OnSavedQueriesChanged sets a function called after a saved query is added
+// or removed, typically to register the saved query tools again
+func (h *MCPHandler) OnSavedQueriesChanged(fn func()) {
+	h.savedQueriesChanged = fn
+}
+
+func (h *MCPHandler) ListSavedQueries(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling listSavedQueries request")
+
+	queries, err := h.mainRepo(ctx).ListSavedQueries()
+	if err != nil {
+		h.logger.Error("Listing saved queries failed: ", err)
+		return toolError("Failed to list saved queries. Please try again."), nil
+	}
+	if len(queries) == 0 {
+		return toolText("No saved queries. Add one with save_query."), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Saved queries (%d):\n", len(queries))
+	for _, query := range queries {
+		fmt.Fprintf(&b, "\n%s (tool %s)\n", query.Name, savedQueryToolPrefix+query.Name)
+		fmt.Fprintf(&b, "Description: %s\n", query.Description)
+		fmt.Fprintf(&b, "SQL: %s\n", strings.TrimSpace(query.SQL))
+		for _, param := range query.Parameters {
+			fmt.Fprintf(&b, "Parameter :%s %s", param.Name, param.Type)
+			if param.Required {
+				b.WriteString(", required")
+			}
+			if param.Default != nil {
+				fmt.Fprintf(&b, ", default %v", param.Default)
+			}
+			if param.Description != "" {
+				fmt.Fprintf(&b, " - %s", param.Description)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return toolText(b.String()), nil
+}
+
+func (h *MCPHandler) SaveQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling saveQuery request")
+
+	var query models.SavedQuery
+	if err := request.BindArguments(&query); err != nil {
+		return toolError("Invalid arguments, expected 'name', 'description', 'sql' and an optional 'parameters' array"), nil
+	}
+	if query.Name == "" || query.SQL == "" {
+		return toolError("Missing or invalid 'name' or 'sql' argument"), nil
+	}
+
+	saved, err := h.mainRepo(ctx).SaveQuery(query)
+	if err != nil {
+		h.logger.Error("Saving query failed: ", err)
+		return toolError(fmt.Sprintf("Failed to save query: %v", err)), nil
+	}
+	if h.savedQueriesChanged != nil {
+		h.savedQueriesChanged()
+	}
+
+	return toolText(fmt.Sprintf("Saved query %s with %d parameters, call it with the %s tool.",
+		saved.Name, len(saved.Parameters), savedQueryToolPrefix+saved.Name)), nil
+}
+
+func (h *MCPHandler) RemoveSavedQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling removeSavedQuery request")
+
+	name := request.GetString("name", "")
+	if name == "" {
+		return toolError("Missing or invalid 'name' argument"), nil
+	}
+
+	if err := h.mainRepo(ctx).RemoveSavedQuery(name); err != nil {
+		h.logger.Error("Removing saved query failed: ", err)
+		return toolError(fmt.Sprintf("Failed to remove saved query: %v", err)), nil
+	}
+	if h.savedQueriesChanged != nil {
+		h.savedQueriesChanged()
+	}
+
+	return toolText(fmt.Sprintf("Removed saved query %s.", name)), nil
+}
+
+// SavedQueryTools returns a tool for every saved query of the served
+// databases. A query saved in several databases gets one tool, defined by the
+// first database that has it, which runs on the selected database.
+func (h *MCPHandler) SavedQueryTools() []server.ServerTool {
+	var tools []server.ServerTool
+	defined := make(map[string]models.SavedQuery)
+	for _, name := range h.databases.Names() {
+		db, err := h.databases.Get(name)
+		if err != nil {
+			continue
+		}
+		queries, err := db.ListSavedQueries()
+		if err != nil {
+			h.logger.Errorf("Failed to load the saved queries of %s: %v", name, err)
+			continue
+		}
+		for _, query := range queries {
+			if previous, ok := defined[query.Name]; ok {
+				if !reflect.DeepEqual(previous, query) {
+					h.logger.Warnf("Saved query %s differs between databases, its tool uses the definition of the first one", query.Name)
+				}
+				continue
+			}
+			defined[query.Name] = query
+			tool, handler := h.WithDatabase(savedQueryTool(query), h.runSavedQuery(query.Name))
+			tools = append(tools, server.ServerTool{Tool: tool, Handler: handler})
+		}
+	}
+	return tools
+}
+
+// savedQueryTool describes a saved query as a read-only tool whose input
+// schema has a property for every parameter
+func savedQueryTool(query models.SavedQuery) mcp.Tool {
+	tool := mcp.NewTool(savedQueryToolPrefix+query.Name,
+		mcp.WithDescription(query.Description),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	for _, param := range query.Parameters {
+		property := map[string]any{"type": param.Type}
+		if param.Description != "" {
+			property["description"] = param.Description
+		}
+		if param.Default != nil {
+			property["default"] = param.Default
+		}
+		tool.InputSchema.Properties[param.Name] = property
+		if param.Required {
+			tool.InputSchema.Required = append(tool.InputSchema.Required, param.Name)
+		}
+	}
+	return tool
+}
+
+// runSavedQuery returns the handler of a saved query tool. The query is looked
+// up when called, so it runs the current definition.
+func (h *MCPHandler) runSavedQuery(name string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		h.logger.Infof("Handling saved query %s request", name)
+
+		args := maps.Clone(request.GetArguments())
+		delete(args, "database")
+
+		result, err := h.sessionRepo(ctx).RunSavedQuery(name, args)
+		if err != nil {
+			h.logger.Errorf("Saved query %s failed: %v", name, err)
+			return toolError(fmt.Sprintf("Saved query %s failed: %v", name, err)), nil
+		}
+		return toolText(formatQueryResponse(result)), nil
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPHandler_SavedQueries(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	changes := 0
+	handler.OnSavedQueriesChanged(func() { changes++ })
+
+	ctx := context.Background()
+	save := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "save_query",
+			Arguments: map[string]any{
+				"name":        "users_older_than",
+				"description": "Users older than an age",
+				"sql":         "SELECT name FROM users WHERE age > :age ORDER BY age",
+				"parameters": []any{
+					map[string]any{"name": "age", "type": "integer", "description": "Minimum age", "default": 18},
+				},
+			},
+		},
+	}
+	result, err := handler.SaveQuery(ctx, save)
+	if err != nil || result.IsError {
+		t.Fatalf("SaveQuery failed: %+v (%v)", result, err)
+	}
+	if changes != 1 {
+		t.Errorf("Expected the change callback to be called once, got %d", changes)
+	}
+
+	tools := handler.SavedQueryTools()
+	if len(tools) != 1 || tools[0].Tool.Name != "query_users_older_than" {
+		t.Fatalf("Expected the tool query_users_older_than, got %+v", tools)
+	}
+	tool := tools[0].Tool
+	if tool.Description != "Users older than an age" || tool.Annotations.ReadOnlyHint == nil || !*tool.Annotations.ReadOnlyHint {
+		t.Errorf("Expected a read-only tool with the query description, got %+v", tool)
+	}
+	property, ok := tool.InputSchema.Properties["age"].(map[string]any)
+	if !ok || property["type"] != "integer" || property["default"] != int64(18) {
+		t.Errorf("Expected an integer age property with a default, got %v", tool.InputSchema.Properties["age"])
+	}
+
+	run := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: tool.Name, Arguments: map[string]any{"age": float64(31)}}}
+	result, err = tools[0].Handler(ctx, run)
+	if err != nil || result.IsError {
+		t.Fatalf("Saved query tool failed: %+v (%v)", result, err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !containsString(text, "Row Count: 1") || !containsString(text, "name=Bob Wilson") {
+		t.Errorf("Expected Bob Wilson only, got: %s", text)
+	}
+
+	run.Params.Arguments = map[string]any{"age": "old"}
+	result, _ = tools[0].Handler(ctx, run)
+	if !result.IsError {
+		t.Error("Expected an error for an argument of the wrong type")
+	}
+
+	list, _ := handler.ListSavedQueries(ctx, mcp.CallToolRequest{})
+	if text := list.Content[0].(*mcp.TextContent).Text; !containsString(text, "Parameter :age integer, default 18 - Minimum age") {
+		t.Errorf("Expected the parameter in the list, got: %s", text)
+	}
+
+	remove := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "remove_saved_query", Arguments: map[string]any{"name": "users_older_than"}}}
+	result, err = handler.RemoveSavedQuery(ctx, remove)
+	if err != nil || result.IsError {
+		t.Fatalf("RemoveSavedQuery failed: %+v (%v)", result, err)
+	}
+	if changes != 2 || len(handler.SavedQueryTools()) != 0 {
+		t.Errorf("Expected the tool to be gone after removal, got %d changes", changes)
+	}
+}
+
+func TestMCPHandler_SaveQuery_Invalid(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "save_query",
+			Arguments: map[string]any{
+				"name":        "delete_users",
+				"description": "Not a query",
+				"sql":         "DELETE FROM users",
+			},
+		},
+	}
+	result, err := handler.SaveQuery(context.Background(), request)
+	if err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+	if !result.IsError || !containsString(result.Content[0].(*mcp.TextContent).Text, "must be a SELECT") {
+		t.Errorf("Expected a validation error, got %+v", result)
+	}
+}
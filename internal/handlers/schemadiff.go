@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func (h *MCPHandler) SchemaDiff(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Info("Handling schemaDiff request")
+
+	other := request.GetString("other_database", "")
+	if other == "" {
+		return toolError("Missing or invalid 'other_database' argument"), nil
+	}
+	other, err := h.otherDatabase(ctx, other)
+	if err != nil {
+		return toolError(fmt.Sprintf("Invalid 'other_database' argument: %v", err)), nil
+	}
+
+	var fromOther bool
+	switch direction := request.GetString("direction", "to_other"); direction {
+	case "to_other":
+	case "from_other":
+		fromOther = true
+	default:
+		return toolError("Invalid 'direction' argument, expected 'to_other' or 'from_other'"), nil
+	}
+
+	diff, err := h.sessionRepo(ctx).DiffSchemaWithFile(other, fromOther)
+	if err != nil {
+		h.logger.Error("Schema diff failed: ", err)
+		return toolError("Failed to compare schemas. Please check that the other database file exists and is readable."), nil
+	}
+
+	return toolText(FormatSchemaDiff(diff)), nil
+}
+
+// FormatSchemaDiff renders a schema diff and its migration as text
+func FormatSchemaDiff(diff *models.SchemaDiff) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Schema Diff:\nSource: %s\nTarget: %s\n\n", diff.Source, diff.Target)
+	if diff.Identical {
+		b.WriteString("Schemas are identical.\n")
+		return b.String()
+	}
+
+	for _, name := range diff.AddedTables {
+		fmt.Fprintf(&b, "+ table %s\n", name)
+	}
+	for _, name := range diff.RemovedTables {
+		fmt.Fprintf(&b, "- table %s\n", name)
+	}
+	for _, table := range diff.ChangedTables {
+		fmt.Fprintf(&b, "~ table %s", table.Name)
+		if table.RequiresRebuild {
+			b.WriteString(" (requires rebuild)")
+		}
+		b.WriteString("\n")
+		for _, col := range table.AddedColumns {
+			fmt.Fprintf(&b, "    + column %s %s\n", col.Name, col.Type)
+		}
+		for _, col := range table.RemovedColumns {
+			fmt.Fprintf(&b, "    - column %s\n", col)
+		}
+		for _, change := range table.ChangedColumns {
+			fmt.Fprintf(&b, "    ~ column %s: %s -> %s\n", change.Name, change.From, change.To)
+		}
+		if table.ForeignKeysChanged {
+			b.WriteString("    ~ foreign keys changed\n")
+		}
+		if table.DefinitionChanged && len(table.AddedColumns) == 0 && len(table.RemovedColumns) == 0 && len(table.ChangedColumns) == 0 && !table.ForeignKeysChanged {
+			b.WriteString("    ~ constraints changed\n")
+		}
+	}
+	for _, object := range diff.AddedObjects {
+		fmt.Fprintf(&b, "+ %s %s on %s\n", object.Type, object.Name, object.TableName)
+	}
+	for _, object := range diff.RemovedObjects {
+		fmt.Fprintf(&b, "- %s %s on %s\n", object.Type, object.Name, object.TableName)
+	}
+	for _, object := range diff.ChangedObjects {
+		fmt.Fprintf(&b, "~ %s %s on %s\n", object.Type, object.Name, object.TableName)
+	}
+
+	b.WriteString("\nMigration:\n")
+	for _, statement := range diff.Migration {
+		b.WriteString(strings.TrimSpace(statement) + ";\n")
+	}
+
+	return b.String()
+}
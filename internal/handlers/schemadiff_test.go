@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+)
+
+func TestMCPHandler_SchemaDiff(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	tmpfile, err := os.CreateTemp("", "test_other_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	tmpfile.Close()
+	defer os.Remove(tmpfile.Name())
+
+	other, err := repository.NewSQLiteDB(tmpfile.Name(), logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to initialize other database: %v", err)
+	}
+	if _, err := other.Execute(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)`); err != nil {
+		t.Fatalf("Failed to create users table: %v", err)
+	}
+
+	ctx := context.Background()
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "schema_diff",
+			Arguments: map[string]any{
+				"other_database": tmpfile.Name(),
+			},
+		},
+	}
+
+	// A file that is not served is out of reach
+	result, err := handler.SchemaDiff(ctx, request)
+	if err != nil {
+		t.Fatalf("SchemaDiff failed: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected a database that is not served to be refused")
+	}
+
+	if err := handler.databases.Add("other", other); err != nil {
+		t.Fatalf("Failed to register database: %v", err)
+	}
+	request.Params.Arguments = map[string]any{"other_database": "other"}
+	result, err = handler.SchemaDiff(ctx, request)
+	if err != nil {
+		t.Fatalf("SchemaDiff failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected successful result, got error")
+	}
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent")
+	}
+
+	for _, expected := range []string{"- table orders", "~ table users (requires rebuild)", `DROP TABLE IF EXISTS "orders"`} {
+		if !containsString(textContent.Text, expected) {
+			t.Errorf("Expected %q in diff output, got: %s", expected, textContent.Text)
+		}
+	}
+}
+
+func TestMCPHandler_SchemaDiff_InvalidDirection(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "schema_diff",
+			Arguments: map[string]any{
+				"other_database": "other.db",
+				"direction":      "sideways",
+			},
+		},
+	}
+
+	result, err := handler.SchemaDiff(context.Background(), request)
+	if err != nil {
+		t.Fatalf("SchemaDiff failed: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected error result for invalid direction")
+	}
+}
@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"github.com/rvarun11/sqlite-mcp/internal/repository"
+)
+
+// toolNamePattern is the tool name format MCP clients accept
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// TableTools returns insert_<table>, get_<table>_by_pk, update_<table>_by_pk
+// and delete_<table>_by_pk tools for the tables of the served databases. A
+// table in several databases gets one set of tools, described by the first
+// database that has it, which run on the selected database. Tables without a
+// primary key only get the insert tool.
+func (h *MCPHandler) TableTools() []server.ServerTool {
+	var tools []server.ServerTool
+	seen := make(map[string]bool)
+	multiple := len(h.databases.Names()) > 1
+	for _, name := range h.databases.Names() {
+		db, err := h.databases.Get(name)
+		if err != nil {
+			continue
+		}
+		tables, err := db.GetSchema()
+		if err != nil {
+			h.logger.Errorf("Failed to load the schema of %s for the table tools: %v", name, err)
+			continue
+		}
+		for _, table := range tables {
+			if seen[table.Name] {
+				continue
+			}
+			seen[table.Name] = true
+			if !toolNamePattern.MatchString("update_" + table.Name + "_by_pk") {
+				h.logger.Warnf("Table %s gets no table tools, its name does not fit in a tool name", table.Name)
+				continue
+			}
+			if multiple && slices.ContainsFunc(table.Columns, func(c models.Column) bool { return c.Name == "database" }) {
+				h.logger.Warnf("Table %s gets no table tools, its column database clashes with the database argument", table.Name)
+				continue
+			}
+			for _, tool := range tableTools(table, db.ReadOnly()) {
+				tool.Tool, tool.Handler = h.WithDatabase(tool.Tool, h.tableToolHandler(tool.Tool.Name, table.Name))
+				tools = append(tools, tool)
+			}
+		}
+	}
+	return tools
+}
+
+// OnSchemaChanged sets a function called after a tool call that may have
+// changed a schema or its annotations, typically to generate the table tools
+// again right away. Changes made outside the server are found by polling.
+func (h *MCPHandler) OnSchemaChanged(fn func()) {
+	h.schemaChanged = fn
+}
+
+func (h *MCPHandler) notifySchemaChanged() {
+	if h.schemaChanged != nil {
+		h.schemaChanged()
+	}
+}
+
+// tableTools describes the tools of a table, the write tools only when the
+// database is writable
+func tableTools(table models.Table, readOnly bool) []server.ServerTool {
+	var key []models.Column
+	for _, column := range table.Columns {
+		if column.PrimaryKey {
+			key = append(key, column)
+		}
+	}
+
+	var tools []server.ServerTool
+	if !readOnly {
+		insert := mcp.NewTool("insert_"+table.Name,
+			mcp.WithDescription(fmt.Sprintf("Insert a row into %s.%s Omitted columns take their default.", table.Name, tableSummary(table))),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(false),
+		)
+		for _, column := range table.Columns {
+			insert.InputSchema.Properties[column.Name] = columnSchema(column)
+			if column.NotNull && column.DefaultValue == nil && !isRowidAlias(column, key) {
+				insert.InputSchema.Required = append(insert.InputSchema.Required, column.Name)
+			}
+		}
+		tools = append(tools, server.ServerTool{Tool: insert})
+	}
+	if len(key) == 0 {
+		return tools
+	}
+
+	get := mcp.NewTool("get_"+table.Name+"_by_pk",
+		mcp.WithDescription(fmt.Sprintf("Get the row of %s with the given primary key.%s", table.Name, tableSummary(table))),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addKey(&get, key)
+	tools = append(tools, server.ServerTool{Tool: get})
+	if readOnly {
+		return tools
+	}
+
+	update := mcp.NewTool("update_"+table.Name+"_by_pk",
+		mcp.WithDescription(fmt.Sprintf("Change columns of the row of %s with the given primary key. Only the provided columns change.", table.Name)),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addKey(&update, key)
+	for _, column := range table.Columns {
+		if !column.PrimaryKey {
+			update.InputSchema.Properties[column.Name] = columnSchema(column)
+		}
+	}
+
+	del := mcp.NewTool("delete_"+table.Name+"_by_pk",
+		mcp.WithDescription(fmt.Sprintf("Delete the row of %s with the given primary key.", table.Name)),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+	addKey(&del, key)
+
+	return append(tools, server.ServerTool{Tool: update}, server.ServerTool{Tool: del})
+}
+
+// addKey adds the primary key columns as required properties
+func addKey(tool *mcp.Tool, key []models.Column) {
+	for _, column := range key {
+		schema := columnSchema(column)
+		if t := jsonType(column.Type); t != nil {
+			schema["type"] = t // A key to look up is never NULL
+		}
+		delete(schema, "default")
+		tool.InputSchema.Properties[column.Name] = schema
+		tool.InputSchema.Required = append(tool.InputSchema.Required, column.Name)
+	}
+}
+
+// tableSummary returns the table description from its annotation, if any,
+// as a sentence to append
+func tableSummary(table models.Table) string {
+	if table.Description == "" {
+		return ""
+	}
+	return " " + strings.TrimSuffix(table.Description, ".") + "."
+}
+
+// columnSchema derives the JSON Schema of a column from its declared type,
+// NOT NULL constraint, default and annotation
+func columnSchema(column models.Column) map[string]any {
+	schema := make(map[string]any)
+	if t := jsonType(column.Type); t != nil {
+		if column.NotNull {
+			schema["type"] = t
+		} else {
+			schema["type"] = []any{t, "null"}
+		}
+	}
+
+	var description []string
+	if column.Description != "" {
+		description = append(description, column.Description)
+	}
+	if column.Type != "" {
+		description = append(description, "SQLite type "+column.Type)
+	}
+	if column.Unit != "" {
+		description = append(description, "unit "+column.Unit)
+	}
+	if column.DefaultValue != nil {
+		if value, ok := defaultValue(*column.DefaultValue); ok {
+			if value != nil {
+				schema["default"] = value
+			}
+		} else {
+			description = append(description, "defaults to "+*column.DefaultValue)
+		}
+	}
+	if len(description) > 0 {
+		schema["description"] = strings.Join(description, ", ")
+	}
+	if len(column.EnumValues) > 0 && jsonType(column.Type) == "string" {
+		schema["enum"] = column.EnumValues
+	}
+	return schema
+}
+
+// jsonType maps a declared column type to a JSON Schema type following
+// SQLite's type affinity rules, nil when any value fits. Boolean and date
+// types, which have numeric affinity, are mapped as they are meant.
+func jsonType(declared string) any {
+	t := strings.ToUpper(declared)
+	switch {
+	case strings.Contains(t, "JSON"):
+		return nil // Objects and arrays are stored as JSON text
+	case strings.Contains(t, "BOOL"):
+		return "boolean"
+	case strings.Contains(t, "DATE") || strings.Contains(t, "TIME"):
+		return "string"
+	case strings.Contains(t, "INT"):
+		return "integer"
+	case strings.Contains(t, "CHAR") || strings.Contains(t, "CLOB") || strings.Contains(t, "TEXT"):
+		return "string"
+	case t == "" || strings.Contains(t, "BLOB"):
+		return nil
+	}
+	return "number" // REAL and NUMERIC affinity
+}
+
+// defaultValue parses a column default that is a literal. It reports false
+// for expressions such as CURRENT_TIMESTAMP.
+func defaultValue(literal string) (any, bool) {
+	if strings.EqualFold(literal, "NULL") {
+		return nil, true
+	}
+	if strings.EqualFold(literal, "TRUE") || strings.EqualFold(literal, "FALSE") {
+		return strings.EqualFold(literal, "TRUE"), true
+	}
+	if len(literal) >= 2 && literal[0] == '\'' && literal[len(literal)-1] == '\'' {
+		return strings.ReplaceAll(literal[1:len(literal)-1], "''", "'"), true
+	}
+	if i, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return i, true
+	}
+	if f, err := strconv.ParseFloat(literal, 64); err == nil {
+		return f, true
+	}
+	return nil, false
+}
+
+// isRowidAlias reports whether a column is the INTEGER PRIMARY KEY, which
+// SQLite fills in when omitted
+func isRowidAlias(column models.Column, key []models.Column) bool {
+	return len(key) == 1 && key[0].Name == column.Name && strings.EqualFold(column.Type, "INTEGER")
+}
+
+// tableToolHandler returns the handler of a generated table tool. The table
+// is looked up when called, so it works on the current schema of the session.
+func (h *MCPHandler) tableToolHandler(tool, table string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		h.logger.Infof("Handling %s request", tool)
+
+		args := maps.Clone(request.GetArguments())
+		delete(args, "database")
+
+		repo := h.sessionRepo(ctx)
+		if err := checkColumnArguments(repo, table, args); err != nil {
+			return toolError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+		}
+
+		var result *models.ExecuteResult
+		var err error
+		switch {
+		case strings.HasPrefix(tool, "insert_"):
+			result, err = repo.InsertRow(table, args)
+		case strings.HasPrefix(tool, "update_"):
+			result, err = repo.UpdateRowByPK(table, args)
+		case strings.HasPrefix(tool, "delete_"):
+			result, err = repo.DeleteRowByPK(table, args)
+		default:
+			rows, err := repo.GetRowByPK(table, args)
+			if err != nil {
+				h.logger.Errorf("%s failed: %v", tool, err)
+				return toolError(fmt.Sprintf("Failed to get row: %v", err)), nil
+			}
+			if rows.Count == 0 {
+				return toolText(fmt.Sprintf("No row of %s has this primary key.", table)), nil
+			}
+			return toolText(formatQueryResponse(rows)), nil
+		}
+		if err != nil {
+			h.logger.Errorf("%s failed: %v", tool, err)
+			return toolError(fmt.Sprintf("Failed to change %s: %v", table, err)), nil
+		}
+		return toolText(formatExecuteResponse(result)), nil
+	}
+}
+
+// checkColumnArguments validates arguments against the JSON Schema of their
+// columns in the current schema. SQLite's type affinity would store a value
+// of the wrong type, such as text in an INTEGER column, without complaint.
+func checkColumnArguments(repo *repository.SQLiteDB, table string, args map[string]any) error {
+	tables, err := repo.GetSchema()
+	if err != nil {
+		return nil // Left to the repository, which reports the failure
+	}
+	i := slices.IndexFunc(tables, func(t models.Table) bool { return t.Name == table })
+	if i < 0 {
+		return nil
+	}
+	for _, column := range tables[i].Columns {
+		value, ok := args[column.Name]
+		if !ok {
+			continue
+		}
+		schema := columnSchema(column)
+		if !matchesSchemaType(schema["type"], value) {
+			return fmt.Errorf("column %s must be of type %s, got %v", column.Name, formatSchemaType(schema["type"]), value)
+		}
+		if allowed, ok := schema["enum"].([]string); ok && value != nil && !slices.Contains(allowed, fmt.Sprint(value)) {
+			return fmt.Errorf("column %s must be one of %s, got %v", column.Name, strings.Join(allowed, ", "), value)
+		}
+	}
+	return nil
+}
+
+// matchesSchemaType reports whether a value decoded from JSON has one of the
+// JSON Schema types, any type when nil
+func matchesSchemaType(schemaType any, value any) bool {
+	switch t := schemaType.(type) {
+	case nil:
+		return true
+	case []any:
+		return slices.ContainsFunc(t, func(t any) bool { return matchesSchemaType(t, value) })
+	case string:
+		switch v := value.(type) {
+		case nil:
+			return t == "null"
+		case string:
+			return t == "string"
+		case bool:
+			return t == "boolean"
+		case int, int64:
+			return t == "integer" || t == "number"
+		case float64:
+			return t == "number" || t == "integer" && v == math.Trunc(v)
+		}
+	}
+	return false
+}
+
+// formatSchemaType renders a JSON Schema type for error messages
+func formatSchemaType(schemaType any) string {
+	if types, ok := schemaType.([]any); ok {
+		names := make([]string, len(types))
+		for i, t := range types {
+			names[i] = fmt.Sprint(t)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(schemaType)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func findTool(t *testing.T, tools []server.ServerTool, name string) server.ServerTool {
+	t.Helper()
+	for _, tool := range tools {
+		if tool.Tool.Name == name {
+			return tool
+		}
+	}
+	t.Fatalf("Tool %s not found", name)
+	return server.ServerTool{}
+}
+
+func TestMCPHandler_TableTools(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	tools := handler.TableTools()
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Tool.Name)
+	}
+	for _, name := range []string{"insert_users", "get_users_by_pk", "update_users_by_pk", "delete_users_by_pk", "insert_orders", "delete_orders_by_pk"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("Expected tool %s, got %v", name, names)
+		}
+	}
+
+	insert := findTool(t, tools, "insert_orders").Tool
+	if !slices.Equal(insert.InputSchema.Required, []string{"user_id", "product_name"}) {
+		t.Errorf("Expected the NOT NULL columns without default to be required, got %v", insert.InputSchema.Required)
+	}
+	quantity := insert.InputSchema.Properties["quantity"].(map[string]any)
+	if !slices.Equal(quantity["type"].([]any), []any{"integer", "null"}) || quantity["default"] != int64(1) {
+		t.Errorf("Expected a nullable integer with default 1, got %v", quantity)
+	}
+	if price := insert.InputSchema.Properties["price"].(map[string]any); !slices.Equal(price["type"].([]any), []any{"number", "null"}) {
+		t.Errorf("Expected DECIMAL to map to number, got %v", price)
+	}
+	users := findTool(t, tools, "insert_users").Tool
+	if created := users.InputSchema.Properties["created_at"].(map[string]any); created["description"] != "SQLite type DATETIME, defaults to CURRENT_TIMESTAMP" {
+		t.Errorf("Expected the default expression to be described, got %v", created)
+	}
+
+	get := findTool(t, tools, "get_users_by_pk")
+	if !slices.Equal(get.Tool.InputSchema.Required, []string{"id"}) || get.Tool.InputSchema.Properties["id"].(map[string]any)["type"] != "integer" {
+		t.Errorf("Expected the primary key as the only required argument, got %+v", get.Tool.InputSchema)
+	}
+
+	ctx := context.Background()
+	call := func(tool server.ServerTool, args map[string]any) (string, bool) {
+		result, err := tool.Handler(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: tool.Tool.Name, Arguments: args}})
+		if err != nil {
+			t.Fatalf("%s failed: %v", tool.Tool.Name, err)
+		}
+		return result.Content[0].(*mcp.TextContent).Text, result.IsError
+	}
+
+	if text, isError := call(findTool(t, tools, "insert_orders"), map[string]any{"user_id": float64(1), "product_name": "Lamp"}); isError {
+		t.Fatalf("insert_orders failed: %s", text)
+	}
+	if text, isError := call(findTool(t, tools, "update_orders_by_pk"), map[string]any{"id": float64(1), "quantity": float64(3)}); isError || !containsString(text, "Rows Affected: 1") {
+		t.Errorf("Expected one updated row, got %s", text)
+	}
+	if text, _ := call(findTool(t, tools, "get_orders_by_pk"), map[string]any{"id": float64(1)}); !containsString(text, "quantity=3") {
+		t.Errorf("Expected the updated row, got %s", text)
+	}
+	if text, isError := call(findTool(t, tools, "insert_orders"), map[string]any{"product_name": "Chair"}); !isError || !containsString(text, "NOT NULL") {
+		t.Errorf("Expected the constraint violation, got %s", text)
+	}
+	// Arguments are checked against the declared types, which SQLite does not enforce
+	for _, args := range []map[string]any{
+		{"user_id": "notanumber", "product_name": "Lamp"},
+		{"user_id": float64(1), "product_name": "Lamp", "quantity": 1.5},
+		{"user_id": float64(1), "product_name": float64(7)},
+		{"user_id": nil, "product_name": "Lamp"},
+	} {
+		if text, isError := call(findTool(t, tools, "insert_orders"), args); !isError || !containsString(text, "must be of type") {
+			t.Errorf("Expected a type error for %v, got %s", args, text)
+		}
+	}
+	if text, isError := call(findTool(t, tools, "update_orders_by_pk"), map[string]any{"id": "1", "quantity": float64(2)}); !isError || !containsString(text, "column id must be of type integer") {
+		t.Errorf("Expected a type error for the key, got %s", text)
+	}
+	if text, isError := call(findTool(t, tools, "insert_orders"), map[string]any{"user_id": float64(1), "product_name": "Desk", "price": float64(10), "quantity": nil}); isError {
+		t.Errorf("Expected whole numbers and nulls of nullable columns to be accepted, got %s", text)
+	}
+	if text, _ := call(findTool(t, tools, "get_orders_by_pk"), map[string]any{"id": float64(7)}); !containsString(text, "No row of orders") {
+		t.Errorf("Expected no row, got %s", text)
+	}
+}
+
+func TestMCPHandler_OnSchemaChanged(t *testing.T) {
+	handler, cleanup := setupTestMCPHandler(t)
+	defer cleanup()
+
+	var tools []server.ServerTool
+	handler.OnSchemaChanged(func() { tools = handler.TableTools() })
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "execute", Arguments: map[string]any{"sql": "CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)"}}}
+	if result, err := handler.Execute(context.Background(), request); err != nil || result.IsError {
+		t.Fatalf("Execute failed: %+v (%v)", result, err)
+	}
+	// The tools are there when the call returns, without waiting for a poll
+	findTool(t, tools, "insert_notes")
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolGate hides the tools whose name matches a disabled pattern from clients
+// and refuses calls to them. Every tool stays registered with the server, so
+// the disabled patterns can be replaced while the server runs.
+type ToolGate struct {
+	mu       sync.RWMutex
+	names    []string // Tools registered through Track
+	disabled []string
+}
+
+func NewToolGate(disabled []string) *ToolGate {
+	return &ToolGate{disabled: slices.Clone(disabled)}
+}
+
+// Track records a tool registered with the server, so SetDisabled can tell
+// whether the exposed tools change
+func (g *ToolGate) Track(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !slices.Contains(g.names, name) {
+		g.names = append(g.names, name)
+	}
+}
+
+// Tracked reports whether a tool is registered with the server
+func (g *ToolGate) Tracked(name string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return slices.Contains(g.names, name)
+}
+
+// Untrack forgets a tool removed from the server
+func (g *ToolGate) Untrack(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.names = slices.DeleteFunc(g.names, func(n string) bool { return n == name })
+}
+
+// Enabled reports whether a tool is exposed to clients
+func (g *ToolGate) Enabled(name string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return enabled(g.disabled, name)
+}
+
+// SetDisabled replaces the disabled patterns and reports whether this changes
+// which of the tracked tools are exposed
+func (g *ToolGate) SetDisabled(disabled []string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	changed := false
+	for _, name := range g.names {
+		if enabled(g.disabled, name) != enabled(disabled, name) {
+			changed = true
+			break
+		}
+	}
+	g.disabled = slices.Clone(disabled)
+	return changed
+}
+
+// Filter removes the disabled tools from tools/list, see server.WithToolFilter
+func (g *ToolGate) Filter(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return slices.DeleteFunc(tools, func(tool mcp.Tool) bool {
+		return !enabled(g.disabled, tool.Name)
+	})
+}
+
+// Middleware refuses calls to disabled tools, see server.WithToolHandlerMiddleware
+func (g *ToolGate) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !g.Enabled(request.Params.Name) {
+			return toolError(fmt.Sprintf("Tool %s is disabled by the server configuration", request.Params.Name)), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+func enabled(disabled []string, name string) bool {
+	for _, pattern := range disabled {
+		if ok, _ := path.Match(pattern, name); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ToolSync keeps a group of generated tools registered with the server in
+// line with their definitions. Set only removes and adds the tools that
+// changed, so clients are notified once per change and unchanged tools stay
+// callable throughout. A generated tool never replaces a tool registered
+// outside the group, such as insert_rows for a table named rows.
+type ToolSync struct {
+	mu     sync.Mutex
+	server *server.MCPServer
+	gate   *ToolGate
+	tools  map[string]mcp.Tool // Tools of the group currently registered
+}
+
+func NewToolSync(s *server.MCPServer, gate *ToolGate) *ToolSync {
+	return &ToolSync{server: s, gate: gate, tools: make(map[string]mcp.Tool)}
+}
+
+// Set replaces the tools of the group. A handler is only registered again
+// when its tool definition changed, so handlers must look up anything else
+// they depend on when called.
+func (s *ToolSync) Set(tools []server.ServerTool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := make(map[string]mcp.Tool, len(tools))
+	var changed []server.ServerTool
+	for _, tool := range tools {
+		name := tool.Tool.Name
+		if _, ok := next[name]; ok {
+			continue
+		}
+		if _, ok := s.tools[name]; !ok && s.gate.Tracked(name) {
+			continue
+		}
+		next[name] = tool.Tool
+		if current, ok := s.tools[name]; !ok || !reflect.DeepEqual(current, tool.Tool) {
+			changed = append(changed, tool)
+		}
+	}
+
+	var removed []string
+	for name := range s.tools {
+		if _, ok := next[name]; !ok {
+			removed = append(removed, name)
+			s.gate.Untrack(name)
+		}
+	}
+	if len(removed) > 0 {
+		s.server.DeleteTools(removed...)
+	}
+	if len(changed) > 0 {
+		for _, tool := range changed {
+			s.gate.Track(tool.Tool.Name)
+		}
+		s.server.AddTools(changed...)
+	}
+	s.tools = next
+}
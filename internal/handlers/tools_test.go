@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestToolGate_DisablesTools(t *testing.T) {
+	gate := NewToolGate([]string{"import_*"})
+	for _, name := range []string{"query", "import_csv", "import_json"} {
+		gate.Track(name)
+	}
+
+	tools := gate.Filter(context.Background(), []mcp.Tool{{Name: "query"}, {Name: "import_csv"}, {Name: "import_json"}})
+	if len(tools) != 1 || tools[0].Name != "query" {
+		t.Errorf("Expected only query to be listed, got %v", tools)
+	}
+
+	called := false
+	handler := gate.Middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = true
+		return toolText("ok"), nil
+	})
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "import_csv"
+	result, err := handler(context.Background(), request)
+	if err != nil || !result.IsError || called {
+		t.Errorf("Expected a call to a disabled tool to be refused, got %+v (%v)", result, err)
+	}
+
+	// Only a change to the exposed tools is reported
+	if gate.SetDisabled([]string{"import_csv", "import_json"}) {
+		t.Error("Expected no change when the same tools stay disabled")
+	}
+	if !gate.SetDisabled([]string{"query"}) {
+		t.Error("Expected a change when other tools are disabled")
+	}
+	if gate.Enabled("query") || !gate.Enabled("import_csv") {
+		t.Error("Expected query to be disabled and import_csv enabled")
+	}
+}
+
+// listedTools returns the names of the tools a server lists to clients
+func listedTools(t *testing.T, s *server.MCPServer) []string {
+	t.Helper()
+	response := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Failed to encode tools/list response: %v", err)
+	}
+	var list struct {
+		Result struct {
+			Tools []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatalf("Failed to decode tools/list response: %v", err)
+	}
+	var names []string
+	for _, tool := range list.Result.Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestToolSync_ReplacesTools(t *testing.T) {
+	gate := NewToolGate(nil)
+	s := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true), server.WithToolFilter(gate.Filter))
+	sync := NewToolSync(s, gate)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return toolText("ok"), nil
+	}
+	sync.Set([]server.ServerTool{
+		{Tool: mcp.NewTool("query_a"), Handler: handler},
+		{Tool: mcp.NewTool("query_b"), Handler: handler},
+	})
+	if names := listedTools(t, s); !slices.Equal(names, []string{"query_a", "query_b"}) {
+		t.Errorf("Expected query_a and query_b, got %v", names)
+	}
+
+	sync.Set([]server.ServerTool{
+		{Tool: mcp.NewTool("query_b", mcp.WithDescription("changed")), Handler: handler},
+		{Tool: mcp.NewTool("query_c"), Handler: handler},
+	})
+	if names := listedTools(t, s); !slices.Equal(names, []string{"query_b", "query_c"}) {
+		t.Errorf("Expected query_b and query_c, got %v", names)
+	}
+
+	// Generated tools never replace built-in ones
+	gate.Track("insert_rows")
+	sync.Set([]server.ServerTool{
+		{Tool: mcp.NewTool("query_c"), Handler: handler},
+		{Tool: mcp.NewTool("insert_rows", mcp.WithDescription("generated")), Handler: handler},
+	})
+	if names := listedTools(t, s); !slices.Equal(names, []string{"query_c"}) {
+		t.Errorf("Expected the clashing tool to be left out, got %v", names)
+	}
+
+	// Removed tools are no longer tracked by the gate
+	if gate.SetDisabled([]string{"query_a"}) {
+		t.Error("Expected disabling a removed tool not to change the exposed tools")
+	}
+}
@@ -8,6 +8,10 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+// level is shared by the loggers NewLogger builds, so SetDebug changes their
+// level while they are in use
+var level = zap.NewAtomicLevel()
+
 // NewLogger creates a new zap sugared logger instance
 func NewLogger(cfg *config.Config) (*zap.SugaredLogger, error) {
 	loggerConfig := buildConfig(cfg)
@@ -35,10 +39,16 @@ func buildConfig(appConfig *config.Config) zap.Config {
 	config.EncoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout(time.Kitchen)
 	config.OutputPaths = []string{"stderr"} // preferred for mcps
 
-	if appConfig.Debug {
-		config.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+	SetDebug(appConfig.Debug)
+	config.Level = level
+	return config
+}
+
+// SetDebug switches the loggers built by NewLogger between the debug and info level
+func SetDebug(debug bool) {
+	if debug {
+		level.SetLevel(zap.DebugLevel)
 	} else {
-		config.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
+		level.SetLevel(zap.InfoLevel)
 	}
-	return config
 }
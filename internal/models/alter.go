@@ -0,0 +1,36 @@
+package models
+
+// Operations supported by AlterTable
+const (
+	AlterAddColumn      = "add_column"
+	AlterDropColumn     = "drop_column"
+	AlterRenameColumn   = "rename_column"
+	AlterRetypeColumn   = "retype_column"
+	AlterModifyColumn   = "modify_column"
+	AlterAddConstraint  = "add_constraint"
+	AlterDropConstraint = "drop_constraint"
+)
+
+type AlterTableSpec struct {
+	Table      string           `json:"table"`
+	Operations []AlterOperation `json:"operations"`
+	DryRun     bool             `json:"dry_run"`
+}
+
+type AlterOperation struct {
+	Op         string `json:"op"`
+	Column     string `json:"column,omitempty"`
+	NewName    string `json:"new_name,omitempty"`   // rename_column
+	Type       string `json:"type,omitempty"`       // add_column, retype_column
+	Definition string `json:"definition,omitempty"` // Column constraints for add/modify_column, table constraint for add_constraint
+	Using      string `json:"using,omitempty"`      // Expression over the old row filling the column, e.g. CAST(price AS INTEGER)
+	Name       string `json:"name,omitempty"`       // drop_constraint: constraint name or its full text
+}
+
+type AlterTableResult struct {
+	Table          string   `json:"table"`
+	CreateSQL      string   `json:"create_sql"`
+	Statements     []string `json:"statements"`
+	DroppedIndexes []string `json:"dropped_indexes,omitempty"` // Indexes on dropped columns
+	DryRun         bool     `json:"dry_run"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// Annotation describes a table (Column empty) or one of its columns, since
+// SQLite has no COMMENT syntax
+type Annotation struct {
+	Table       string   `json:"table"`
+	Column      string   `json:"column,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Unit        string   `json:"unit,omitempty"`        // Unit of measure, e.g. "cents" or "ms"
+	EnumValues  []string `json:"enum_values,omitempty"` // Allowed values for coded columns
+	Sensitivity string   `json:"sensitivity,omitempty"` // e.g. "public", "internal", "pii", "secret"
+}
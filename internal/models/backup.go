@@ -0,0 +1,35 @@
+package models
+
+// Backup methods
+const (
+	BackupOnline = "online" // SQLite online backup API, a page-by-page copy
+	BackupVacuum = "vacuum" // VACUUM INTO, a compacted copy
+)
+
+type BackupOptions struct {
+	Method string // Defaults to online
+	Label  string // Appended to the file name
+	Keep   int    // Backups of this database to keep, 0 keeps the configured number
+}
+
+// Backup is a backup file with the metadata stored beside it
+type Backup struct {
+	Name      string `json:"name"` // File name inside the backup directory
+	Path      string `json:"path"`
+	Source    string `json:"source"`
+	Method    string `json:"method"`
+	Label     string `json:"label,omitempty"`
+	CreatedAt string `json:"created_at"`
+	Size      int64  `json:"size"`
+	Checksum  string `json:"sha256"`
+}
+
+type BackupResult struct {
+	Backup Backup   `json:"backup"`
+	Pruned []string `json:"pruned,omitempty"` // Backups removed by the retention policy
+}
+
+type RestoreResult struct {
+	Restored Backup  `json:"restored"`
+	Safety   *Backup `json:"safety,omitempty"` // Backup of the state replaced by the restore
+}
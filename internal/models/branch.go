@@ -0,0 +1,44 @@
+package models
+
+// Branch is a scratch copy of the main database that one session works on
+// until it is merged into the main database or discarded
+type Branch struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Session   string `json:"session,omitempty"`
+	CreatedAt string `json:"created_at"`
+	Size      int64  `json:"size"`
+	Active    bool   `json:"active"` // Whether the requesting session works on the branch
+}
+
+type BranchDiff struct {
+	Branch string      `json:"branch"`
+	Schema *SchemaDiff `json:"schema"`
+	Data   *DataDiff   `json:"data"`
+}
+
+type BranchMergeResult struct {
+	Branch string `json:"branch"`
+	Merged bool   `json:"merged"`
+	// FastForward is set when the branch changed the schema and main was
+	// unchanged, so main was replaced with the branch as a whole
+	FastForward bool            `json:"fast_forward"`
+	Schema      []string        `json:"schema,omitempty"` // Schema changes made on the branch
+	Tables      []TableMerge    `json:"tables,omitempty"`
+	Conflicts   []MergeConflict `json:"conflicts,omitempty"`
+}
+
+type TableMerge struct {
+	Table    string `json:"table"`
+	Inserted int64  `json:"inserted"`
+	Updated  int64  `json:"updated"`
+	Deleted  int64  `json:"deleted"`
+}
+
+// MergeConflict is a row changed on the branch that was also changed in main
+// since the branch was created
+type MergeConflict struct {
+	Table  string         `json:"table"`
+	Key    map[string]any `json:"key"`
+	Reason string         `json:"reason"`
+}
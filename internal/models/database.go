@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Policies limit the statements run against a database, whichever tool runs them
+const (
+	PolicyFull       = "full"        // No restrictions
+	PolicyNoDDL      = "no-ddl"      // Rows may change, the schema may not
+	PolicyAppendOnly = "append-only" // Rows may only be inserted
+)
+
+// MemoryDatabase is the path of a database that lives in memory only
+const MemoryDatabase = ":memory:"
+
+type DatabaseOptions struct {
+	ReadOnly   bool         // Open the file read-only
+	Policy     string       // One of the Policy constants, full when empty
+	Attach     []Attachment // Secondary databases attached to every connection
+	Connection ConnectionOptions
+}
+
+// ConnectionOptions tune the SQLite connections of a database, zero values
+// keep SQLite's defaults
+type ConnectionOptions struct {
+	JournalMode string   // e.g. WAL, which lets reads run while a write is in progress
+	Synchronous string   // OFF, NORMAL, FULL or EXTRA
+	BusyTimeout int      // Milliseconds to wait for a lock before failing with SQLITE_BUSY, 5000 when 0
+	CacheSize   int      // Page cache size in pages, or in KiB when negative
+	MmapSize    int64    // Bytes of the file to access through memory-mapped I/O
+	ForeignKeys bool     // Enforce foreign key constraints
+	Pragmas     []string // Further pragmas as name=value, run after the others
+	Readers     int      // Connections for reads beside the single writer connection, 24 when 0
+}
+
+// Limits bound the statements run by the query and execute tools, zero values
+// leave them unbounded
+type Limits struct {
+	MaxRows      int           // Rows a query returns at most, the result is marked truncated beyond
+	QueryTimeout time.Duration // Statements running longer are interrupted
+}
+
+// Attachment is a secondary database file attached under an alias, so queries
+// can join its tables as alias.table
+type Attachment struct {
+	Alias    string `json:"alias"`
+	Path     string `json:"path"`
+	Writable bool   `json:"writable"` // Attached read-only unless set
+}
+
+// Database describes a database served by the server
+type Database struct {
+	Name     string       `json:"name"`
+	Path     string       `json:"path"`
+	Default  bool         `json:"default"` // Used by tools called without a database argument
+	ReadOnly bool         `json:"read_only"`
+	Policy   string       `json:"policy"`
+	Tables   int          `json:"tables"`
+	Size     int64        `json:"size"`
+	Attached []Attachment `json:"attached,omitempty"`
+}
@@ -0,0 +1,47 @@
+package models
+
+type DataDiffOptions struct {
+	Table     string // Only compare this table, all common tables when empty
+	Limit     int    // Maximum rows listed per table and change kind
+	FromOther bool   // Treat the other database as the source
+}
+
+type DataDiff struct {
+	Source        string          `json:"source"`
+	Target        string          `json:"target"`
+	Identical     bool            `json:"identical"`
+	Tables        []TableDataDiff `json:"tables,omitempty"`
+	SkippedTables []SkippedTable  `json:"skipped_tables,omitempty"`
+}
+
+// TableDataDiff lists the rows of one table that differ between the source and
+// the target. Counts are always complete, row lists are capped by the limit.
+type TableDataDiff struct {
+	Table        string           `json:"table"`
+	Key          []string         `json:"key,omitempty"` // Empty when rows are matched on their full content
+	Columns      []string         `json:"columns"`       // Columns present in both tables
+	Inserted     int64            `json:"inserted"`      // Rows only in the target
+	Deleted      int64            `json:"deleted"`       // Rows only in the source
+	Updated      int64            `json:"updated"`       // Rows whose key exists in both with different values
+	InsertedRows []map[string]any `json:"inserted_rows,omitempty"`
+	DeletedRows  []map[string]any `json:"deleted_rows,omitempty"`
+	UpdatedRows  []RowChange      `json:"updated_rows,omitempty"`
+	Truncated    bool             `json:"truncated"`
+	Note         string           `json:"note,omitempty"`
+}
+
+type RowChange struct {
+	Key     map[string]any `json:"key"`
+	Changes []ValueChange  `json:"changes"`
+}
+
+type ValueChange struct {
+	Column string `json:"column"`
+	From   any    `json:"from"`
+	To     any    `json:"to"`
+}
+
+type SkippedTable struct {
+	Table  string `json:"table"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,18 @@
+package models
+
+type DumpOptions struct {
+	Tables []string // LIKE patterns matched against table names, empty dumps everything
+}
+
+type DumpResult struct {
+	Path    string `json:"path,omitempty"`
+	Tables  int    `json:"tables"`
+	Rows    int64  `json:"rows"`
+	Objects int    `json:"objects"` // Indexes, triggers and views
+	Bytes   int64  `json:"bytes"`
+}
+
+type LoadResult struct {
+	Statements int `json:"statements"`
+	Tables     int `json:"tables"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// Export formats
+const (
+	ExportCSV   = "csv"
+	ExportJSONL = "jsonl" // One JSON object per line
+	ExportJSON  = "json"  // One JSON array of objects
+	ExportSQL   = "sql"   // INSERT statements
+)
+
+type ExportOptions struct {
+	SQL       string
+	Path      string
+	Format    string // Defaults to the file extension, then csv
+	Table     string // Target table of SQL INSERT statements, defaults to the file name
+	Overwrite bool
+}
+
+type ExportResult struct {
+	Path    string   `json:"path"`
+	Format  string   `json:"format"`
+	Columns []string `json:"columns"`
+	Rows    int64    `json:"rows"`
+	Bytes   int64    `json:"bytes"`
+}
@@ -0,0 +1,46 @@
+package models
+
+type CSVImportOptions struct {
+	Path      string
+	Table     string
+	Delimiter rune   // Defaults to ','
+	NoHeader  bool   // First row is data, columns are named column_1, column_2, ...
+	Encoding  string // utf-8 (default), latin1 or windows-1252
+	BatchSize int    // Rows per transaction
+}
+
+// Flatten modes for nested JSON objects
+const (
+	FlattenColumns = "columns" // Prefixed columns, e.g. address_city
+	FlattenJSON    = "json"    // One column holding the object as JSON text
+)
+
+type JSONImportOptions struct {
+	Path        string
+	Table       string
+	Root        string // Dot separated path to the array inside a top-level object, e.g. data.items
+	Flatten     string // FlattenColumns (default) or FlattenJSON
+	SplitArrays bool   // Nested arrays become child tables instead of JSON text columns
+	BatchSize   int    // Records per transaction
+}
+
+type ImportResult struct {
+	Table        string         `json:"table"`
+	Created      bool           `json:"created"` // Table was created with inferred column types
+	Columns      []ImportColumn `json:"columns"`
+	RowsInserted int64          `json:"rows_inserted"`
+	RowsRejected int64          `json:"rows_rejected"`
+	Rejected     []RejectedRow  `json:"rejected,omitempty"` // First rejected rows with reasons
+	ChildTables  []ImportResult `json:"child_tables,omitempty"`
+}
+
+type ImportColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type RejectedRow struct {
+	Line   int    `json:"line,omitempty"`   // Line of CSV input
+	Record int    `json:"record,omitempty"` // Record number of JSON input
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// Conflict modes of inserting rows, without one a conflict fails the insert
+const (
+	OnConflictIgnore  = "ignore"  // Skip rows that conflict
+	OnConflictReplace = "replace" // Delete the conflicting rows first
+	OnConflictUpsert  = "upsert"  // Update the conflicting row on ConflictColumns
+)
+
+type InsertRowsOptions struct {
+	Table      string           `json:"table"`
+	Rows       []map[string]any `json:"rows"` // Column values by column name
+	OnConflict string           `json:"on_conflict,omitempty"`
+	// ConflictColumns are the columns of a primary key or unique index an
+	// upsert matches on, the other given columns are updated
+	ConflictColumns []string `json:"conflict_columns,omitempty"`
+}
+
+type InsertRowsResult struct {
+	Table   string  `json:"table"`
+	Written int     `json:"written"`
+	RowIDs  []int64 `json:"rowids,omitempty"`  // Rowids of the written rows in order, empty for WITHOUT ROWID tables
+	Skipped []int   `json:"skipped,omitempty"` // Indexes of the rows ignored due to a conflict
+}
@@ -0,0 +1,20 @@
+package models
+
+// Migration states reported by ListMigrations
+const (
+	MigrationPending  = "pending"
+	MigrationApplied  = "applied"
+	MigrationModified = "modified" // Applied, but the file changed since
+	MigrationMissing  = "missing"  // Applied, but the file no longer exists
+)
+
+type Migration struct {
+	Version   int64  `json:"version"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Checksum  string `json:"checksum,omitempty"` // SHA-256 of the up file
+	AppliedAt string `json:"applied_at,omitempty"`
+	HasDown   bool   `json:"has_down"`
+	UpPath    string `json:"up_path,omitempty"`
+	DownPath  string `json:"down_path,omitempty"`
+}
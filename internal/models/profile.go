@@ -0,0 +1,51 @@
+package models
+
+type ProfileOptions struct {
+	TopN       int `json:"top_n"`       // Number of most frequent values to report per column
+	SampleRows int `json:"sample_rows"` // Maximum number of rows scanned for column statistics
+}
+
+type TableProfile struct {
+	Table        string          `json:"table"`
+	RowCount     int64           `json:"row_count"`
+	Sampled      bool            `json:"sampled"`       // True when column statistics cover only a sample
+	SampleRows   int64           `json:"sample_rows"`   // Number of rows the column statistics are based on
+	SampleMethod string          `json:"sample_method"` // "all", "rowid" or "random_order", as for SampleResult
+	DataVersion  int64           `json:"data_version"`  // Database data version the profile was computed at
+	Cached       bool            `json:"cached"`
+	Columns      []ColumnProfile `json:"columns"`
+}
+
+type ColumnProfile struct {
+	Name                string           `json:"name"`
+	DeclaredType        string           `json:"declared_type"`
+	NullFraction        float64          `json:"null_fraction"`
+	DistinctCount       int64            `json:"distinct_count"`
+	DistinctApproximate bool             `json:"distinct_approximate"` // True when estimated with HyperLogLog
+	Min                 any              `json:"min,omitempty"`
+	Max                 any              `json:"max,omitempty"`
+	TopValues           []ValueFrequency `json:"top_values,omitempty"`
+	StorageClasses      map[string]int64 `json:"storage_classes"` // Actual storage class (typeof) distribution
+}
+
+type ValueFrequency struct {
+	Value any   `json:"value"`
+	Count int64 `json:"count"`
+}
+
+type SampleOptions struct {
+	Limit          int    `json:"limit"`            // Number of rows to return
+	StratifyBy     string `json:"stratify_by"`      // Optional column to stratify the sample by
+	MaxValueLength int    `json:"max_value_length"` // Text values longer than this are truncated
+}
+
+type SampleResult struct {
+	Table        string           `json:"table"`
+	Columns      []string         `json:"columns"`
+	Rows         []map[string]any `json:"rows"`
+	Count        int              `json:"count"`
+	TotalRows    int64            `json:"total_rows"`
+	Method       string           `json:"method"` // "all", "rowid" or "random_order"
+	StratifiedBy string           `json:"stratified_by,omitempty"`
+	Strata       []ValueFrequency `json:"strata,omitempty"` // Rows returned per stratum value
+}
@@ -0,0 +1,36 @@
+package models
+
+type Relationship struct {
+	FromTable   string   `json:"from_table"`
+	FromColumns []string `json:"from_columns"` // Referencing columns, ordered by FK sequence
+	ToTable     string   `json:"to_table"`
+	ToColumns   []string `json:"to_columns"` // Referenced columns, ordered by FK sequence
+	Inferred    bool     `json:"inferred"`   // True when not declared as a FOREIGN KEY
+	Confidence  float64  `json:"confidence"` // 1 for declared foreign keys
+	Reason      string   `json:"reason,omitempty"`
+}
+
+type InferenceOptions struct {
+	Table         string   `json:"table,omitempty"`          // Only relationships from or to this table
+	SampleSize    int      `json:"sample_size"`              // Distinct values checked for containment per candidate
+	MinConfidence *float64 `json:"min_confidence,omitempty"` // Candidates scoring below this are dropped, nil for the default
+}
+
+type JoinPathOptions struct {
+	IncludeInferred bool `json:"include_inferred"` // Also follow relationships inferred from column names
+	MaxDepth        int  `json:"max_depth"`        // Maximum number of joins in a path
+	Limit           int  `json:"limit"`            // Maximum number of paths returned
+}
+
+type JoinStep struct {
+	Table        string       `json:"table"` // Table joined in this step
+	On           string       `json:"on"`    // Join condition
+	Relationship Relationship `json:"relationship"`
+}
+
+type JoinPath struct {
+	Tables   []string   `json:"tables"`
+	Steps    []JoinStep `json:"steps"`
+	SQL      string     `json:"sql"`      // Ready-to-use FROM ... JOIN ... ON ... clause
+	Inferred bool       `json:"inferred"` // True when any step uses an inferred relationship
+}
@@ -0,0 +1,28 @@
+package models
+
+// Types of saved query parameters, named like their JSON Schema types
+const (
+	ParameterString  = "string"
+	ParameterInteger = "integer"
+	ParameterNumber  = "number"
+	ParameterBoolean = "boolean"
+)
+
+// SavedQuery is a vetted query exposed to clients as a tool of its own, so
+// they call it by name with typed arguments instead of writing SQL. The SQL
+// refers to the parameters as :name.
+type SavedQuery struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	SQL         string           `json:"sql"`
+	Parameters  []QueryParameter `json:"parameters,omitempty"`
+}
+
+// QueryParameter is a typed parameter of a saved query
+type QueryParameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // One of the Parameter constants
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Default     any    `json:"default,omitempty"` // Bound when the argument is omitted, NULL without one
+}
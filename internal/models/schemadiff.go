@@ -0,0 +1,38 @@
+package models
+
+// SchemaObject is an entry of sqlite_master
+type SchemaObject struct {
+	Type      string `json:"type"` // table, index, trigger or view
+	Name      string `json:"name"`
+	TableName string `json:"table_name"`
+	SQL       string `json:"sql"`
+}
+
+type SchemaDiff struct {
+	Source         string         `json:"source"`
+	Target         string         `json:"target"`
+	Identical      bool           `json:"identical"`
+	AddedTables    []string       `json:"added_tables,omitempty"`
+	RemovedTables  []string       `json:"removed_tables,omitempty"`
+	ChangedTables  []TableDiff    `json:"changed_tables,omitempty"`
+	AddedObjects   []SchemaObject `json:"added_objects,omitempty"`   // Indexes, triggers and views only in the target
+	RemovedObjects []SchemaObject `json:"removed_objects,omitempty"` // Indexes, triggers and views only in the source
+	ChangedObjects []SchemaObject `json:"changed_objects,omitempty"` // Target definitions of objects that differ
+	Migration      []string       `json:"migration,omitempty"`       // Statements that turn the source schema into the target schema
+}
+
+type TableDiff struct {
+	Name               string         `json:"name"`
+	AddedColumns       []Column       `json:"added_columns,omitempty"`
+	RemovedColumns     []string       `json:"removed_columns,omitempty"`
+	ChangedColumns     []ColumnChange `json:"changed_columns,omitempty"`
+	ForeignKeysChanged bool           `json:"foreign_keys_changed,omitempty"`
+	DefinitionChanged  bool           `json:"definition_changed,omitempty"` // CREATE TABLE text differs, e.g. CHECK or UNIQUE constraints
+	RequiresRebuild    bool           `json:"requires_rebuild"`             // Change is not expressible with ALTER TABLE
+}
+
+type ColumnChange struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
@@ -2,9 +2,13 @@ package models
 
 type Table struct {
 	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Sensitivity string       `json:"sensitivity,omitempty"`
 	Columns     []Column     `json:"columns"`
 	Indexes     []string     `json:"indexes,omitempty"`
 	ForeignKeys []ForeignKey `json:"foreign_keys,omitempty"` // Add this
+	// InferredRelationships are likely references that are not declared as foreign keys
+	InferredRelationships []Relationship `json:"inferred_relationships,omitempty"`
 }
 
 type ForeignKey struct {
@@ -19,17 +23,25 @@ type ForeignKey struct {
 }
 
 type Column struct {
-	Name         string  `json:"name"`
-	Type         string  `json:"type"`
-	NotNull      bool    `json:"not_null"`
-	DefaultValue *string `json:"default_value,omitempty"`
-	PrimaryKey   bool    `json:"primary_key"`
+	Name            string  `json:"name"`
+	Type            string  `json:"type"`
+	NotNull         bool    `json:"not_null"`
+	DefaultValue    *string `json:"default_value,omitempty"`
+	PrimaryKey      bool    `json:"primary_key"`
+	PrimaryKeyIndex int     `json:"primary_key_index,omitempty"` // 1-based position within the primary key
+
+	// Annotations from the sidecar annotation store
+	Description string   `json:"description,omitempty"`
+	Unit        string   `json:"unit,omitempty"`
+	EnumValues  []string `json:"enum_values,omitempty"`
+	Sensitivity string   `json:"sensitivity,omitempty"`
 }
 
 type QueryResult struct {
-	Columns []string         `json:"columns"`
-	Rows    []map[string]any `json:"rows"`
-	Count   int              `json:"count"`
+	Columns   []string         `json:"columns"`
+	Rows      []map[string]any `json:"rows"`
+	Count     int              `json:"count"`
+	Truncated bool             `json:"truncated,omitempty"` // More rows matched than the row limit allows
 }
 
 type ExecuteResult struct {
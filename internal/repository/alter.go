@@ -0,0 +1,411 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+// AlterTable applies a structured change spec to a table. Column renames use
+// ALTER TABLE RENAME COLUMN, everything else rebuilds the table following
+// https://www.sqlite.org/lang_altertable.html#otheralter: with foreign keys
+// disabled and inside one transaction, views are dropped, the new definition is
+// created and filled from the old table, the old table is replaced, indexes,
+// triggers and views are recreated and PRAGMA foreign_key_check must pass
+// before committing. With DryRun the transaction is rolled back.
+func (s *SQLiteDB) AlterTable(spec models.AlterTableSpec) (*models.AlterTableResult, error) {
+	s.logger.Debugf("Altering table %s with %d operations", spec.Table, len(spec.Operations))
+
+	if len(spec.Operations) == 0 {
+		return nil, fmt.Errorf("no operations given")
+	}
+	if isInternalTable(spec.Table) {
+		return nil, fmt.Errorf("table %s does not exist", spec.Table)
+	}
+	exists, err := s.tableExists(spec.Table)
+	if err != nil {
+		s.logger.Errorf("Failed to check table existence: %v", err)
+		return nil, fmt.Errorf("failed to alter table %s", spec.Table)
+	}
+	if !exists {
+		return nil, fmt.Errorf("table %s does not exist", spec.Table)
+	}
+
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to get connection: %v", err)
+		return nil, fmt.Errorf("failed to alter table %s", spec.Table)
+	}
+	defer conn.Close()
+
+	// PRAGMA foreign_keys is a no-op inside a transaction, so it is switched
+	// off on the pinned connection first and restored afterwards
+	var foreignKeys bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		s.logger.Errorf("Failed to read foreign_keys pragma: %v", err)
+		return nil, fmt.Errorf("failed to alter table %s", spec.Table)
+	}
+	if foreignKeys {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys=OFF"); err != nil {
+			s.logger.Errorf("Failed to disable foreign keys: %v", err)
+			return nil, fmt.Errorf("failed to alter table %s", spec.Table)
+		}
+		defer func() {
+			if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys=ON"); err != nil {
+				s.logger.Errorf("Failed to re-enable foreign keys: %v", err)
+			}
+		}()
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Errorf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to alter table %s", spec.Table)
+	}
+	defer tx.Rollback()
+
+	result, err := alterTable(ctx, tx, spec)
+	if err != nil {
+		s.logger.Errorf("Failed to alter table %s: %v", spec.Table, err)
+		return nil, err
+	}
+
+	if spec.DryRun {
+		s.logger.Infof("Dry run of altering table %s, %d statements", spec.Table, len(result.Statements))
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Errorf("Failed to commit: %v", err)
+		return nil, fmt.Errorf("failed to alter table %s", spec.Table)
+	}
+
+	s.logger.Infof("Altered table %s, %d statements", spec.Table, len(result.Statements))
+	return result, nil
+}
+
+func alterTable(ctx context.Context, tx *sql.Tx, spec models.AlterTableSpec) (*models.AlterTableResult, error) {
+	result := &models.AlterTableResult{Table: spec.Table, DryRun: spec.DryRun}
+	exec := func(statement string) error {
+		result.Statements = append(result.Statements, statement)
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("%s: %v", sanitizeQuery(statement), err)
+		}
+		return nil
+	}
+
+	// Renames are native and also update indexes, triggers and views, so they
+	// run first and the rebuild works on the renamed columns
+	var rebuildOps []models.AlterOperation
+	for _, op := range spec.Operations {
+		if op.Op != models.AlterRenameColumn {
+			rebuildOps = append(rebuildOps, op)
+			continue
+		}
+		if op.Column == "" || op.NewName == "" {
+			return nil, fmt.Errorf("rename_column requires column and new_name")
+		}
+		if err := exec(fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", quoteIdent(spec.Table), quoteIdent(op.Column), quoteIdent(op.NewName))); err != nil {
+			return nil, err
+		}
+	}
+
+	var createSQL string
+	if err := tx.QueryRowContext(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", spec.Table).Scan(&createSQL); err != nil {
+		return nil, fmt.Errorf("failed to read definition of table %s", spec.Table)
+	}
+	result.CreateSQL = createSQL
+	if len(rebuildOps) == 0 {
+		return result, nil
+	}
+
+	def, err := parseTableDefinition(createSQL)
+	if err != nil {
+		return nil, err
+	}
+	oldColumns := def.columnNames()
+
+	sources := make(map[string]string)
+	var dropped []string
+	for _, op := range rebuildOps {
+		if err := applyAlterOperation(def, op, sources); err != nil {
+			return nil, err
+		}
+		if op.Op == models.AlterDropColumn {
+			dropped = append(dropped, op.Column)
+		}
+	}
+	if len(def.columnNames()) == 0 {
+		return nil, fmt.Errorf("cannot drop all columns of table %s", spec.Table)
+	}
+
+	rebuild := tableRebuild{Table: spec.Table, CreateSQL: def.String()}
+	for _, col := range def.columnNames() {
+		if source, ok := sources[strings.ToLower(col)]; ok {
+			rebuild.Columns = append(rebuild.Columns, col)
+			rebuild.Sources = append(rebuild.Sources, source)
+		} else if containsFold(oldColumns, col) {
+			rebuild.Columns = append(rebuild.Columns, col)
+			rebuild.Sources = append(rebuild.Sources, quoteIdent(col))
+		}
+	}
+
+	objects, err := queryObjects(ctx, tx, spec.Table)
+	if err != nil {
+		return nil, err
+	}
+	// Triggers are recreated after the views, which they may use, rather than
+	// by the rebuild
+	var views, triggers []models.SchemaObject
+	for _, object := range objects {
+		switch object.Type {
+		case "index":
+			if columns, err := indexColumns(ctx, tx, object.Name); err != nil {
+				return nil, err
+			} else if intersectsFold(columns, dropped) {
+				result.DroppedIndexes = append(result.DroppedIndexes, object.Name)
+				continue
+			}
+			rebuild.Indexes = append(rebuild.Indexes, object.SQL)
+		case "trigger":
+			triggers = append(triggers, object)
+		case "view":
+			views = append(views, object)
+		}
+	}
+
+	statements, err := rebuild.statements()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, view := range views {
+		if err := exec("DROP VIEW " + quoteIdent(view.Name)); err != nil {
+			return nil, err
+		}
+	}
+	for _, statement := range statements {
+		if err := exec(statement); err != nil {
+			return nil, err
+		}
+	}
+	for _, view := range views {
+		if err := exec(view.SQL); err != nil {
+			return nil, err
+		}
+	}
+	// SQLite does not resolve the columns of views and triggers when they are
+	// created, so ones using a dropped column only fail when next used
+	for _, view := range views {
+		if err := checkStatement(ctx, tx, "SELECT * FROM "+quoteIdent(view.Name)+" LIMIT 0"); err != nil {
+			return nil, fmt.Errorf("view %s no longer works after the change: %v", view.Name, err)
+		}
+	}
+	for _, trigger := range triggers {
+		if err := exec(trigger.SQL); err != nil {
+			return nil, err
+		}
+		if err := checkStatement(ctx, tx, triggerEventStatement(trigger.SQL, spec.Table, def.columnNames())); err != nil {
+			return nil, fmt.Errorf("trigger %s no longer works after the change: %v", trigger.Name, err)
+		}
+	}
+
+	if err := foreignKeyCheck(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	result.CreateSQL = def.String()
+	return result, nil
+}
+
+// applyAlterOperation changes the table definition for one operation and
+// records the expressions filling changed columns in sources
+func applyAlterOperation(def *tableDefinition, op models.AlterOperation, sources map[string]string) error {
+	needsColumn := op.Op != models.AlterAddConstraint && op.Op != models.AlterDropConstraint
+	if needsColumn && op.Column == "" {
+		return fmt.Errorf("%s requires a column", op.Op)
+	}
+	index := def.column(op.Column)
+	if needsColumn && op.Op != models.AlterAddColumn && index < 0 {
+		return fmt.Errorf("column %s does not exist", op.Column)
+	}
+	if op.Using != "" && needsColumn {
+		sources[strings.ToLower(op.Column)] = "(" + op.Using + ")"
+	}
+
+	switch op.Op {
+	case models.AlterAddColumn:
+		if index >= 0 {
+			return fmt.Errorf("column %s already exists", op.Column)
+		}
+		def.insertColumn(joinNonEmpty(quoteIdent(op.Column), op.Type, op.Definition))
+
+	case models.AlterDropColumn:
+		for _, other := range def.defs {
+			if _, _, ok := splitColumnDefinition(other); !ok && referencesIdent(other, op.Column) {
+				return fmt.Errorf("column %s is used by constraint %q, drop the constraint first", op.Column, other)
+			}
+		}
+		def.defs = append(def.defs[:index], def.defs[index+1:]...)
+
+	case models.AlterRetypeColumn:
+		if op.Type == "" {
+			return fmt.Errorf("retype_column requires a type")
+		}
+		name, _ := readToken(def.defs[index])
+		_, rest, _ := splitColumnDefinition(def.defs[index])
+		_, constraints := splitColumnType(rest)
+		def.defs[index] = joinNonEmpty(name, op.Type, constraints)
+
+	case models.AlterModifyColumn:
+		if op.Type == "" && op.Definition == "" {
+			return fmt.Errorf("modify_column requires a type or definition")
+		}
+		name, _ := readToken(def.defs[index])
+		def.defs[index] = joinNonEmpty(name, op.Type, op.Definition)
+
+	case models.AlterAddConstraint:
+		keyword, _ := readToken(op.Definition)
+		if !tableConstraintKeywords[strings.ToUpper(keyword)] {
+			return fmt.Errorf("add_constraint requires a table constraint such as UNIQUE (a, b) or CHECK (...)")
+		}
+		def.defs = append(def.defs, strings.TrimSpace(op.Definition))
+
+	case models.AlterDropConstraint:
+		for i, other := range def.defs {
+			if _, _, ok := splitColumnDefinition(other); ok {
+				continue
+			}
+			if strings.EqualFold(constraintName(other), op.Name) || normalizeSQL(other) == normalizeSQL(op.Name) {
+				def.defs = append(def.defs[:i], def.defs[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("constraint %s not found, column constraints are changed with modify_column", op.Name)
+
+	case models.AlterRenameColumn:
+		// Applied natively before the rebuild
+	default:
+		return fmt.Errorf("unknown operation %q", op.Op)
+	}
+
+	return nil
+}
+
+// queryObjects returns the indexes and triggers of a table and all views
+func queryObjects(ctx context.Context, tx *sql.Tx, table string) ([]models.SchemaObject, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT type, name, tbl_name, sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND ((type IN ('index', 'trigger') AND tbl_name = ?) OR type = 'view')
+		ORDER BY type, name`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema objects: %v", err)
+	}
+	defer rows.Close()
+
+	var objects []models.SchemaObject
+	for rows.Next() {
+		var object models.SchemaObject
+		if err := rows.Scan(&object.Type, &object.Name, &object.TableName, &object.SQL); err != nil {
+			return nil, fmt.Errorf("failed to read schema objects: %v", err)
+		}
+		objects = append(objects, object)
+	}
+	return objects, rows.Err()
+}
+
+// triggerEventPattern finds the event of a CREATE TRIGGER statement
+var triggerEventPattern = regexp.MustCompile(`(?is)\b(DELETE|INSERT|UPDATE)\b(?:\s+OF\b.*?)?\s+ON\b`)
+
+// triggerEventStatement returns a statement on table that fires the trigger.
+// Compiling it compiles the trigger program, which resolves its columns.
+func triggerEventStatement(createSQL, table string, columns []string) string {
+	event := "UPDATE"
+	if m := triggerEventPattern.FindStringSubmatch(createSQL); m != nil {
+		event = strings.ToUpper(m[1])
+	}
+	switch event {
+	case "DELETE":
+		return "DELETE FROM " + quoteIdent(table)
+	case "INSERT":
+		return "INSERT INTO " + quoteIdent(table) + " DEFAULT VALUES"
+	}
+	assignments := make([]string, len(columns))
+	for i, col := range columns {
+		assignments[i] = quoteIdent(col) + " = " + quoteIdent(col)
+	}
+	return "UPDATE " + quoteIdent(table) + " SET " + strings.Join(assignments, ", ")
+}
+
+// checkStatement compiles a statement without running it
+func checkStatement(ctx context.Context, tx *sql.Tx, statement string) error {
+	rows, err := tx.QueryContext(ctx, "EXPLAIN "+statement)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+func indexColumns(ctx context.Context, tx *sql.Tx, index string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT name FROM pragma_index_info(?) WHERE name IS NOT NULL", index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index %s: %v", index, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to read index %s: %v", index, err)
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
+
+// foreignKeyCheck fails when any foreign key constraint is violated
+func foreignKeyCheck(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return fmt.Errorf("foreign key check failed: %v", err)
+	}
+	defer rows.Close()
+
+	var violations []string
+	for rows.Next() {
+		var table, parent string
+		var rowid sql.NullInt64
+		var fkid int
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return fmt.Errorf("foreign key check failed: %v", err)
+		}
+		violations = append(violations, fmt.Sprintf("%s row %d references missing %s", table, rowid.Int64, parent))
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("foreign key violations: %s", strings.Join(violations, "; "))
+	}
+	return rows.Err()
+}
+
+func intersectsFold(a, b []string) bool {
+	for _, value := range a {
+		if containsFold(b, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinNonEmpty(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func setupAlterDB(t *testing.T) *SQLiteDB {
+	t.Helper()
+
+	return newTestDBWithSchema(t, "alter.db",
+		"PRAGMA foreign_keys=ON",
+		`CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT)`,
+		`CREATE TABLE orders (
+			id INTEGER PRIMARY KEY,
+			customer_id INTEGER REFERENCES customers(id),
+			price TEXT, -- stored as text by mistake
+			note VARCHAR(10) NOT NULL DEFAULT '',
+			legacy TEXT,
+			CONSTRAINT positive_price CHECK (price > 0)
+		)`,
+		`CREATE INDEX idx_orders_customer ON orders(customer_id)`,
+		`CREATE INDEX idx_orders_legacy ON orders(legacy)`,
+		`CREATE VIEW order_totals AS SELECT customer_id, SUM(price) AS total FROM orders GROUP BY customer_id`,
+		`CREATE TRIGGER orders_note AFTER INSERT ON orders BEGIN UPDATE orders SET note = 'new' WHERE id = NEW.id AND note = ''; END`,
+		`INSERT INTO customers VALUES (1, 'John')`,
+		`INSERT INTO orders (id, customer_id, price, legacy) VALUES (1, 1, '12.50', 'x'), (2, 1, '3', 'y')`,
+	)
+}
+
+func TestAlterTable(t *testing.T) {
+	db := setupAlterDB(t)
+
+	result, err := db.AlterTable(models.AlterTableSpec{
+		Table: "orders",
+		Operations: []models.AlterOperation{
+			{Op: models.AlterRetypeColumn, Column: "price", Type: "INTEGER", Using: "CAST(price * 100 AS INTEGER)"},
+			{Op: models.AlterDropConstraint, Name: "positive_price"},
+			{Op: models.AlterDropColumn, Column: "legacy"},
+			{Op: models.AlterRenameColumn, Column: "note", NewName: "comment"},
+			{Op: models.AlterModifyColumn, Column: "comment", Type: "TEXT"},
+			{Op: models.AlterAddColumn, Column: "status", Type: "TEXT", Definition: "NOT NULL DEFAULT 'open'"},
+			{Op: models.AlterAddConstraint, Definition: "UNIQUE (customer_id, price)"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AlterTable failed: %v", err)
+	}
+
+	if len(result.DroppedIndexes) != 1 || result.DroppedIndexes[0] != "idx_orders_legacy" {
+		t.Errorf("Expected idx_orders_legacy to be dropped, got %v", result.DroppedIndexes)
+	}
+	for _, unexpected := range []string{"legacy", "positive_price"} {
+		if strings.Contains(result.CreateSQL, unexpected) {
+			t.Errorf("Expected %s to be removed from %s", unexpected, result.CreateSQL)
+		}
+	}
+
+	rows, err := db.Query("SELECT id, price, typeof(price) AS type, comment, status FROM orders ORDER BY id")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if rows.Count != 2 || rows.Rows[0]["price"] != int64(1250) || rows.Rows[0]["type"] != "integer" || rows.Rows[0]["status"] != "open" {
+		t.Errorf("Unexpected rows after rebuild: %v", rows.Rows)
+	}
+
+	objects, err := db.GetSchemaObjects()
+	if err != nil {
+		t.Fatalf("GetSchemaObjects failed: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, object := range objects {
+		names[object.Name] = true
+	}
+	for _, expected := range []string{"idx_orders_customer", "order_totals", "orders_note"} {
+		if !names[expected] {
+			t.Errorf("Expected %s to be recreated", expected)
+		}
+	}
+
+	if _, err := db.Execute("INSERT INTO orders (customer_id, price) VALUES (1, 1250)"); err == nil {
+		t.Error("Expected the new UNIQUE constraint to be enforced")
+	}
+}
+
+func TestAlterTable_RollsBackOnError(t *testing.T) {
+	db := setupAlterDB(t)
+
+	before, err := db.GetSchemaObjects()
+	if err != nil {
+		t.Fatalf("GetSchemaObjects failed: %v", err)
+	}
+
+	_, err = db.AlterTable(models.AlterTableSpec{
+		Table: "orders",
+		Operations: []models.AlterOperation{
+			{Op: models.AlterRenameColumn, Column: "legacy", NewName: "old"},
+			{Op: models.AlterAddColumn, Column: "required", Type: "TEXT", Definition: "NOT NULL", Using: "NULL"},
+		},
+	})
+	if err == nil {
+		t.Fatal("Expected NOT NULL violation")
+	}
+
+	after, err := db.GetSchemaObjects()
+	if err != nil {
+		t.Fatalf("GetSchemaObjects failed: %v", err)
+	}
+	if len(before) != len(after) {
+		t.Fatalf("Expected schema to be unchanged")
+	}
+	for i := range before {
+		if before[i].SQL != after[i].SQL {
+			t.Errorf("Expected %s to be unchanged, got %s", before[i].SQL, after[i].SQL)
+		}
+	}
+}
+
+func TestAlterTable_BrokenDependents(t *testing.T) {
+	tests := []struct {
+		name      string
+		dependent string
+	}{
+		{"legacy_orders", "CREATE VIEW legacy_orders AS SELECT id, legacy FROM orders"},
+		{"orders_legacy", "CREATE TRIGGER orders_legacy BEFORE UPDATE OF price ON orders BEGIN SELECT RAISE(ABORT, 'legacy') WHERE OLD.legacy IS NULL; END"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupAlterDB(t)
+			if _, err := db.db.Exec(tt.dependent); err != nil {
+				t.Fatalf("Failed to create %s: %v", tt.name, err)
+			}
+
+			_, err := db.AlterTable(models.AlterTableSpec{
+				Table:      "orders",
+				Operations: []models.AlterOperation{{Op: models.AlterDropColumn, Column: "legacy"}},
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.name) {
+				t.Fatalf("Expected an error naming %s, got %v", tt.name, err)
+			}
+			if _, err := db.Query("SELECT legacy FROM orders"); err != nil {
+				t.Errorf("Expected the column to be kept, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAlterTable_Validation(t *testing.T) {
+	db := setupAlterDB(t)
+
+	cases := map[string]models.AlterOperation{
+		"unknown column":       {Op: models.AlterDropColumn, Column: "missing"},
+		"existing column":      {Op: models.AlterAddColumn, Column: "price", Type: "TEXT"},
+		"column in constraint": {Op: models.AlterDropColumn, Column: "price"},
+		"missing constraint":   {Op: models.AlterDropConstraint, Name: "nope"},
+		"column constraint":    {Op: models.AlterAddConstraint, Definition: "NOT NULL"},
+		"unknown operation":    {Op: "truncate"},
+	}
+	for name, op := range cases {
+		if _, err := db.AlterTable(models.AlterTableSpec{Table: "orders", Operations: []models.AlterOperation{op}}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := db.AlterTable(models.AlterTableSpec{Table: "missing", Operations: []models.AlterOperation{{Op: models.AlterDropColumn, Column: "x"}}}); err == nil {
+		t.Error("Expected error for missing table")
+	}
+}
+
+func TestAlterTable_DryRun(t *testing.T) {
+	db := setupAlterDB(t)
+
+	result, err := db.AlterTable(models.AlterTableSpec{
+		Table:      "orders",
+		Operations: []models.AlterOperation{{Op: models.AlterDropColumn, Column: "legacy"}},
+		DryRun:     true,
+	})
+	if err != nil {
+		t.Fatalf("AlterTable failed: %v", err)
+	}
+	if !result.DryRun || len(result.Statements) == 0 {
+		t.Errorf("Expected dry run statements, got %+v", result)
+	}
+
+	tables, err := db.GetSchema()
+	if err != nil {
+		t.Fatalf("GetSchema failed: %v", err)
+	}
+	orders, _ := findTable(tables, "orders")
+	if !hasColumn(&orders, "legacy") {
+		t.Error("Expected dry run to leave the table unchanged")
+	}
+}
+
+func TestParseTableDefinition(t *testing.T) {
+	def, err := parseTableDefinition(`CREATE TABLE "t" (a DECIMAL(10, 2) NOT NULL, "b c" TEXT DEFAULT 'x,y', [d], CONSTRAINT u UNIQUE (a, "b c")) WITHOUT ROWID`)
+	if err != nil {
+		t.Fatalf("parseTableDefinition failed: %v", err)
+	}
+
+	if got := strings.Join(def.columnNames(), "|"); got != "a|b c|d" {
+		t.Errorf("Unexpected columns: %s", got)
+	}
+	if len(def.defs) != 4 || def.suffix != ") WITHOUT ROWID" {
+		t.Errorf("Unexpected definition: %#v", def)
+	}
+
+	typeName, constraints := splitColumnType("DECIMAL(10, 2) NOT NULL")
+	if typeName != "DECIMAL(10, 2)" || constraints != "NOT NULL" {
+		t.Errorf("Unexpected split: %q / %q", typeName, constraints)
+	}
+	if constraintName(def.defs[3]) != "u" || !referencesIdent(def.defs[3], "b c") {
+		t.Errorf("Unexpected constraint parsing of %s", def.defs[3])
+	}
+
+	if _, err := parseTableDefinition("CREATE TABLE t AS SELECT 1"); err == nil {
+		t.Error("Expected error for CREATE TABLE AS SELECT")
+	}
+}
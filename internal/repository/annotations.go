@@ -0,0 +1,414 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// annotationsTable is the sidecar table used when no annotation file is configured.
+// Tables prefixed with _mcp_ are internal and hidden from GetSchema.
+const annotationsTable = "_mcp_annotations"
+
+// AnnotationStore persists table and column annotations
+type AnnotationStore interface {
+	List() ([]models.Annotation, error)
+	Set(annotation models.Annotation) error
+	Delete(table, column string) error
+}
+
+var (
+	_ AnnotationStore = (*tableAnnotationStore)(nil)
+	_ AnnotationStore = (*fileAnnotationStore)(nil)
+)
+
+// SetAnnotationStore replaces the default _mcp_annotations table store
+func (s *SQLiteDB) SetAnnotationStore(store AnnotationStore) {
+	s.annotations = store
+}
+
+// ListAnnotations returns all stored annotations
+func (s *SQLiteDB) ListAnnotations() ([]models.Annotation, error) {
+	annotations, err := s.annotations.List()
+	if err != nil {
+		s.logger.Errorf("Failed to list annotations: %v", err)
+		return nil, fmt.Errorf("failed to retrieve annotations")
+	}
+	return annotations, nil
+}
+
+// Annotate merges the non-empty fields of update into the stored annotation
+// for its table or column and returns the result
+func (s *SQLiteDB) Annotate(update models.Annotation) (*models.Annotation, error) {
+	s.logger.Debugf("Annotating %s.%s", update.Table, update.Column)
+
+	if err := s.validateAnnotationTarget(update.Table, update.Column); err != nil {
+		return nil, err
+	}
+
+	annotations, err := s.ListAnnotations()
+	if err != nil {
+		return nil, err
+	}
+
+	merged := models.Annotation{Table: update.Table, Column: update.Column}
+	for _, existing := range annotations {
+		if strings.EqualFold(existing.Table, update.Table) && strings.EqualFold(existing.Column, update.Column) {
+			merged = existing
+			break
+		}
+	}
+	if update.Description != "" {
+		merged.Description = update.Description
+	}
+	if update.Unit != "" {
+		merged.Unit = update.Unit
+	}
+	if update.EnumValues != nil {
+		merged.EnumValues = update.EnumValues
+	}
+	if update.Sensitivity != "" {
+		merged.Sensitivity = update.Sensitivity
+	}
+
+	if err := s.annotations.Set(merged); err != nil {
+		s.logger.Errorf("Failed to store annotation for %s.%s: %v", update.Table, update.Column, err)
+		return nil, fmt.Errorf("failed to store annotation")
+	}
+
+	s.logger.Infof("Stored annotation for table %s, column: %q", merged.Table, merged.Column)
+	return &merged, nil
+}
+
+// RemoveAnnotation deletes the annotation of a table or column
+func (s *SQLiteDB) RemoveAnnotation(table, column string) error {
+	if err := s.annotations.Delete(table, column); err != nil {
+		s.logger.Errorf("Failed to remove annotation for %s.%s: %v", table, column, err)
+		return fmt.Errorf("failed to remove annotation")
+	}
+	s.logger.Infof("Removed annotation for table %s, column: %q", table, column)
+	return nil
+}
+
+func (s *SQLiteDB) validateAnnotationTarget(table, column string) error {
+	exists, err := s.tableExists(table)
+	if err != nil {
+		s.logger.Errorf("Failed to look up table %s: %v", table, err)
+		return fmt.Errorf("failed to retrieve table information")
+	}
+	if !exists || isInternalTable(table) {
+		return fmt.Errorf("table %s does not exist", table)
+	}
+	if column == "" {
+		return nil
+	}
+
+	tableInfo, err := s.getTableInfo(table)
+	if err != nil {
+		s.logger.Errorf("Failed to get table info for table %s: %v", table, err)
+		return fmt.Errorf("failed to retrieve table information")
+	}
+	if !hasColumn(tableInfo, column) {
+		return fmt.Errorf("column %s does not exist in table %s", column, table)
+	}
+	return nil
+}
+
+// applyAnnotations merges stored annotations into tables and their columns
+func applyAnnotations(tables []models.Table, annotations []models.Annotation) {
+	for _, a := range annotations {
+		for i := range tables {
+			if !strings.EqualFold(tables[i].Name, a.Table) {
+				continue
+			}
+			if a.Column == "" {
+				tables[i].Description = a.Description
+				tables[i].Sensitivity = a.Sensitivity
+				continue
+			}
+			for j := range tables[i].Columns {
+				col := &tables[i].Columns[j]
+				if strings.EqualFold(col.Name, a.Column) {
+					col.Description = a.Description
+					col.Unit = a.Unit
+					col.EnumValues = a.EnumValues
+					col.Sensitivity = a.Sensitivity
+				}
+			}
+		}
+	}
+}
+
+func isInternalTable(name string) bool {
+	return strings.HasPrefix(strings.ToLower(name), "_mcp_")
+}
+
+// tableAnnotationStore keeps annotations in the _mcp_annotations table of the
+// database itself. The table is only created on the first write.
+type tableAnnotationStore struct {
+	db     *sql.DB // Writer
+	reader *sql.DB
+}
+
+func (t *tableAnnotationStore) exists() (bool, error) {
+	var count int
+	err := t.reader.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name = ?", annotationsTable).Scan(&count)
+	return count > 0, err
+}
+
+func (t *tableAnnotationStore) List() ([]models.Annotation, error) {
+	exists, err := t.exists()
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	rows, err := t.reader.Query("SELECT table_name, column_name, description, unit, enum_values, sensitivity FROM " +
+		annotationsTable + " ORDER BY table_name, column_name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var annotations []models.Annotation
+	for rows.Next() {
+		var a models.Annotation
+		var enumValues sql.NullString
+		if err := rows.Scan(&a.Table, &a.Column, &a.Description, &a.Unit, &enumValues, &a.Sensitivity); err != nil {
+			return nil, err
+		}
+		if enumValues.Valid && enumValues.String != "" {
+			if err := json.Unmarshal([]byte(enumValues.String), &a.EnumValues); err != nil {
+				return nil, fmt.Errorf("invalid enum_values for %s.%s: %w", a.Table, a.Column, err)
+			}
+		}
+		annotations = append(annotations, a)
+	}
+
+	return annotations, rows.Err()
+}
+
+func (t *tableAnnotationStore) Set(a models.Annotation) error {
+	_, err := t.db.Exec(`CREATE TABLE IF NOT EXISTS ` + annotationsTable + ` (
+		table_name TEXT NOT NULL,
+		column_name TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		unit TEXT NOT NULL DEFAULT '',
+		enum_values TEXT,
+		sensitivity TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (table_name, column_name)
+	)`)
+	if err != nil {
+		return err
+	}
+
+	var enumValues sql.NullString
+	if len(a.EnumValues) > 0 {
+		encoded, err := json.Marshal(a.EnumValues)
+		if err != nil {
+			return err
+		}
+		enumValues = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	_, err = t.db.Exec(`INSERT OR REPLACE INTO `+annotationsTable+`
+		(table_name, column_name, description, unit, enum_values, sensitivity) VALUES (?, ?, ?, ?, ?, ?)`,
+		a.Table, a.Column, a.Description, a.Unit, enumValues, a.Sensitivity)
+	return err
+}
+
+func (t *tableAnnotationStore) Delete(table, column string) error {
+	exists, err := t.exists()
+	if err != nil || !exists {
+		return err
+	}
+	_, err = t.db.Exec("DELETE FROM "+annotationsTable+" WHERE table_name = ? AND column_name = ?", table, column)
+	return err
+}
+
+// fileAnnotationStore keeps annotations in a YAML file of the form
+//
+//	tables:
+//	  orders:
+//	    description: Customer orders
+//	    columns:
+//	      st:
+//	        description: Order status
+//	        enum_values: [pending, shipped, cancelled]
+type fileAnnotationStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+type annotationFile struct {
+	Tables map[string]*tableAnnotationEntry `yaml:"tables"`
+}
+
+type tableAnnotationEntry struct {
+	annotationEntry `yaml:",inline"`
+	Columns         map[string]*annotationEntry `yaml:"columns,omitempty"`
+}
+
+type annotationEntry struct {
+	Description string   `yaml:"description,omitempty"`
+	Unit        string   `yaml:"unit,omitempty"`
+	EnumValues  []string `yaml:"enum_values,omitempty"`
+	Sensitivity string   `yaml:"sensitivity,omitempty"`
+}
+
+// NewFileAnnotationStore returns a store backed by a YAML file, which is
+// created on the first write if it does not exist
+func NewFileAnnotationStore(path string) AnnotationStore {
+	return &fileAnnotationStore{path: path}
+}
+
+func (f *fileAnnotationStore) List() ([]models.Annotation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var annotations []models.Annotation
+	for _, table := range sortedKeys(file.Tables) {
+		entry := file.Tables[table]
+		if entry == nil {
+			continue
+		}
+		if !entry.annotationEntry.isEmpty() {
+			annotations = append(annotations, entry.toAnnotation(table, ""))
+		}
+		for _, column := range sortedKeys(entry.Columns) {
+			if col := entry.Columns[column]; col != nil {
+				annotations = append(annotations, col.toAnnotation(table, column))
+			}
+		}
+	}
+	return annotations, nil
+}
+
+func (f *fileAnnotationStore) Set(a models.Annotation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	table := file.Tables[a.Table]
+	if table == nil {
+		table = &tableAnnotationEntry{}
+		file.Tables[a.Table] = table
+	}
+
+	entry := annotationEntry{
+		Description: a.Description,
+		Unit:        a.Unit,
+		EnumValues:  a.EnumValues,
+		Sensitivity: a.Sensitivity,
+	}
+	if a.Column == "" {
+		table.annotationEntry = entry
+	} else {
+		if table.Columns == nil {
+			table.Columns = make(map[string]*annotationEntry)
+		}
+		table.Columns[a.Column] = &entry
+	}
+
+	return f.save(file)
+}
+
+func (f *fileAnnotationStore) Delete(table, column string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	entry := file.Tables[table]
+	if entry == nil {
+		return nil
+	}
+	if column == "" {
+		entry.annotationEntry = annotationEntry{}
+	} else {
+		delete(entry.Columns, column)
+	}
+	if entry.annotationEntry.isEmpty() && len(entry.Columns) == 0 {
+		delete(file.Tables, table)
+	}
+
+	return f.save(file)
+}
+
+func (f *fileAnnotationStore) load() (*annotationFile, error) {
+	file := &annotationFile{}
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		file.Tables = make(map[string]*tableAnnotationEntry)
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("invalid annotation file %s: %w", f.path, err)
+	}
+	if file.Tables == nil {
+		file.Tables = make(map[string]*tableAnnotationEntry)
+	}
+	return file, nil
+}
+
+func (f *fileAnnotationStore) save(file *annotationFile) error {
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated file behind
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
+
+func (e annotationEntry) isEmpty() bool {
+	return e.Description == "" && e.Unit == "" && len(e.EnumValues) == 0 && e.Sensitivity == ""
+}
+
+func (e annotationEntry) toAnnotation(table, column string) models.Annotation {
+	return models.Annotation{
+		Table:       table,
+		Column:      column,
+		Description: e.Description,
+		Unit:        e.Unit,
+		EnumValues:  e.EnumValues,
+		Sensitivity: e.Sensitivity,
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
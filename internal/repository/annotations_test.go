@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func findColumn(t *testing.T, tables []models.Table, table, column string) models.Column {
+	t.Helper()
+	for _, tbl := range tables {
+		if tbl.Name != table {
+			continue
+		}
+		for _, col := range tbl.Columns {
+			if col.Name == column {
+				return col
+			}
+		}
+	}
+	t.Fatalf("Column %s.%s not found", table, column)
+	return models.Column{}
+}
+
+func TestAnnotate_TableStore(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := db.Annotate(models.Annotation{Table: "test_users", Column: "email", Description: "Login email", Sensitivity: "pii"})
+	if err != nil {
+		t.Fatalf("Annotate failed: %v", err)
+	}
+
+	// A later update only changes the provided fields
+	annotation, err := db.Annotate(models.Annotation{Table: "test_users", Column: "email", EnumValues: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("Annotate failed: %v", err)
+	}
+	if annotation.Description != "Login email" || annotation.Sensitivity != "pii" || len(annotation.EnumValues) != 2 {
+		t.Errorf("Expected merged annotation, got %+v", annotation)
+	}
+
+	if _, err := db.Annotate(models.Annotation{Table: "test_users", Description: "Registered users"}); err != nil {
+		t.Fatalf("Annotate failed: %v", err)
+	}
+
+	tables, err := db.GetSchema()
+	if err != nil {
+		t.Fatalf("GetSchema failed: %v", err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("Expected the annotation table to be hidden, got %d tables", len(tables))
+	}
+	if tables[0].Description != "Registered users" {
+		t.Errorf("Expected table description, got %q", tables[0].Description)
+	}
+
+	email := findColumn(t, tables, "test_users", "email")
+	if email.Description != "Login email" || email.Sensitivity != "pii" {
+		t.Errorf("Expected column annotation to be merged, got %+v", email)
+	}
+
+	if err := db.RemoveAnnotation("test_users", "email"); err != nil {
+		t.Fatalf("RemoveAnnotation failed: %v", err)
+	}
+	tables, _ = db.GetSchema()
+	if email := findColumn(t, tables, "test_users", "email"); email.Description != "" {
+		t.Errorf("Expected annotation to be removed, got %+v", email)
+	}
+}
+
+func TestAnnotate_InvalidTarget(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.Annotate(models.Annotation{Table: "missing", Description: "x"}); err == nil {
+		t.Error("Expected error for missing table")
+	}
+	if _, err := db.Annotate(models.Annotation{Table: "test_users", Column: "missing", Description: "x"}); err == nil {
+		t.Error("Expected error for missing column")
+	}
+}
+
+func TestAnnotate_FileStore(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "annotations.yaml")
+	err := os.WriteFile(path, []byte(`
+tables:
+  test_users:
+    description: Registered users
+    columns:
+      name:
+        description: Display name
+`), 0o644)
+	if err != nil {
+		t.Fatalf("Failed to write annotation file: %v", err)
+	}
+	db.SetAnnotationStore(NewFileAnnotationStore(path))
+
+	if _, err := db.Annotate(models.Annotation{Table: "test_users", Column: "email", Unit: "address"}); err != nil {
+		t.Fatalf("Annotate failed: %v", err)
+	}
+
+	tables, err := db.GetSchema()
+	if err != nil {
+		t.Fatalf("GetSchema failed: %v", err)
+	}
+	if tables[0].Description != "Registered users" {
+		t.Errorf("Expected table description from file, got %q", tables[0].Description)
+	}
+	if name := findColumn(t, tables, "test_users", "name"); name.Description != "Display name" {
+		t.Errorf("Expected column description from file, got %q", name.Description)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read annotation file: %v", err)
+	}
+	if !strings.Contains(string(data), "unit: address") {
+		t.Errorf("Expected new annotation to be written to the file, got:\n%s", data)
+	}
+
+	// The database itself is left untouched
+	if exists, _ := db.tableExists(annotationsTable); exists {
+		t.Error("Expected no annotation table when using a file store")
+	}
+}
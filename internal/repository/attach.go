@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+var (
+	attachPattern = regexp.MustCompile(`(?i)^\s*(ATTACH|DETACH)\b`)
+	vacuumPattern = regexp.MustCompile(`(?i)^\s*VACUUM(\s+main)?\s*;?\s*$`)
+)
+
+// Attachments returns the secondary databases attached to every connection
+func (s *SQLiteDB) Attachments() []models.Attachment {
+	return s.attachments
+}
+
+// validateAttachments checks aliases are unique and usable and that the
+// attached files exist, attaching never creates a file
+func validateAttachments(attachments []models.Attachment) error {
+	seen := make(map[string]bool)
+	for _, attachment := range attachments {
+		alias := strings.ToLower(attachment.Alias)
+		switch {
+		case alias == "":
+			return fmt.Errorf("attached database %s has no alias", attachment.Path)
+		case alias == "main" || alias == "temp" || isInternalTable(alias):
+			return fmt.Errorf("%s cannot be used as the alias of an attached database", attachment.Alias)
+		case seen[alias]:
+			return fmt.Errorf("attached database alias %s is used twice", attachment.Alias)
+		}
+		if _, err := os.Stat(attachment.Path); err != nil {
+			return fmt.Errorf("attached database file %s does not exist", attachment.Path)
+		}
+		seen[alias] = true
+	}
+	return nil
+}
+
+// attachDatabases attaches the configured databases to a new connection,
+// before the authorizer that denies ATTACH is installed
+func attachDatabases(conn *sqlite3.SQLiteConn, attachments []models.Attachment) error {
+	for _, attachment := range attachments {
+		dsn := readOnlyDSN(attachment.Path)
+		if attachment.Writable {
+			dsn = fileDSN(attachment.Path, "rw")
+		}
+		if _, err := conn.Exec("ATTACH DATABASE ? AS "+quoteIdent(attachment.Alias), []driver.Value{dsn}); err != nil {
+			return fmt.Errorf("failed to attach %s as %s: %w", attachment.Path, attachment.Alias, err)
+		}
+	}
+	return nil
+}
+
+// withoutAuthorizer runs fn with the authorizer removed from a pinned
+// connection. It is only used for the repository's own ATTACH, DETACH and
+// VACUUM statements; VACUUM attaches a database and recreates the schema in
+// it, which a policy would otherwise deny.
+func (s *SQLiteDB) withoutAuthorizer(conn *sql.Conn, fn func() error) error {
+	if err := setAuthorizer(conn, nil); err != nil {
+		return err
+	}
+	defer func() {
+		if err := setAuthorizer(conn, s.access.authorize); err != nil {
+			s.logger.Errorf("Failed to restore the authorizer: %v", err)
+		}
+	}()
+	return fn()
+}
+
+func setAuthorizer(conn *sql.Conn, authorize authorizer) error {
+	return conn.Raw(func(driverConn any) error {
+		sqliteConn, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		sqliteConn.RegisterAuthorizer(authorize)
+		return nil
+	})
+}
+
+// attachDatabase attaches a file read-only to a pinned connection
+func (s *SQLiteDB) attachDatabase(ctx context.Context, conn *sql.Conn, path, schema string) error {
+	return s.withoutAuthorizer(conn, func() error {
+		_, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS "+schema, readOnlyDSN(path))
+		return err
+	})
+}
+
+// detachDatabase detaches a schema attached by attachDatabase
+func (s *SQLiteDB) detachDatabase(ctx context.Context, conn *sql.Conn, schema string) error {
+	return s.withoutAuthorizer(conn, func() error {
+		_, err := conn.ExecContext(ctx, "DETACH DATABASE "+schema)
+		return err
+	})
+}
+
+// vacuum runs VACUUM, which attaches a temporary database internally
+func (s *SQLiteDB) vacuum() (sql.Result, error) {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var result sql.Result
+	err = s.withoutAuthorizer(conn, func() error {
+		result, err = conn.ExecContext(ctx, "VACUUM")
+		return err
+	})
+	return result, err
+}
+
+// attachedTables describes the tables of an attached database, named
+// alias.table
+func (s *SQLiteDB) attachedTables(alias string) ([]models.Table, error) {
+	rows, err := s.reader.Query(fmt.Sprintf(`SELECT name FROM %s.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%%' AND name NOT LIKE '\_mcp\_%%' ESCAPE '\' ORDER BY name`, quoteIdent(alias)))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tables := make([]models.Table, 0, len(names))
+	for _, name := range names {
+		table, err := s.schemaTableInfo(alias, name)
+		if err != nil {
+			return nil, err
+		}
+		table.Name = alias + "." + name
+		tables = append(tables, *table)
+	}
+	return tables, nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func newAttachTestDBs(t *testing.T) (mainPath, crmPath string) {
+	t.Helper()
+
+	main := newTestDBWithSchema(t, "shop.db",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER, total REAL)",
+		"INSERT INTO orders (customer_id, total) VALUES (1, 10), (1, 5), (2, 7)")
+	crm := newTestDBWithSchema(t, "crm.db",
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE INDEX idx_customers_name ON customers (name)",
+		"INSERT INTO customers VALUES (1, 'Ada'), (2, 'Grace')")
+	mainPath, crmPath = main.Path(), crm.Path()
+	main.Close()
+	crm.Close()
+	return mainPath, crmPath
+}
+
+func TestAttach_JoinsAcrossDatabases(t *testing.T) {
+	mainPath, crmPath := newAttachTestDBs(t)
+	db := openWithOptions(t, mainPath, models.DatabaseOptions{Attach: []models.Attachment{{Alias: "crm", Path: crmPath}}})
+
+	result, err := db.Query("SELECT c.name, SUM(o.total) AS total FROM orders o JOIN crm.customers c ON c.id = o.customer_id GROUP BY c.name ORDER BY c.name")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if result.Count != 2 || result.Rows[0]["name"] != "Ada" || result.Rows[0]["total"] != 15.0 {
+		t.Errorf("Unexpected join result: %v", result.Rows)
+	}
+
+	// Every pooled connection has the database attached
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		conn, err := db.reader.Conn(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get connection: %v", err)
+		}
+		defer conn.Close()
+		var count int
+		if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM crm.customers").Scan(&count); err != nil || count != 2 {
+			t.Errorf("Connection %d: expected 2 customers, got %d (%v)", i, count, err)
+		}
+	}
+
+	// Attached read-only by default
+	if _, err := db.Execute("INSERT INTO crm.customers VALUES (3, 'Linus')"); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Expected the write to the attached database to be denied, got: %v", err)
+	}
+	if _, err := db.Execute("INSERT INTO orders (customer_id, total) VALUES (2, 1)"); err != nil {
+		t.Errorf("Expected the main database to stay writable, got: %v", err)
+	}
+}
+
+func TestAttach_Writable(t *testing.T) {
+	mainPath, crmPath := newAttachTestDBs(t)
+	db := openWithOptions(t, mainPath, models.DatabaseOptions{Attach: []models.Attachment{{Alias: "crm", Path: crmPath, Writable: true}}})
+
+	if _, err := db.Execute("INSERT INTO crm.customers VALUES (3, 'Linus')"); err != nil {
+		t.Fatalf("Expected the write to succeed, got: %v", err)
+	}
+	if n := countRows(t, db, "SELECT COUNT(*) FROM crm.customers"); n != 3 {
+		t.Errorf("Expected 3 customers, got %d", n)
+	}
+}
+
+func TestAttach_SchemaListsAttachedTables(t *testing.T) {
+	mainPath, crmPath := newAttachTestDBs(t)
+	db := openWithOptions(t, mainPath, models.DatabaseOptions{Attach: []models.Attachment{{Alias: "crm", Path: crmPath}}})
+
+	tables, err := db.GetSchema()
+	if err != nil {
+		t.Fatalf("GetSchema failed: %v", err)
+	}
+	if len(tables) != 2 || tables[0].Name != "orders" || tables[1].Name != "crm.customers" {
+		t.Fatalf("Expected orders and crm.customers, got %v", tables)
+	}
+	customers := tables[1]
+	if len(customers.Columns) != 2 || !customers.Columns[0].PrimaryKey || !customers.Columns[1].NotNull {
+		t.Errorf("Unexpected columns: %+v", customers.Columns)
+	}
+	if len(customers.Indexes) != 1 || customers.Indexes[0] != "idx_customers_name" {
+		t.Errorf("Unexpected indexes: %v", customers.Indexes)
+	}
+
+	// Comparing schemas only looks at the main database
+	diff, err := db.DiffSchemaWithFile(mainPath, false)
+	if err != nil {
+		t.Fatalf("DiffSchemaWithFile failed: %v", err)
+	}
+	if !diff.Identical {
+		t.Errorf("Expected no differences, got %+v", diff)
+	}
+}
+
+func TestAttach_DeniesAttachAndDetach(t *testing.T) {
+	mainPath, crmPath := newAttachTestDBs(t)
+	other := filepath.Join(t.TempDir(), "other.db")
+
+	for _, opts := range []models.DatabaseOptions{
+		{},
+		{Policy: models.PolicyNoDDL, Attach: []models.Attachment{{Alias: "crm", Path: crmPath}}},
+	} {
+		db := openWithOptions(t, mainPath, opts)
+		for _, statement := range []string{
+			"ATTACH DATABASE '" + other + "' AS other",
+			"ATTACH DATABASE '" + filepath.Dir(other) + "/' || 'other.db' AS other",
+			"DETACH DATABASE crm",
+		} {
+			if _, err := db.Execute(statement); !errors.Is(err, ErrNotAllowed) {
+				t.Errorf("policy %q: expected %q to be denied, got: %v", opts.Policy, statement, err)
+			}
+		}
+
+		// VACUUM attaches internally and keeps working, as do the features
+		// attaching other files themselves
+		if _, err := db.Execute("VACUUM"); err != nil {
+			t.Errorf("policy %q: expected VACUUM to work, got: %v", opts.Policy, err)
+		}
+		if _, err := db.DiffDataWithFile(mainPath, models.DataDiffOptions{}); err != nil {
+			t.Errorf("policy %q: expected the data diff to work, got: %v", opts.Policy, err)
+		}
+		db.SetBackupDir(t.TempDir(), 0)
+		if _, err := db.Backup(models.BackupOptions{Method: models.BackupVacuum}); err != nil {
+			t.Errorf("policy %q: expected the vacuum backup to work, got: %v", opts.Policy, err)
+		}
+		db.Close()
+	}
+}
+
+func TestAttach_InvalidAttachments(t *testing.T) {
+	mainPath, crmPath := newAttachTestDBs(t)
+
+	for _, attachments := range [][]models.Attachment{
+		{{Alias: "main", Path: crmPath}},
+		{{Alias: "_mcp_x", Path: crmPath}},
+		{{Alias: "crm", Path: crmPath}, {Alias: "CRM", Path: crmPath}},
+		{{Alias: "crm", Path: filepath.Join(t.TempDir(), "missing.db")}},
+	} {
+		if _, err := NewSQLiteDBWithOptions(mainPath, models.DatabaseOptions{Attach: attachments}, logger.NewTestLogger()); err == nil {
+			t.Errorf("Expected an error for %v", attachments)
+		}
+	}
+}
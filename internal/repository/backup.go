@@ -0,0 +1,405 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+const (
+	// backupStepPages is the number of pages copied per online backup step;
+	// other connections can write between steps
+	backupStepPages  = 1024
+	backupTimeFormat = "20060102T150405.000000Z"
+	// backupCreatedFormat has a fixed width so creation times sort as strings
+	backupCreatedFormat = "2006-01-02T15:04:05.000000Z"
+	backupMetaSuffix    = ".json"
+)
+
+var errNoBackupDir = errors.New("no backup directory is configured")
+
+// SetBackupDir sets the directory backups are written to and the number of
+// backups of this database kept there, 0 keeping all of them
+func (s *SQLiteDB) SetBackupDir(dir string, keep int) {
+	s.backupDir = dir
+	s.backupKeep = keep
+}
+
+// Backup copies the database into the backup directory, either with the online
+// backup API or as a compacted copy with VACUUM INTO. A JSON file beside the
+// copy records its source, method and SHA-256 checksum. Afterwards the oldest
+// backups of this database beyond the retention limit are removed.
+func (s *SQLiteDB) Backup(opts models.BackupOptions) (*models.BackupResult, error) {
+	s.logger.Debugf("Backing up database with method %s", opts.Method)
+
+	if s.backupDir == "" {
+		return nil, errNoBackupDir
+	}
+	backup, err := s.createBackup(opts.Method, opts.Label)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := opts.Keep
+	if keep <= 0 {
+		keep = s.backupKeep
+	}
+	pruned, err := s.pruneBackups(keep)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Backed up database to %s (%d bytes)", backup.Path, backup.Size)
+	return &models.BackupResult{Backup: *backup, Pruned: pruned}, nil
+}
+
+// ListBackups returns the backups of this database in the backup directory,
+// which databases may share, newest first
+func (s *SQLiteDB) ListBackups() ([]models.Backup, error) {
+	s.logger.Debug("Listing backups")
+
+	if s.backupDir == "" {
+		return nil, errNoBackupDir
+	}
+	all, err := s.readBackups()
+	if err != nil {
+		return nil, err
+	}
+	source := s.backupSource()
+	var backups []models.Backup
+	for _, backup := range all {
+		if backup.Source == source {
+			backups = append(backups, backup)
+		}
+	}
+
+	s.logger.Infof("Listed %d backups", len(backups))
+	return backups, nil
+}
+
+// Restore replaces the database contents with a backup from the backup
+// directory after verifying its checksum. The current state is backed up
+// first, so a restore can itself be undone.
+func (s *SQLiteDB) Restore(name string) (*models.RestoreResult, error) {
+	s.logger.Debugf("Restoring backup %s", name)
+
+	if s.backupDir == "" {
+		return nil, errNoBackupDir
+	}
+	if err := s.checkFullAccess(); err != nil {
+		return nil, err
+	}
+	if name == "" || filepath.Base(name) != name || strings.HasSuffix(name, backupMetaSuffix) {
+		return nil, fmt.Errorf("invalid backup name %s", name)
+	}
+
+	backups, err := s.readBackups()
+	if err != nil {
+		return nil, err
+	}
+	var backup *models.Backup
+	for i := range backups {
+		if backups[i].Name == name {
+			backup = &backups[i]
+		}
+	}
+	if backup == nil {
+		return nil, fmt.Errorf("backup %s does not exist", name)
+	}
+	if backup.Source != s.backupSource() {
+		return nil, fmt.Errorf("backup %s is a backup of %s, not of this database", name, backup.Source)
+	}
+
+	sum, err := fileChecksum(backup.Path)
+	if err != nil {
+		s.logger.Errorf("Failed to read backup %s: %v", backup.Path, err)
+		return nil, fmt.Errorf("failed to read backup %s", name)
+	}
+	if sum != backup.Checksum {
+		return nil, fmt.Errorf("backup %s failed checksum verification, the file changed since it was written", name)
+	}
+
+	safety, err := s.createBackup(models.BackupOnline, "pre_restore")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	src, err := sql.Open("sqlite3", readOnlyDSN(backup.Path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup %s", name)
+	}
+	defer src.Close()
+
+	if err := copyDatabase(ctx, s.db, src); err != nil {
+		s.logger.Errorf("Failed to restore %s: %v", backup.Path, err)
+		return nil, fmt.Errorf("failed to restore backup %s: %v", name, err)
+	}
+
+	s.logger.Infof("Restored database from %s", backup.Path)
+	return &models.RestoreResult{Restored: *backup, Safety: safety}, nil
+}
+
+// createBackup writes a backup and its metadata file
+func (s *SQLiteDB) createBackup(method, label string) (*models.Backup, error) {
+	if method == "" {
+		method = models.BackupOnline
+	}
+	if method != models.BackupOnline && method != models.BackupVacuum {
+		return nil, fmt.Errorf("invalid backup method %s, expected online or vacuum", method)
+	}
+	if err := os.MkdirAll(s.backupDir, 0o755); err != nil {
+		s.logger.Errorf("Failed to create backup directory: %v", err)
+		return nil, fmt.Errorf("failed to create backup directory %s", s.backupDir)
+	}
+
+	source := s.backupSource()
+	now := time.Now().UTC()
+	base := strings.TrimSuffix(filepath.Base(s.path), filepath.Ext(s.path))
+	if s.InMemory() {
+		base = "memory"
+	}
+	name := base + "-" + now.Format(backupTimeFormat)
+	if label != "" {
+		name += "-" + migrationSlug(label)
+	}
+	// Backups taken within the same microsecond get a counter
+	stem := name
+	name = stem + ".db"
+	for i := 2; fileExists(filepath.Join(s.backupDir, name)); i++ {
+		name = fmt.Sprintf("%s-%d.db", stem, i)
+	}
+	path := filepath.Join(s.backupDir, name)
+
+	ctx := context.Background()
+	switch method {
+	case models.BackupOnline:
+		dest, err := sql.Open("sqlite3", path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create backup %s", name)
+		}
+		err = copyDatabase(ctx, dest, s.reader)
+		dest.Close()
+		if err != nil {
+			os.Remove(path)
+			s.logger.Errorf("Online backup to %s failed: %v", path, err)
+			return nil, fmt.Errorf("failed to back up database: %v", err)
+		}
+	case models.BackupVacuum:
+		conn, err := s.db.Conn(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create backup %s", name)
+		}
+		// VACUUM INTO attaches the new file, which the authorizer denies
+		err = s.withoutAuthorizer(conn, func() error {
+			_, err := conn.ExecContext(ctx, "VACUUM INTO ?", path)
+			return err
+		})
+		conn.Close()
+		if err != nil {
+			os.Remove(path)
+			s.logger.Errorf("VACUUM INTO %s failed: %v", path, err)
+			return nil, fmt.Errorf("failed to back up database: %v", err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup %s", name)
+	}
+	sum, err := fileChecksum(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup %s", name)
+	}
+	backup := &models.Backup{
+		Name:      name,
+		Path:      path,
+		Source:    source,
+		Method:    method,
+		Label:     label,
+		CreatedAt: now.Format(backupCreatedFormat),
+		Size:      info.Size(),
+		Checksum:  sum,
+	}
+
+	meta, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeNewFile(path+backupMetaSuffix, string(meta)+"\n"); err != nil {
+		os.Remove(path)
+		s.logger.Errorf("Failed to write backup metadata: %v", err)
+		return nil, fmt.Errorf("failed to write metadata for backup %s", name)
+	}
+
+	return backup, nil
+}
+
+// readBackups reads the metadata files in the backup directory, newest first.
+// Backup files without metadata are ignored.
+func (s *SQLiteDB) readBackups() ([]models.Backup, error) {
+	entries, err := os.ReadDir(s.backupDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to read backup directory: %v", err)
+		return nil, fmt.Errorf("failed to read backup directory %s", s.backupDir)
+	}
+
+	var backups []models.Backup
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), backupMetaSuffix) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(s.backupDir, entry.Name()))
+		if err != nil {
+			s.logger.Warnf("Failed to read backup metadata %s: %v", entry.Name(), err)
+			continue
+		}
+		var backup models.Backup
+		if err := json.Unmarshal(content, &backup); err != nil || backup.Name+backupMetaSuffix != entry.Name() {
+			s.logger.Warnf("Ignoring invalid backup metadata %s", entry.Name())
+			continue
+		}
+		// The directory may have moved since the backup was written
+		backup.Path = filepath.Join(s.backupDir, backup.Name)
+		if _, err := os.Stat(backup.Path); err != nil {
+			continue
+		}
+		backups = append(backups, backup)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].CreatedAt != backups[j].CreatedAt {
+			return backups[i].CreatedAt > backups[j].CreatedAt
+		}
+		// Counter suffixes make later backups of the same microsecond longer
+		return len(backups[i].Name) > len(backups[j].Name) || len(backups[i].Name) == len(backups[j].Name) && backups[i].Name > backups[j].Name
+	})
+	return backups, nil
+}
+
+// pruneBackups removes the oldest backups of this database beyond keep
+func (s *SQLiteDB) pruneBackups(keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+	backups, err := s.readBackups()
+	if err != nil {
+		return nil, err
+	}
+
+	source := s.backupSource()
+	var pruned []string
+	kept := 0
+	for _, backup := range backups {
+		if backup.Source != source {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil {
+			s.logger.Errorf("Failed to remove backup %s: %v", backup.Path, err)
+			continue
+		}
+		os.Remove(backup.Path + backupMetaSuffix)
+		pruned = append(pruned, backup.Name)
+	}
+	return pruned, nil
+}
+
+// backupSource is the path backups of this database record as their source
+func (s *SQLiteDB) backupSource() string {
+	source, err := filepath.Abs(s.path)
+	if err != nil {
+		return s.path
+	}
+	return source
+}
+
+// copyDatabase copies the main database of src into dest with the online
+// backup API, in steps so that src is not locked for the whole copy
+func copyDatabase(ctx context.Context, dest, src *sql.DB) error {
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+	return copyConn(destConn, srcConn)
+}
+
+// copyConn copies the main database of src into dest like copyDatabase, for
+// callers already holding the destination connection
+func copyConn(destConn, srcConn *sql.Conn) error {
+	return destConn.Raw(func(destDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			destSQLite, ok := destDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", destDriver)
+			}
+			srcSQLite, ok := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", srcDriver)
+			}
+
+			backup, err := destSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+			for {
+				done, err := backup.Step(backupStepPages)
+				if err != nil {
+					backup.Finish()
+					return err
+				}
+				if done {
+					break
+				}
+				// Step also returns when the source is busy, give writers a moment
+				time.Sleep(time.Millisecond)
+			}
+			return backup.Finish()
+		})
+	})
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// fileChecksum returns the hex SHA-256 of a file's content
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
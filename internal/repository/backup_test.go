@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func countUsers(t *testing.T, db *SQLiteDB) int {
+	t.Helper()
+
+	var count int
+	if err := db.db.QueryRow("SELECT COUNT(*) FROM test_users").Scan(&count); err != nil {
+		t.Fatalf("Failed to count users: %v", err)
+	}
+	return count
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	dir := filepath.Join(t.TempDir(), "backups")
+	db.SetBackupDir(dir, 0)
+
+	if _, err := db.Execute("INSERT INTO test_users (name, email) VALUES ('Ann', 'ann@example.com')"); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+
+	for _, method := range []string{models.BackupOnline, models.BackupVacuum} {
+		result, err := db.Backup(models.BackupOptions{Method: method, Label: "Before Import"})
+		if err != nil {
+			t.Fatalf("%s backup failed: %v", method, err)
+		}
+		backup := result.Backup
+		if backup.Method != method || backup.Size == 0 || len(backup.Checksum) != 64 || !strings.HasSuffix(backup.Name, "-before_import.db") {
+			t.Errorf("Unexpected backup: %+v", backup)
+		}
+		if _, err := os.Stat(backup.Path + ".json"); err != nil {
+			t.Errorf("Expected metadata file: %v", err)
+		}
+	}
+
+	backups, err := db.ListBackups()
+	if err != nil {
+		t.Fatalf("ListBackups failed: %v", err)
+	}
+	if len(backups) != 2 || backups[0].Method != models.BackupVacuum {
+		t.Fatalf("Unexpected backups: %+v", backups)
+	}
+
+	if _, err := db.Execute("DELETE FROM test_users"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := db.Execute("CREATE TABLE scratch (x)"); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	restored, err := db.Restore(backups[1].Name)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if restored.Safety == nil || restored.Safety.Label != "pre_restore" {
+		t.Errorf("Expected a safety backup, got %+v", restored.Safety)
+	}
+	if count := countUsers(t, db); count != 1 {
+		t.Errorf("Expected 1 user after restore, got %d", count)
+	}
+	if exists, _ := db.tableExists("scratch"); exists {
+		t.Error("Expected scratch table to be gone after restore")
+	}
+
+	// Restoring the safety backup undoes the restore
+	if _, err := db.Restore(restored.Safety.Name); err != nil {
+		t.Fatalf("Restore of safety backup failed: %v", err)
+	}
+	if count := countUsers(t, db); count != 0 {
+		t.Errorf("Expected 0 users after undoing the restore, got %d", count)
+	}
+}
+
+func TestRestore_VerifiesChecksum(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.SetBackupDir(t.TempDir(), 0)
+	result, err := db.Backup(models.BackupOptions{})
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	f, err := os.OpenFile(result.Backup.Path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Failed to open backup: %v", err)
+	}
+	f.WriteString("tampered")
+	f.Close()
+
+	if _, err := db.Restore(result.Backup.Name); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Expected checksum error, got %v", err)
+	}
+	for _, name := range []string{"", "../" + result.Backup.Name, result.Backup.Name + ".json", "missing.db"} {
+		if _, err := db.Restore(name); err == nil {
+			t.Errorf("Expected error restoring %q", name)
+		}
+	}
+}
+
+func TestBackup_Retention(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	db.SetBackupDir(dir, 2)
+
+	var names []string
+	for i := 0; i < 4; i++ {
+		result, err := db.Backup(models.BackupOptions{Label: string(rune('a' + i))})
+		if err != nil {
+			t.Fatalf("Backup failed: %v", err)
+		}
+		names = append(names, result.Backup.Name)
+		if i >= 2 && (len(result.Pruned) != 1 || result.Pruned[0] != names[i-2]) {
+			t.Errorf("Backup %d: expected %s pruned, got %v", i, names[i-2], result.Pruned)
+		}
+	}
+
+	backups, err := db.ListBackups()
+	if err != nil {
+		t.Fatalf("ListBackups failed: %v", err)
+	}
+	if len(backups) != 2 || backups[0].Name != names[3] || backups[1].Name != names[2] {
+		t.Errorf("Unexpected backups after retention: %+v", backups)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 4 {
+		t.Errorf("Expected 2 backups with metadata, got %d files", len(entries))
+	}
+
+	// A per-call limit overrides the configured one
+	result, err := db.Backup(models.BackupOptions{Keep: 1})
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if len(result.Pruned) != 2 {
+		t.Errorf("Expected 2 pruned backups, got %v", result.Pruned)
+	}
+}
+
+func TestBackup_SharedDirectory(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	other := newTestDBWithSchema(t, "other.db", "CREATE TABLE notes (id INTEGER PRIMARY KEY)")
+
+	dir := t.TempDir()
+	db.SetBackupDir(dir, 0)
+	other.SetBackupDir(dir, 0)
+
+	if _, err := db.Backup(models.BackupOptions{}); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	result, err := other.Backup(models.BackupOptions{})
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	backups, err := db.ListBackups()
+	if err != nil {
+		t.Fatalf("ListBackups failed: %v", err)
+	}
+	if len(backups) != 1 || backups[0].Source != db.backupSource() {
+		t.Errorf("Expected only the backup of this database, got %+v", backups)
+	}
+
+	// Restoring another database's backup would silently replace the contents
+	if _, err := db.Restore(result.Backup.Name); err == nil || !strings.Contains(err.Error(), "not of this database") {
+		t.Errorf("Expected the backup of another database to be refused, got %v", err)
+	}
+	if exists, _ := db.tableExists("test_users"); !exists {
+		t.Error("Expected the database to be unchanged")
+	}
+}
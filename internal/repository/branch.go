@@ -0,0 +1,685 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+const (
+	// Schema names the base snapshot and the branch are attached under while merging
+	branchBaseSchema = "_mcp_base"
+	branchSchema     = "_mcp_branch"
+
+	maxBranchNameLength = 64
+	maxMergeConflicts   = 20
+)
+
+// branch is a copy of the main database in its own temporary directory, next
+// to an untouched snapshot of main taken at the same time. The snapshot is the
+// common ancestor when the branch is merged.
+type branch struct {
+	name    string
+	session string
+	dir     string
+	created time.Time
+	db      *SQLiteDB // nil while the snapshot is taken
+}
+
+func (b *branch) path() string {
+	return filepath.Join(b.dir, "branch.db")
+}
+
+func (b *branch) basePath() string {
+	return filepath.Join(b.dir, "base.db")
+}
+
+// BranchManager keeps copy-on-write branches of a main database. A session
+// works on at most one branch at a time; sessions without one work on the
+// main database, which stays untouched until a branch is merged.
+type BranchManager struct {
+	main *SQLiteDB
+
+	mu       sync.Mutex
+	branches map[string]*branch // By name
+	sessions map[string]*branch // By session ID
+}
+
+func NewBranchManager(main *SQLiteDB) *BranchManager {
+	return &BranchManager{
+		main:     main,
+		branches: make(map[string]*branch),
+		sessions: make(map[string]*branch),
+	}
+}
+
+// Current returns the database a session works on
+func (m *BranchManager) Current(session string) *SQLiteDB {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if b := m.sessions[session]; b != nil {
+		return b.db
+	}
+	return m.main
+}
+
+// Create snapshots the main database into a new branch with the online backup
+// API and switches the session to it. Without a name the branch is numbered.
+func (m *BranchManager) Create(session, name string) (*models.Branch, error) {
+	m.main.logger.Debugf("Creating branch %s", name)
+
+	m.mu.Lock()
+	if current := m.sessions[session]; current != nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("this session already works on branch %s, merge or discard it first", current.name)
+	}
+	for i := 1; name == ""; i++ {
+		if candidate := fmt.Sprintf("branch_%d", i); m.branches[candidate] == nil {
+			name = candidate
+		}
+	}
+	if !validBranchName(name) {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("invalid branch name %s, use up to %d letters, digits, '_' and '-'", name, maxBranchNameLength)
+	}
+	if m.branches[name] != nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("branch %s already exists", name)
+	}
+	// Reserve the name, the snapshot is taken without holding the lock
+	b := &branch{name: name, session: session, created: time.Now().UTC()}
+	m.branches[name] = b
+	m.mu.Unlock()
+
+	if err := m.snapshot(b); err != nil {
+		m.mu.Lock()
+		delete(m.branches, name)
+		m.mu.Unlock()
+		if b.dir != "" {
+			os.RemoveAll(b.dir)
+		}
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.sessions[session] = b
+	info := m.info(b, session)
+	m.mu.Unlock()
+
+	m.main.logger.Infof("Created branch %s at %s", name, b.path())
+	return &info, nil
+}
+
+// snapshot copies the main database into the branch directory and opens the copy
+func (m *BranchManager) snapshot(b *branch) error {
+	dir, err := os.MkdirTemp("", "sqlite-mcp-branch-*")
+	if err != nil {
+		m.main.logger.Errorf("Failed to create branch directory: %v", err)
+		return fmt.Errorf("failed to create branch %s", b.name)
+	}
+	b.dir = dir
+
+	ctx := context.Background()
+	if err := copyDatabaseToFile(ctx, b.path(), m.main.db); err != nil {
+		m.main.logger.Errorf("Failed to snapshot database for branch %s: %v", b.name, err)
+		return fmt.Errorf("failed to snapshot database: %v", err)
+	}
+	// The branch shares the policy and limits of main, including later changes
+	db, err := openWithAccess(b.path(), models.DatabaseOptions{Attach: m.main.attachments, Connection: m.main.connection}, m.main.access, m.main.logger)
+	if err != nil {
+		return fmt.Errorf("failed to open branch %s", b.name)
+	}
+	db.limits = m.main.limits
+	// The base is copied from the branch before anything writes to it, so
+	// both start from the same state even if main changed in between
+	if err := copyDatabaseToFile(ctx, b.basePath(), db.db); err != nil {
+		db.Close()
+		m.main.logger.Errorf("Failed to snapshot base of branch %s: %v", b.name, err)
+		return fmt.Errorf("failed to snapshot database: %v", err)
+	}
+
+	db.migrationsDir = m.main.migrationsDir
+	db.importDirs = m.main.importDirs
+	db.exportDir = m.main.exportDir
+	// Annotations kept in the database are part of the copy, a file is shared
+	if _, ok := m.main.annotations.(*tableAnnotationStore); !ok {
+		db.annotations = m.main.annotations
+	}
+	// Saved queries are exposed as tools of the main database, so a branch
+	// always sees the same ones
+	db.queries = m.main.queries
+	b.db = db
+	return nil
+}
+
+// List returns all branches by name, marking the one the session works on
+func (m *BranchManager) List(session string) []models.Branch {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var branches []models.Branch
+	for _, name := range sortedKeys(m.branches) {
+		if b := m.branches[name]; b.db != nil {
+			branches = append(branches, m.info(b, session))
+		}
+	}
+	return branches
+}
+
+// Diff compares the main database with a branch, the session's branch when
+// name is empty. The diffs describe how to turn main into the branch.
+func (m *BranchManager) Diff(session, name string, opts models.DataDiffOptions) (*models.BranchDiff, error) {
+	b, err := m.lookup(session, name)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := m.main.DiffSchemaWithFile(b.path(), false)
+	if err != nil {
+		return nil, err
+	}
+	opts.FromOther = false
+	data, err := m.main.DiffDataWithFile(b.path(), opts)
+	if err != nil {
+		return nil, err
+	}
+	return &models.BranchDiff{Branch: b.name, Schema: schema, Data: data}, nil
+}
+
+// Merge applies the changes made on a branch to the main database and removes
+// the branch. Rows are merged three-way against the snapshot the branch was
+// created from, so changes made to main in the meantime are kept; rows changed
+// on both sides are reported as conflicts and nothing is merged. A branch
+// that changed the schema can only be merged while main is unchanged, main is
+// then replaced with the branch.
+func (m *BranchManager) Merge(session, name string) (*models.BranchMergeResult, error) {
+	b, err := m.lookup(session, name)
+	if err != nil {
+		return nil, err
+	}
+	m.main.logger.Debugf("Merging branch %s", b.name)
+
+	result, err := m.main.mergeBranch(b.path(), b.basePath())
+	if err != nil {
+		return nil, err
+	}
+	result.Branch = b.name
+	if !result.Merged {
+		m.main.logger.Infof("Merge of branch %s stopped on %d conflicts", b.name, len(result.Conflicts))
+		return result, nil
+	}
+
+	m.remove(b)
+	m.main.logger.Infof("Merged branch %s, fast-forward: %t", b.name, result.FastForward)
+	return result, nil
+}
+
+// Discard removes a branch without merging it
+func (m *BranchManager) Discard(session, name string) (*models.Branch, error) {
+	b, err := m.lookup(session, name)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	info := m.info(b, session)
+	m.mu.Unlock()
+	m.remove(b)
+
+	m.main.logger.Infof("Discarded branch %s", b.name)
+	return &info, nil
+}
+
+// Close removes all branches
+func (m *BranchManager) Close() error {
+	m.mu.Lock()
+	branches := make([]*branch, 0, len(m.branches))
+	for _, b := range m.branches {
+		if b.db != nil {
+			branches = append(branches, b)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, b := range branches {
+		m.remove(b)
+	}
+	return nil
+}
+
+// lookup returns a branch by name, or the session's branch when name is empty
+func (m *BranchManager) lookup(session, name string) (*branch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if name == "" {
+		if b := m.sessions[session]; b != nil {
+			return b, nil
+		}
+		return nil, fmt.Errorf("this session does not work on a branch")
+	}
+	if b := m.branches[name]; b != nil && b.db != nil {
+		return b, nil
+	}
+	return nil, fmt.Errorf("branch %s does not exist", name)
+}
+
+// remove forgets a branch, switching its session back to main, and deletes its files
+func (m *BranchManager) remove(b *branch) {
+	m.mu.Lock()
+	if m.branches[b.name] != b {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.branches, b.name)
+	if m.sessions[b.session] == b {
+		delete(m.sessions, b.session)
+	}
+	m.mu.Unlock()
+
+	if err := b.db.Close(); err != nil {
+		m.main.logger.Warnf("Failed to close branch %s: %v", b.name, err)
+	}
+	if err := os.RemoveAll(b.dir); err != nil {
+		m.main.logger.Warnf("Failed to remove branch directory %s: %v", b.dir, err)
+	}
+}
+
+// info describes a branch; callers hold mu
+func (m *BranchManager) info(b *branch, session string) models.Branch {
+	info := models.Branch{
+		Name:      b.name,
+		Path:      b.path(),
+		Session:   b.session,
+		CreatedAt: b.created.Format(time.RFC3339),
+		Active:    m.sessions[session] == b,
+	}
+	if stat, err := os.Stat(b.path()); err == nil {
+		info.Size = stat.Size()
+	}
+	return info
+}
+
+func validBranchName(name string) bool {
+	if name == "" || len(name) > maxBranchNameLength {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeTable is a table merged row by row, matched on its primary key or rowid
+type mergeTable struct {
+	name    string
+	columns []string // Stored columns, generated columns are computed again
+	key     []string
+	rowid   bool // Matched on rowid, which is then copied as well
+}
+
+// mergeBranch merges the branch file into this database using the base file
+// as the common ancestor
+func (s *SQLiteDB) mergeBranch(branchPath, basePath string) (*models.BranchMergeResult, error) {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to get connection: %v", err)
+		return nil, fmt.Errorf("failed to merge branch")
+	}
+	defer conn.Close()
+
+	for _, attach := range []struct{ schema, path string }{{branchBaseSchema, basePath}, {branchSchema, branchPath}} {
+		if err := s.attachDatabase(ctx, conn, attach.path, attach.schema); err != nil {
+			s.logger.Errorf("Failed to attach %s: %v", attach.path, err)
+			return nil, fmt.Errorf("failed to open branch")
+		}
+		defer func(schema string) {
+			if err := s.detachDatabase(ctx, conn, schema); err != nil {
+				s.logger.Errorf("Failed to detach %s: %v", schema, err)
+			}
+		}(attach.schema)
+	}
+
+	definitions := make(map[string]string)
+	for _, schema := range []string{"main", branchBaseSchema, branchSchema} {
+		definition, err := schemaDefinition(ctx, conn, schema)
+		if err != nil {
+			s.logger.Errorf("Failed to read schema of %s: %v", schema, err)
+			return nil, fmt.Errorf("failed to merge branch")
+		}
+		definitions[schema] = definition
+	}
+	tables, err := mergeTables(ctx, conn, branchBaseSchema)
+	if err != nil {
+		s.logger.Errorf("Failed to list tables: %v", err)
+		return nil, fmt.Errorf("failed to merge branch")
+	}
+
+	result := &models.BranchMergeResult{}
+	if definitions[branchSchema] != definitions[branchBaseSchema] {
+		return s.fastForward(ctx, conn, result, tables, definitions, branchPath, basePath)
+	}
+	if definitions["main"] != definitions[branchBaseSchema] {
+		return nil, fmt.Errorf("the schema of main changed since the branch was created, discard the branch and create it again")
+	}
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		s.logger.Errorf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to merge branch: %v", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			if _, err := conn.ExecContext(ctx, "ROLLBACK"); err != nil {
+				s.logger.Errorf("Failed to roll back merge: %v", err)
+			}
+		}
+	}()
+	// Rows may reference each other across tables, check once at commit
+	if _, err := conn.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON"); err != nil {
+		return nil, fmt.Errorf("failed to merge branch")
+	}
+
+	for _, table := range tables {
+		conflicts, err := table.conflicts(ctx, conn, maxMergeConflicts-len(result.Conflicts))
+		if err != nil {
+			s.logger.Errorf("Failed to check table %s for conflicts: %v", table.name, err)
+			return nil, fmt.Errorf("failed to merge table %s", table.name)
+		}
+		result.Conflicts = append(result.Conflicts, conflicts...)
+		if len(result.Conflicts) >= maxMergeConflicts {
+			break
+		}
+	}
+	if len(result.Conflicts) > 0 {
+		return result, nil
+	}
+
+	for _, table := range tables {
+		merged, err := table.apply(ctx, conn)
+		if err != nil {
+			s.logger.Errorf("Failed to merge table %s: %v", table.name, err)
+			return nil, fmt.Errorf("failed to merge table %s: %v", table.name, err)
+		}
+		if merged.Inserted+merged.Updated+merged.Deleted > 0 {
+			result.Tables = append(result.Tables, *merged)
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		s.logger.Errorf("Failed to commit merge: %v", err)
+		return nil, fmt.Errorf("failed to merge branch: %v", err)
+	}
+	committed = true
+	result.Merged = true
+	return result, nil
+}
+
+// fastForward replaces this database with a branch that changed the schema,
+// provided main still matches the base the branch was created from
+func (s *SQLiteDB) fastForward(ctx context.Context, conn *sql.Conn, result *models.BranchMergeResult, tables []mergeTable, definitions map[string]string, branchPath, basePath string) (*models.BranchMergeResult, error) {
+	if err := s.checkFullAccess(); err != nil {
+		return nil, fmt.Errorf("%w, the branch changes the schema", err)
+	}
+	changed := definitions["main"] != definitions[branchBaseSchema]
+	for _, table := range tables {
+		if changed {
+			break
+		}
+		differs, err := table.differs(ctx, conn, "main", branchBaseSchema)
+		if err != nil {
+			s.logger.Errorf("Failed to compare table %s: %v", table.name, err)
+			return nil, fmt.Errorf("failed to merge branch")
+		}
+		changed = differs
+	}
+	if changed {
+		return nil, fmt.Errorf("the branch changes the schema and main changed since the branch was created, such a branch can only be merged while main is unchanged")
+	}
+
+	base, err := NewReadOnlySQLiteDB(basePath, s.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open branch")
+	}
+	diff, err := base.DiffSchemaWithFile(branchPath, false)
+	base.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := sql.Open("sqlite3", readOnlyDSN(branchPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open branch")
+	}
+	defer src.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open branch")
+	}
+	defer srcConn.Close()
+	// The held connection is the single writer, copy through it
+	if err := copyConn(conn, srcConn); err != nil {
+		s.logger.Errorf("Failed to copy branch into %s: %v", s.path, err)
+		return nil, fmt.Errorf("failed to merge branch: %v", err)
+	}
+
+	result.Merged = true
+	result.FastForward = true
+	result.Schema = diff.Migration
+	return result, nil
+}
+
+// schemaDefinition returns the definitions of all schema objects as one string
+func schemaDefinition(ctx context.Context, conn *sql.Conn, schema string) (string, error) {
+	var definition sql.NullString
+	err := conn.QueryRowContext(ctx, fmt.Sprintf(`SELECT group_concat(type || ' ' || name || ' ' || coalesce(sql, ''), char(10))
+		FROM (SELECT type, name, sql FROM %s.sqlite_master WHERE name NOT LIKE 'sqlite\_%%' ESCAPE '\' ORDER BY type, name)`, quoteIdent(schema))).Scan(&definition)
+	return definition.String, err
+}
+
+// mergeTables lists the tables of a schema with their stored columns and keys.
+// Virtual tables are merged through their shadow tables.
+func mergeTables(ctx context.Context, conn *sql.Conn, schema string) ([]mergeTable, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(`SELECT m.name, c.name, c.pk
+		FROM (SELECT name FROM %s.sqlite_master
+			WHERE type = 'table' AND name NOT LIKE 'sqlite\_%%' ESCAPE '\' AND sql NOT LIKE 'CREATE VIRTUAL %%') m,
+			pragma_table_xinfo(m.name, ?) c
+		WHERE c.hidden = 0
+		ORDER BY m.name, c.cid`, quoteIdent(schema)), schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []mergeTable
+	var keys [][]diffColumn
+	for rows.Next() {
+		var table string
+		var col diffColumn
+		if err := rows.Scan(&table, &col.Name, &col.PK); err != nil {
+			return nil, err
+		}
+		if len(tables) == 0 || tables[len(tables)-1].name != table {
+			tables = append(tables, mergeTable{name: table})
+			keys = append(keys, nil)
+		}
+		tables[len(tables)-1].columns = append(tables[len(tables)-1].columns, col.Name)
+		if col.PK > 0 {
+			keys[len(keys)-1] = append(keys[len(keys)-1], col)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range tables {
+		key := keys[i]
+		if len(key) == 0 {
+			tables[i].key = []string{"rowid"}
+			tables[i].rowid = true
+			continue
+		}
+		sort.Slice(key, func(a, b int) bool { return key[a].PK < key[b].PK })
+		for _, col := range key {
+			tables[i].key = append(tables[i].key, col.Name)
+		}
+	}
+	return tables, nil
+}
+
+func (t mergeTable) in(schema string) string {
+	return quoteIdent(schema) + "." + quoteIdent(t.name)
+}
+
+// differs reports whether the table holds different rows in two schemas
+func (t mergeTable) differs(ctx context.Context, conn *sql.Conn, left, right string) (bool, error) {
+	columns := strings.Join(qualifiedColumns("t", t.columns), ", ")
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT %[1]s FROM %[2]s t EXCEPT SELECT %[1]s FROM %[3]s t)
+		OR EXISTS (SELECT %[1]s FROM %[3]s t EXCEPT SELECT %[1]s FROM %[2]s t)`, columns, t.in(left), t.in(right))
+	var differs bool
+	err := conn.QueryRowContext(ctx, query).Scan(&differs)
+	return differs, err
+}
+
+// conflicts returns up to limit rows the branch changed that main changed too.
+// a is the base, b the branch and m main.
+func (t mergeTable) conflicts(ctx context.Context, conn *sql.Conn, limit int) ([]models.MergeConflict, error) {
+	base, branch, main := t.in(branchBaseSchema), t.in(branchSchema), t.in("main")
+	queries := []struct{ reason, query string }{
+		{
+			"inserted on the branch, main has a different row with this key",
+			fmt.Sprintf("SELECT %s FROM %s b WHERE NOT EXISTS (SELECT 1 FROM %s a WHERE %s) AND EXISTS (SELECT 1 FROM %s m WHERE %s AND NOT (%s))",
+				t.keyList("b"), branch, base, t.match("a", "b"), main, t.match("m", "b"), t.equal("m", "b")),
+		},
+		{
+			"deleted on the branch, changed in main",
+			fmt.Sprintf("SELECT %s FROM %s a WHERE NOT EXISTS (SELECT 1 FROM %s b WHERE %s) AND EXISTS (SELECT 1 FROM %s m WHERE %s AND NOT (%s))",
+				t.keyList("a"), base, branch, t.match("a", "b"), main, t.match("m", "a"), t.equal("m", "a")),
+		},
+		{
+			"updated on the branch, changed or deleted in main",
+			fmt.Sprintf("SELECT %s FROM %s b JOIN %s a ON %s WHERE NOT (%s) AND NOT EXISTS (SELECT 1 FROM %s m WHERE %s AND (%s OR %s))",
+				t.keyList("b"), branch, base, t.match("a", "b"), t.equal("a", "b"), main, t.match("m", "b"), t.equal("m", "a"), t.equal("m", "b")),
+		},
+	}
+
+	var conflicts []models.MergeConflict
+	for _, q := range queries {
+		if len(conflicts) >= limit {
+			break
+		}
+		rows, err := conn.QueryContext(ctx, q.query+" LIMIT ?", limit-len(conflicts))
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			values := make([]any, len(t.key))
+			valuePtrs := make([]any, len(t.key))
+			for i := range values {
+				valuePtrs[i] = &values[i]
+			}
+			if err := rows.Scan(valuePtrs...); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			conflict := models.MergeConflict{Table: t.name, Key: make(map[string]any, len(t.key)), Reason: q.reason}
+			for i, col := range t.key {
+				conflict.Key[col] = normalizeValue(values[i])
+			}
+			conflicts = append(conflicts, conflict)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return conflicts, nil
+}
+
+// apply deletes, updates and inserts the rows the branch changed. Rows main
+// already holds in the branch's state are left alone.
+func (t mergeTable) apply(ctx context.Context, conn *sql.Conn) (*models.TableMerge, error) {
+	base, branch, main := t.in(branchBaseSchema), t.in(branchSchema), t.in("main")
+	merged := &models.TableMerge{Table: t.name}
+
+	var err error
+	merged.Deleted, err = execCount(ctx, conn, fmt.Sprintf("DELETE FROM %s AS m WHERE EXISTS (SELECT 1 FROM %s a WHERE %s AND NOT EXISTS (SELECT 1 FROM %s b WHERE %s))",
+		main, base, t.match("m", "a"), branch, t.match("a", "b")))
+	if err != nil {
+		return nil, err
+	}
+
+	var assignments []string
+	for _, col := range t.columns {
+		if !containsFold(t.key, col) {
+			assignments = append(assignments, fmt.Sprintf("%s = b.%s", quoteIdent(col), quoteIdent(col)))
+		}
+	}
+	if len(assignments) > 0 {
+		merged.Updated, err = execCount(ctx, conn, fmt.Sprintf("UPDATE %s AS m SET %s FROM %s AS a, %s AS b WHERE %s AND NOT (%s) AND %s AND NOT (%s)",
+			main, strings.Join(assignments, ", "), base, branch, t.match("a", "b"), t.equal("a", "b"), t.match("m", "b"), t.equal("m", "b")))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	columns := t.columns
+	if t.rowid {
+		columns = append([]string{"rowid"}, columns...)
+	}
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = quoteIdent(col)
+	}
+	merged.Inserted, err = execCount(ctx, conn, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s b WHERE NOT EXISTS (SELECT 1 FROM %s a WHERE %s) AND NOT EXISTS (SELECT 1 FROM %s m WHERE %s)",
+		main, strings.Join(quoted, ", "), strings.Join(qualifiedColumns("b", columns), ", "), branch, base, t.match("a", "b"), main, t.match("m", "b")))
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func (t mergeTable) keyList(alias string) string {
+	return strings.Join(qualifiedColumns(alias, t.key), ", ")
+}
+
+// match compares the keys of two aliases
+func (t mergeTable) match(left, right string) string {
+	return matchCondition(left, right, t.key)
+}
+
+// equal compares the stored columns of two aliases
+func (t mergeTable) equal(left, right string) string {
+	return matchCondition(left, right, t.columns)
+}
+
+func execCount(ctx context.Context, conn *sql.Conn, query string) (int64, error) {
+	result, err := conn.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// copyDatabaseToFile copies the main database of src into a new file
+func copyDatabaseToFile(ctx context.Context, path string, src *sql.DB) error {
+	dest, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+	return copyDatabase(ctx, dest, src)
+}
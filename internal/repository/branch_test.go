@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func newBranchTestDB(t *testing.T) *SQLiteDB {
+	return newTestDBWithSchema(t, "main.db",
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT, qty INTEGER)",
+		"CREATE TABLE notes (body TEXT)",
+		"INSERT INTO items VALUES (1, 'apple', 5), (2, 'pear', 3), (3, 'plum', 1)",
+		"INSERT INTO notes VALUES ('first')",
+	)
+}
+
+func countRows(t *testing.T, db *SQLiteDB, query string) int64 {
+	t.Helper()
+
+	var count int64
+	if err := db.db.QueryRow(query).Scan(&count); err != nil {
+		t.Fatalf("Query %q failed: %v", query, err)
+	}
+	return count
+}
+
+func TestBranch_CreateSwitchesSession(t *testing.T) {
+	main := newBranchTestDB(t)
+	branches := NewBranchManager(main)
+	defer branches.Close()
+
+	created, err := branches.Create("s1", "")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if created.Name != "branch_1" || !created.Active || created.Size == 0 {
+		t.Errorf("Unexpected branch: %+v", created)
+	}
+	if _, err := branches.Create("s1", "other"); err == nil || !strings.Contains(err.Error(), "already works on branch branch_1") {
+		t.Errorf("Expected an error for a second branch, got: %v", err)
+	}
+	if _, err := branches.Create("s2", "bad name"); err == nil {
+		t.Error("Expected an error for an invalid name")
+	}
+
+	if branches.Current("s1") == main || branches.Current("s2") != main {
+		t.Fatal("Expected only s1 to work on the branch")
+	}
+	if _, err := branches.Current("s1").Execute("DELETE FROM items"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := countRows(t, main, "SELECT COUNT(*) FROM items"); got != 3 {
+		t.Errorf("Expected main to be untouched, got %d rows", got)
+	}
+
+	list := branches.List("s2")
+	if len(list) != 1 || list[0].Active || list[0].Session != "s1" {
+		t.Errorf("Unexpected branches: %+v", list)
+	}
+
+	discarded, err := branches.Discard("s1", "")
+	if err != nil {
+		t.Fatalf("Discard failed: %v", err)
+	}
+	if _, err := os.Stat(discarded.Path); !os.IsNotExist(err) {
+		t.Errorf("Expected branch file to be removed, got: %v", err)
+	}
+	if branches.Current("s1") != main || len(branches.List("s1")) != 0 {
+		t.Error("Expected the session to be back on main")
+	}
+}
+
+func TestBranch_FollowsMainPolicy(t *testing.T) {
+	main := newBranchTestDB(t)
+	if err := main.SetPolicy(models.PolicyNoDDL); err != nil {
+		t.Fatalf("SetPolicy failed: %v", err)
+	}
+	branches := NewBranchManager(main)
+	defer branches.Close()
+
+	if _, err := branches.Create("s1", ""); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	branch := branches.Current("s1")
+	if branch.Policy() != models.PolicyNoDDL {
+		t.Errorf("Expected the branch to have the no-ddl policy, got %s", branch.Policy())
+	}
+	if _, err := branch.Execute("CREATE TABLE other (x)"); err == nil {
+		t.Error("Expected the policy of main to apply on the branch")
+	}
+
+	// A policy change of main reaches the open branch
+	if err := main.SetPolicy(models.PolicyFull); err != nil {
+		t.Fatalf("SetPolicy failed: %v", err)
+	}
+	if _, err := branch.Execute("CREATE TABLE other (x)"); err != nil {
+		t.Errorf("Expected the changed policy to apply on the branch, got %v", err)
+	}
+}
+
+func TestBranch_DiffAndMerge(t *testing.T) {
+	main := newBranchTestDB(t)
+	branches := NewBranchManager(main)
+	defer branches.Close()
+
+	if _, err := branches.Create("s1", "restock"); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	for _, statement := range []string{
+		"UPDATE items SET qty = 10 WHERE id = 1",
+		"DELETE FROM items WHERE id = 2",
+		"INSERT INTO items VALUES (4, 'kiwi', 7)",
+		"INSERT INTO notes VALUES ('second')",
+	} {
+		if _, err := branches.Current("s1").Execute(statement); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+	}
+	// Changed in main meanwhile, on rows the branch did not touch
+	if _, err := main.Execute("UPDATE items SET qty = 2 WHERE id = 3"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	diff, err := branches.Diff("s1", "", models.DataDiffOptions{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !diff.Schema.Identical || diff.Data.Identical || len(diff.Data.Tables) != 2 {
+		t.Errorf("Unexpected diff: %+v", diff.Data)
+	}
+
+	result, err := branches.Merge("s1", "")
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if !result.Merged || result.FastForward || len(result.Tables) != 2 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if items := result.Tables[0]; items.Table != "items" || items.Inserted != 1 || items.Updated != 1 || items.Deleted != 1 {
+		t.Errorf("Unexpected table merge: %+v", items)
+	}
+
+	rows, err := main.Query("SELECT id, qty FROM items ORDER BY id")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	var got []string
+	for _, row := range rows.Rows {
+		got = append(got, fmt.Sprintf("%v:%v", row["id"], row["qty"]))
+	}
+	if strings.Join(got, " ") != "1:10 3:2 4:7" {
+		t.Errorf("Unexpected items after merge: %v", got)
+	}
+	if got := countRows(t, main, "SELECT COUNT(*) FROM notes"); got != 2 {
+		t.Errorf("Expected 2 notes, got %d", got)
+	}
+	if branches.Current("s1") != main || len(branches.List("s1")) != 0 {
+		t.Error("Expected the merged branch to be removed")
+	}
+}
+
+func TestBranch_MergeConflicts(t *testing.T) {
+	main := newBranchTestDB(t)
+	branches := NewBranchManager(main)
+	defer branches.Close()
+
+	if _, err := branches.Create("s1", "edit"); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := branches.Current("s1").Execute("UPDATE items SET qty = 0 WHERE id = 1"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, err := main.Execute("UPDATE items SET qty = 9 WHERE id = 1"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	result, err := branches.Merge("s2", "edit")
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if result.Merged || len(result.Conflicts) != 1 || result.Conflicts[0].Key["id"] != int64(1) {
+		t.Fatalf("Expected one conflict, got: %+v", result)
+	}
+	if got := countRows(t, main, "SELECT qty FROM items WHERE id = 1"); got != 9 {
+		t.Errorf("Expected main to be unchanged, got qty %d", got)
+	}
+	if len(branches.List("s1")) != 1 {
+		t.Error("Expected the branch to be kept after a conflict")
+	}
+}
+
+func TestBranch_MergeSchemaChange(t *testing.T) {
+	main := newBranchTestDB(t)
+	branches := NewBranchManager(main)
+	defer branches.Close()
+
+	if _, err := branches.Create("s1", "schema"); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := branches.Current("s1").Execute("ALTER TABLE items ADD COLUMN price REAL"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// Blocked while main differs from the base
+	if _, err := main.Execute("INSERT INTO notes VALUES ('main')"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, err := branches.Merge("s1", ""); err == nil || !strings.Contains(err.Error(), "main changed") {
+		t.Fatalf("Expected a schema merge error, got: %v", err)
+	}
+	if _, err := main.Execute("DELETE FROM notes WHERE body = 'main'"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	result, err := branches.Merge("s1", "")
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if !result.Merged || !result.FastForward || len(result.Schema) == 0 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if got := countRows(t, main, "SELECT COUNT(*) FROM pragma_table_info('items') WHERE name = 'price'"); got != 1 {
+		t.Error("Expected the new column in main")
+	}
+}
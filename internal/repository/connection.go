@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+const (
+	defaultReaders = 24 // With the writer, the 25 connections of the former single pool
+	maxIdleReaders = 5
+	// pinnedReaders are held by the data_version and in-memory keep-alive
+	// connections, on top of the readers available to queries
+	pinnedReaders = 2
+)
+
+var (
+	pragmaNamePattern  = regexp.MustCompile(`^[A-Za-z_]+$`)
+	pragmaValuePattern = regexp.MustCompile(`^-?[A-Za-z0-9_.]+$`)
+)
+
+// connectionPragmas returns the PRAGMA statements run on every new connection
+// for the options. The journal mode is left alone on read-only databases, as
+// changing it writes to the file.
+func connectionPragmas(opts models.ConnectionOptions, readOnly bool) ([]string, error) {
+	var pragmas []string
+	add := func(name, value string) error {
+		if !pragmaNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid pragma name %q", name)
+		}
+		if !pragmaValuePattern.MatchString(value) {
+			return fmt.Errorf("invalid value %q for pragma %s", value, name)
+		}
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA %s = %s", name, value))
+		return nil
+	}
+	oneOf := func(name, value string, allowed ...string) error {
+		if !slices.Contains(allowed, strings.ToUpper(value)) {
+			return fmt.Errorf("invalid %s %s, expected one of %s", name, value, strings.Join(allowed, ", "))
+		}
+		return add(name, strings.ToUpper(value))
+	}
+
+	if opts.BusyTimeout < 0 || opts.MmapSize < 0 || opts.Readers < 0 {
+		return nil, fmt.Errorf("busy timeout, mmap size and readers cannot be negative")
+	}
+	// The busy timeout comes first so the other pragmas already wait for locks
+	if opts.BusyTimeout > 0 {
+		if err := add("busy_timeout", strconv.Itoa(opts.BusyTimeout)); err != nil {
+			return nil, err
+		}
+	}
+	if opts.JournalMode != "" && !readOnly {
+		if err := oneOf("journal_mode", opts.JournalMode, "DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Synchronous != "" {
+		if err := oneOf("synchronous", opts.Synchronous, "OFF", "NORMAL", "FULL", "EXTRA"); err != nil {
+			return nil, err
+		}
+	}
+	if opts.CacheSize != 0 {
+		if err := add("cache_size", strconv.Itoa(opts.CacheSize)); err != nil {
+			return nil, err
+		}
+	}
+	if opts.MmapSize > 0 {
+		if err := add("mmap_size", strconv.FormatInt(opts.MmapSize, 10)); err != nil {
+			return nil, err
+		}
+	}
+	if opts.ForeignKeys {
+		if err := add("foreign_keys", "ON"); err != nil {
+			return nil, err
+		}
+	}
+	for _, pragma := range opts.Pragmas {
+		name, value, ok := strings.Cut(pragma, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid pragma %q, expected name=value", pragma)
+		}
+		if err := add(strings.TrimSpace(name), strings.TrimSpace(value)); err != nil {
+			return nil, err
+		}
+	}
+	return pragmas, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func TestConnection_AppliesPragmas(t *testing.T) {
+	db := openWithOptions(t, filepath.Join(t.TempDir(), "tuned.db"), models.DatabaseOptions{Connection: models.ConnectionOptions{
+		JournalMode: "wal",
+		Synchronous: "normal",
+		BusyTimeout: 1234,
+		CacheSize:   -4000,
+		ForeignKeys: true,
+		Pragmas:     []string{"temp_store=MEMORY"},
+		Readers:     2,
+	}})
+
+	expected := map[string]string{
+		"journal_mode": "wal",
+		"synchronous":  "1",
+		"busy_timeout": "1234",
+		"cache_size":   "-4000",
+		"foreign_keys": "1",
+		"temp_store":   "2",
+	}
+	ctx := context.Background()
+	for poolName, pool := range map[string]*sql.DB{"writer": db.db, "reader": db.reader} {
+		conn, err := pool.Conn(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get %s connection: %v", poolName, err)
+		}
+		for pragma, want := range expected {
+			var got string
+			if err := conn.QueryRowContext(ctx, "PRAGMA "+pragma).Scan(&got); err != nil || got != want {
+				t.Errorf("%s connection: expected %s = %s, got %s (%v)", poolName, pragma, want, got, err)
+			}
+		}
+		conn.Close()
+	}
+
+	// Reader connections cannot write
+	conn, err := db.reader.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get reader connection: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE t (x)"); err == nil {
+		t.Error("Expected a reader connection to reject writes")
+	}
+}
+
+func TestConnection_ConcurrentWriters(t *testing.T) {
+	db := openWithOptions(t, filepath.Join(t.TempDir(), "writers.db"), models.DatabaseOptions{Connection: models.ConnectionOptions{JournalMode: "WAL"}})
+	if _, err := db.Execute("CREATE TABLE events (id INTEGER PRIMARY KEY, source TEXT)"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 80)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := db.Execute(fmt.Sprintf("INSERT INTO events (source) VALUES ('writer %d')", i)); err != nil {
+					errs <- err
+				}
+				if _, err := db.Query("SELECT COUNT(*) FROM events"); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Concurrent statement failed: %v", err)
+	}
+	if n := countRows(t, db, "SELECT COUNT(*) FROM events"); n != 80 {
+		t.Errorf("Expected 80 events, got %d", n)
+	}
+}
+
+func TestConnection_ReadsDoNotWaitForWriter(t *testing.T) {
+	db := openWithOptions(t, filepath.Join(t.TempDir(), "reads.db"), models.DatabaseOptions{Connection: models.ConnectionOptions{JournalMode: "WAL"}})
+	if _, err := db.Execute("CREATE TABLE events (id INTEGER PRIMARY KEY, source TEXT)"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, err := db.Execute("INSERT INTO events (source) VALUES ('a'), ('b')"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// Hold the writer connection in an open write transaction
+	ctx := context.Background()
+	conn, err := db.db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		t.Fatalf("BEGIN failed: %v", err)
+	}
+	defer conn.ExecContext(ctx, "ROLLBACK")
+
+	reads := map[string]func() error{
+		"schema": func() error { _, err := db.GetSchema(); return err },
+		"profile": func() error {
+			_, err := db.ProfileTable("events", models.ProfileOptions{})
+			return err
+		},
+		"sample": func() error { _, err := db.SampleRows("events", models.SampleOptions{}); return err },
+		"dump":   func() error { _, err := db.Dump(io.Discard, models.DumpOptions{}); return err },
+	}
+	for name, read := range reads {
+		done := make(chan error, 1)
+		go func() { done <- read() }()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("%s failed: %v", name, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s waited for the writer connection", name)
+		}
+	}
+}
+
+func TestConnection_RejectsInvalidOptions(t *testing.T) {
+	for name, opts := range map[string]models.ConnectionOptions{
+		"journal mode":  {JournalMode: "fast"},
+		"synchronous":   {Synchronous: "sometimes"},
+		"negative":      {BusyTimeout: -1},
+		"missing value": {Pragmas: []string{"temp_store"}},
+		"injection":     {Pragmas: []string{"temp_store=1; DROP TABLE users"}},
+		"bad name":      {Pragmas: []string{"temp store=1"}},
+	} {
+		if _, err := connectionPragmas(opts, false); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// A read-only database keeps its journal mode
+	pragmas, err := connectionPragmas(models.ConnectionOptions{JournalMode: "WAL", ForeignKeys: true}, true)
+	if err != nil || len(pragmas) != 1 || pragmas[0] != "PRAGMA foreign_keys = ON" {
+		t.Errorf("Expected only the foreign_keys pragma, got %v (%v)", pragmas, err)
+	}
+}
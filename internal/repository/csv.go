@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+// ImportCSV streams a CSV file from an import directory into a table. Column
+// types of a new table are inferred from the leading rows, empty fields are
+// imported as NULL and rows with the wrong number of fields are rejected.
+func (s *SQLiteDB) ImportCSV(opts models.CSVImportOptions) (*models.ImportResult, error) {
+	s.logger.Debugf("Importing CSV %s into %s", opts.Path, opts.Table)
+
+	if opts.Table == "" {
+		return nil, fmt.Errorf("table name is required")
+	}
+	path, err := s.resolveImportPath(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		s.logger.Errorf("Failed to open %s: %v", path, err)
+		return nil, fmt.Errorf("failed to open %s", opts.Path)
+	}
+	defer file.Close()
+
+	decoded, err := newDecodingReader(file, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(decoded)
+	reader.FieldsPerRecord = -1
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
+
+	// Buffer the leading rows for type inference, then keep streaming
+	var header []string
+	var buffered []importRow
+	for len(buffered) < importInferenceRows {
+		row, err := readCSVRow(reader)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			s.logger.Errorf("Failed to read %s: %v", path, err)
+			return nil, fmt.Errorf("failed to read %s", opts.Path)
+		}
+		if header == nil && row.reject == "" {
+			if !opts.NoHeader {
+				header = make([]string, len(row.values))
+				for i, value := range row.values {
+					header[i] = value.(string)
+				}
+				continue
+			}
+			header = make([]string, len(row.values))
+			for i := range header {
+				header[i] = fmt.Sprintf("column_%d", i+1)
+			}
+		}
+		buffered = append(buffered, row)
+	}
+	if header == nil {
+		return nil, fmt.Errorf("%s has no rows", opts.Path)
+	}
+
+	names := importColumnNames(header)
+	columns := make([]models.ImportColumn, len(names))
+	for i, name := range names {
+		var values []any
+		for _, row := range buffered {
+			// Rows that will be rejected don't count
+			if row.reject == "" && len(row.values) == len(names) {
+				values = append(values, row.values[i])
+			}
+		}
+		columns[i] = models.ImportColumn{Name: name, Type: inferColumnType(values)}
+	}
+
+	prepare := func(row importRow) importRow {
+		if row.reject != "" {
+			return row
+		}
+		if len(row.values) != len(columns) {
+			row.reject = fmt.Sprintf("expected %d fields, got %d", len(columns), len(row.values))
+			return row
+		}
+		for i, value := range row.values {
+			if value == "" {
+				row.values[i] = nil
+			}
+		}
+		return row
+	}
+
+	next := func() (importRow, error) {
+		if len(buffered) > 0 {
+			row := buffered[0]
+			buffered = buffered[1:]
+			return prepare(row), nil
+		}
+		row, err := readCSVRow(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.logger.Errorf("Failed to read %s: %v", path, err)
+				err = fmt.Errorf("failed to read %s", opts.Path)
+			}
+			return importRow{}, err
+		}
+		return prepare(row), nil
+	}
+
+	return s.importRows(opts.Table, columns, next, opts.BatchSize)
+}
+
+// readCSVRow reads the next record. Malformed records are returned as rejected
+// rows, only I/O errors and io.EOF are returned as errors.
+func readCSVRow(reader *csv.Reader) (importRow, error) {
+	record, err := reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return importRow{line: parseErr.StartLine, reject: parseErr.Err.Error()}, nil
+	}
+	if err != nil {
+		return importRow{}, err
+	}
+
+	line, _ := reader.FieldPos(0)
+	values := make([]any, len(record))
+	for i, field := range record {
+		values[i] = field
+	}
+	return importRow{line: line, values: values}, nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func writeImportFile(t *testing.T, db *SQLiteDB, name, content string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	db.SetImportDirs([]string{dir})
+	return dir
+}
+
+func TestImportCSV_CreatesTable(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	writeImportFile(t, db, "people.csv", "\xef\xbb\xbfname,age,score,zip,\n"+
+		"Ann,31,4.5,02134,x\n"+
+		"Bob,,3,10001,y\n"+
+		"broken,row\n"+
+		"\"Cy, Jr.\",40,5,94105,z\n")
+
+	result, err := db.ImportCSV(models.CSVImportOptions{Path: "people.csv", Table: "people", BatchSize: 2})
+	if err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+
+	if !result.Created || result.RowsInserted != 3 || result.RowsRejected != 1 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if result.Rejected[0].Line != 4 || !strings.Contains(result.Rejected[0].Reason, "expected 5 fields") {
+		t.Errorf("Unexpected rejection: %+v", result.Rejected[0])
+	}
+
+	var types []string
+	for _, col := range result.Columns {
+		types = append(types, col.Name+" "+col.Type)
+	}
+	if got := strings.Join(types, ", "); got != "name TEXT, age INTEGER, score REAL, zip TEXT, column_5 TEXT" {
+		t.Errorf("Unexpected columns: %s", got)
+	}
+
+	rows, err := db.Query("SELECT name, age, zip FROM people ORDER BY name")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if rows.Rows[0]["age"] != int64(31) || rows.Rows[1]["age"] != nil || rows.Rows[0]["zip"] != "02134" || rows.Rows[2]["name"] != "Cy, Jr." {
+		t.Errorf("Unexpected rows: %v", rows.Rows)
+	}
+}
+
+func TestImportCSV_ExistingTable(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	writeImportFile(t, db, "users.tsv", "EMAIL\tname\n"+
+		"a@example.com\tAnn\n"+
+		"a@example.com\tDuplicate\n"+
+		"b@example.com\t\n")
+
+	result, err := db.ImportCSV(models.CSVImportOptions{Path: "users.tsv", Table: "test_users", Delimiter: '\t'})
+	if err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+	if result.Created || result.RowsInserted != 1 || result.RowsRejected != 2 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if result.Columns[0].Name != "email" {
+		t.Errorf("Expected header to map onto the table column, got %+v", result.Columns)
+	}
+	for _, rejected := range result.Rejected {
+		if !strings.Contains(rejected.Reason, "constraint failed") {
+			t.Errorf("Expected constraint violation, got %+v", rejected)
+		}
+	}
+
+	writeImportFile(t, db, "bad.csv", "nope\n1\n")
+	if _, err := db.ImportCSV(models.CSVImportOptions{Path: "bad.csv", Table: "test_users"}); err == nil || !strings.Contains(err.Error(), "column nope does not exist") {
+		t.Errorf("Expected unknown column error, got %v", err)
+	}
+}
+
+func TestImportCSV_OptionsAndRestrictions(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	dir := writeImportFile(t, db, "latin1.csv", "caf\xe9;1\nna\xefve;2\n")
+
+	result, err := db.ImportCSV(models.CSVImportOptions{Path: "latin1.csv", Table: "words", Delimiter: ';', NoHeader: true, Encoding: "latin1"})
+	if err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+	if result.RowsInserted != 2 || result.Columns[0].Name != "column_1" {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	rows, err := db.Query("SELECT column_1 FROM words ORDER BY column_2")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if rows.Rows[0]["column_1"] != "café" || rows.Rows[1]["column_1"] != "naïve" {
+		t.Errorf("Expected latin1 to be decoded, got %v", rows.Rows)
+	}
+
+	outside := filepath.Join(filepath.Dir(dir), "outside.csv")
+	if err := os.WriteFile(outside, []byte("a\n1\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	defer os.Remove(outside)
+
+	for _, path := range []string{outside, "../outside.csv", "missing.csv"} {
+		if _, err := db.ImportCSV(models.CSVImportOptions{Path: path, Table: "outside"}); err == nil {
+			t.Errorf("Expected %s to be rejected", path)
+		}
+	}
+
+	if _, err := db.ImportCSV(models.CSVImportOptions{Path: "latin1.csv", Table: "_mcp_annotations"}); err == nil {
+		t.Error("Expected import into internal table to be rejected")
+	}
+
+	db.SetImportDirs(nil)
+	if _, err := db.ImportCSV(models.CSVImportOptions{Path: filepath.Join(dir, "latin1.csv"), Table: "words"}); err == nil {
+		t.Error("Expected imports to be disabled without import directories")
+	}
+}
+
+func TestInferColumnType(t *testing.T) {
+	cases := []struct {
+		values   []any
+		expected string
+	}{
+		{[]any{"1", "2", ""}, "INTEGER"},
+		{[]any{"1", "2.5"}, "REAL"},
+		{[]any{"1", "abc", "2.5"}, "TEXT"},
+		{[]any{"007"}, "TEXT"},
+		{[]any{"0.5", "0"}, "REAL"},
+		{[]any{nil, ""}, "TEXT"},
+		{[]any{int64(1), float64(2)}, "REAL"},
+	}
+	for _, c := range cases {
+		if got := inferColumnType(c.values); got != c.expected {
+			t.Errorf("inferColumnType(%v) = %s, expected %s", c.values, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,388 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+const (
+	defaultDataDiffLimit = 20
+	maxDataDiffLimit     = 1000
+
+	// dataDiffSchema is the name the other database is attached under
+	dataDiffSchema = "_mcp_diff"
+)
+
+type diffColumn struct {
+	Name string
+	PK   int // Position in the primary key, 0 when not part of it
+}
+
+// DiffDataWithFile compares the rows of this database with another database
+// file attached read-only on a dedicated connection. Tables are matched by name
+// and rows by primary key; tables without a shared primary key are matched on
+// the full content of their common columns, so changes show up as a deletion
+// plus an insertion.
+func (s *SQLiteDB) DiffDataWithFile(otherPath string, opts models.DataDiffOptions) (*models.DataDiff, error) {
+	s.logger.Debugf("Diffing data of %s against %s", s.path, otherPath)
+
+	if _, err := os.Stat(otherPath); err != nil {
+		return nil, fmt.Errorf("database file %s does not exist", otherPath)
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultDataDiffLimit
+	}
+	if opts.Limit > maxDataDiffLimit {
+		opts.Limit = maxDataDiffLimit
+	}
+
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to get connection: %v", err)
+		return nil, fmt.Errorf("failed to compare data")
+	}
+	defer conn.Close()
+
+	if err := s.attachDatabase(ctx, conn, otherPath, dataDiffSchema); err != nil {
+		s.logger.Errorf("Failed to attach database %s: %v", otherPath, err)
+		return nil, fmt.Errorf("failed to open database %s", otherPath)
+	}
+	defer func() {
+		if err := s.detachDatabase(ctx, conn, dataDiffSchema); err != nil {
+			s.logger.Errorf("Failed to detach database %s: %v", otherPath, err)
+		}
+	}()
+
+	diff := &models.DataDiff{Source: s.path, Target: otherPath}
+	sourceSchema, targetSchema := "main", dataDiffSchema
+	if opts.FromOther {
+		diff.Source, diff.Target = diff.Target, diff.Source
+		sourceSchema, targetSchema = targetSchema, sourceSchema
+	}
+
+	sourceTables, err := diffTables(ctx, conn, sourceSchema)
+	if err != nil {
+		s.logger.Errorf("Failed to list tables of %s: %v", diff.Source, err)
+		return nil, fmt.Errorf("failed to retrieve tables of %s", diff.Source)
+	}
+	targetTables, err := diffTables(ctx, conn, targetSchema)
+	if err != nil {
+		s.logger.Errorf("Failed to list tables of %s: %v", diff.Target, err)
+		return nil, fmt.Errorf("failed to retrieve tables of %s", diff.Target)
+	}
+
+	names := make(map[string]bool)
+	for name := range sourceTables {
+		names[name] = true
+	}
+	for name := range targetTables {
+		names[name] = true
+	}
+	if opts.Table != "" {
+		if !names[opts.Table] {
+			return nil, fmt.Errorf("table %s does not exist", opts.Table)
+		}
+		names = map[string]bool{opts.Table: true}
+	}
+
+	d := dataDiffer{conn: conn, limit: opts.Limit, source: sourceSchema, target: targetSchema}
+	for _, name := range sortedKeys(names) {
+		sourceColumns, inSource := sourceTables[name]
+		targetColumns, inTarget := targetTables[name]
+		switch {
+		case !inSource:
+			diff.SkippedTables = append(diff.SkippedTables, models.SkippedTable{Table: name, Reason: "only in target"})
+			continue
+		case !inTarget:
+			diff.SkippedTables = append(diff.SkippedTables, models.SkippedTable{Table: name, Reason: "only in source"})
+			continue
+		}
+
+		tableDiff, err := d.diffTable(ctx, name, sourceColumns, targetColumns)
+		if err != nil {
+			s.logger.Errorf("Failed to diff table %s: %v", name, err)
+			return nil, fmt.Errorf("failed to compare data of table %s", name)
+		}
+		if tableDiff == nil {
+			diff.SkippedTables = append(diff.SkippedTables, models.SkippedTable{Table: name, Reason: "no common columns"})
+			continue
+		}
+		// Tables without changes are left out, their notes alone are noise
+		if tableDiff.Inserted+tableDiff.Deleted+tableDiff.Updated > 0 {
+			diff.Tables = append(diff.Tables, *tableDiff)
+		}
+	}
+
+	diff.Identical = len(diff.SkippedTables) == 0 && len(diff.Tables) == 0
+
+	s.logger.Infof("Data diff computed, identical: %t, changed_tables: %d", diff.Identical, len(diff.Tables))
+	return diff, nil
+}
+
+// diffTables lists the user tables of a schema with their columns
+func diffTables(ctx context.Context, conn *sql.Conn, schema string) (map[string][]diffColumn, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(`SELECT m.name, c.name, c.pk
+		FROM %s.sqlite_master m, pragma_table_info(m.name, ?) c
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%%' AND m.name NOT LIKE '\_mcp\_%%' ESCAPE '\'
+		ORDER BY m.name, c.cid`, quoteIdent(schema)), schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make(map[string][]diffColumn)
+	for rows.Next() {
+		var table string
+		var col diffColumn
+		if err := rows.Scan(&table, &col.Name, &col.PK); err != nil {
+			return nil, err
+		}
+		tables[table] = append(tables[table], col)
+	}
+	return tables, rows.Err()
+}
+
+type dataDiffer struct {
+	conn           *sql.Conn
+	limit          int
+	source, target string // Schema names
+}
+
+func (d dataDiffer) diffTable(ctx context.Context, table string, sourceColumns, targetColumns []diffColumn) (*models.TableDataDiff, error) {
+	columns, notes := commonColumns(sourceColumns, targetColumns)
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	result := &models.TableDataDiff{Table: table, Columns: columns}
+	key := sharedPrimaryKey(sourceColumns, targetColumns, columns)
+	if key == nil {
+		key = columns
+		notes = append(notes, "no common primary key, rows matched on all common columns")
+	} else {
+		result.Key = key
+	}
+	result.Note = strings.Join(notes, "; ")
+
+	src := quoteIdent(d.source) + "." + quoteIdent(table)
+	dst := quoteIdent(d.target) + "." + quoteIdent(table)
+	selectList := qualifiedColumns("a", columns)
+	orderBy := strings.Join(qualifiedColumns("a", key), ", ")
+	match := matchCondition("a", "b", key)
+
+	var err error
+	missing := fmt.Sprintf("FROM %%s a WHERE NOT EXISTS (SELECT 1 FROM %%s b WHERE %s)", match)
+	if result.Inserted, result.InsertedRows, err = d.rows(ctx, fmt.Sprintf(missing, dst, src), selectList, orderBy, columns); err != nil {
+		return nil, err
+	}
+	if result.Deleted, result.DeletedRows, err = d.rows(ctx, fmt.Sprintf(missing, src, dst), selectList, orderBy, columns); err != nil {
+		return nil, err
+	}
+	if result.Key != nil {
+		if result.Updated, result.UpdatedRows, err = d.updates(ctx, src, dst, key, columns); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Truncated = int(result.Inserted) > len(result.InsertedRows) ||
+		int(result.Deleted) > len(result.DeletedRows) ||
+		int(result.Updated) > len(result.UpdatedRows)
+
+	return result, nil
+}
+
+// rows counts the rows matched by from and returns up to limit of them
+func (d dataDiffer) rows(ctx context.Context, from string, selectList []string, orderBy string, columns []string) (int64, []map[string]any, error) {
+	var count int64
+	if err := d.conn.QueryRowContext(ctx, "SELECT COUNT(*) "+from).Scan(&count); err != nil {
+		return 0, nil, err
+	}
+	if count == 0 {
+		return 0, nil, nil
+	}
+
+	values, err := d.query(ctx, fmt.Sprintf("SELECT %s %s ORDER BY %s LIMIT ?", strings.Join(selectList, ", "), from, orderBy), len(columns))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rows := make([]map[string]any, len(values))
+	for i, row := range values {
+		rows[i] = make(map[string]any, len(columns))
+		for j, col := range columns {
+			rows[i][col] = row[j]
+		}
+	}
+	return count, rows, nil
+}
+
+// updates counts rows present in both tables whose non-key columns differ and
+// returns up to limit of them with the changed values
+func (d dataDiffer) updates(ctx context.Context, src, dst string, key, columns []string) (int64, []models.RowChange, error) {
+	var valueColumns []string
+	for _, col := range columns {
+		if !containsFold(key, col) {
+			valueColumns = append(valueColumns, col)
+		}
+	}
+	if len(valueColumns) == 0 {
+		return 0, nil, nil
+	}
+
+	from := fmt.Sprintf("FROM %s a JOIN %s b ON %s WHERE NOT (%s)", src, dst, matchCondition("a", "b", key), matchCondition("a", "b", valueColumns))
+
+	var count int64
+	if err := d.conn.QueryRowContext(ctx, "SELECT COUNT(*) "+from).Scan(&count); err != nil {
+		return 0, nil, err
+	}
+	if count == 0 {
+		return 0, nil, nil
+	}
+
+	selectList := qualifiedColumns("a", key)
+	for _, col := range valueColumns {
+		selectList = append(selectList, "a."+quoteIdent(col), "b."+quoteIdent(col))
+	}
+	query := fmt.Sprintf("SELECT %s %s ORDER BY %s LIMIT ?", strings.Join(selectList, ", "), from, strings.Join(qualifiedColumns("a", key), ", "))
+	values, err := d.query(ctx, query, len(selectList))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	changes := make([]models.RowChange, len(values))
+	for i, row := range values {
+		change := models.RowChange{Key: make(map[string]any, len(key))}
+		for j, col := range key {
+			change.Key[col] = row[j]
+		}
+		for j, col := range valueColumns {
+			from, to := row[len(key)+2*j], row[len(key)+2*j+1]
+			if !reflect.DeepEqual(from, to) {
+				change.Changes = append(change.Changes, models.ValueChange{Column: col, From: from, To: to})
+			}
+		}
+		changes[i] = change
+	}
+	return count, changes, nil
+}
+
+func (d dataDiffer) query(ctx context.Context, query string, width int) ([][]any, error) {
+	rows, err := d.conn.QueryContext(ctx, query, d.limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result [][]any
+	for rows.Next() {
+		values := make([]any, width)
+		valuePtrs := make([]any, width)
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, err
+		}
+		for i := range values {
+			values[i] = normalizeValue(values[i])
+		}
+		result = append(result, values)
+	}
+	return result, rows.Err()
+}
+
+// commonColumns returns the source columns that also exist in the target, plus
+// notes on columns that only exist on one side
+func commonColumns(source, target []diffColumn) ([]string, []string) {
+	var common, onlySource, onlyTarget []string
+	for _, col := range source {
+		if findDiffColumn(target, col.Name) != nil {
+			common = append(common, col.Name)
+		} else {
+			onlySource = append(onlySource, col.Name)
+		}
+	}
+	for _, col := range target {
+		if findDiffColumn(source, col.Name) == nil {
+			onlyTarget = append(onlyTarget, col.Name)
+		}
+	}
+
+	var notes []string
+	if len(onlySource) > 0 {
+		notes = append(notes, "columns only in source: "+strings.Join(onlySource, ", "))
+	}
+	if len(onlyTarget) > 0 {
+		notes = append(notes, "columns only in target: "+strings.Join(onlyTarget, ", "))
+	}
+	return common, notes
+}
+
+// sharedPrimaryKey returns the primary key columns when both tables declare the
+// same primary key over common columns, nil otherwise
+func sharedPrimaryKey(source, target []diffColumn, common []string) []string {
+	var key []diffColumn
+	for _, col := range source {
+		if col.PK > 0 {
+			key = append(key, col)
+		}
+	}
+	if len(key) == 0 {
+		return nil
+	}
+	sort.Slice(key, func(i, j int) bool { return key[i].PK < key[j].PK })
+
+	targetKeys := 0
+	for _, col := range target {
+		if col.PK > 0 {
+			targetKeys++
+		}
+	}
+	if targetKeys != len(key) {
+		return nil
+	}
+
+	names := make([]string, len(key))
+	for i, col := range key {
+		other := findDiffColumn(target, col.Name)
+		if other == nil || other.PK != col.PK || !containsFold(common, col.Name) {
+			return nil
+		}
+		names[i] = col.Name
+	}
+	return names
+}
+
+func findDiffColumn(columns []diffColumn, name string) *diffColumn {
+	for i := range columns {
+		if strings.EqualFold(columns[i].Name, name) {
+			return &columns[i]
+		}
+	}
+	return nil
+}
+
+func qualifiedColumns(alias string, columns []string) []string {
+	qualified := make([]string, len(columns))
+	for i, col := range columns {
+		qualified[i] = alias + "." + quoteIdent(col)
+	}
+	return qualified
+}
+
+// matchCondition compares columns of two aliases, treating NULLs as equal
+func matchCondition(left, right string, columns []string) string {
+	conditions := make([]string, len(columns))
+	for i, col := range columns {
+		conditions[i] = fmt.Sprintf("%s.%s IS %s.%s", left, quoteIdent(col), right, quoteIdent(col))
+	}
+	return strings.Join(conditions, " AND ")
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func setupDataDiffDBs(t *testing.T) (*SQLiteDB, *SQLiteDB) {
+	t.Helper()
+
+	source := newTestDBWithSchema(t, "yesterday.db",
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT)`,
+		`CREATE TABLE tags (label TEXT)`,
+		`CREATE TABLE legacy (id INTEGER PRIMARY KEY)`,
+		`INSERT INTO users VALUES (1, 'John', 'john@example.com'), (2, 'Jane', 'jane@example.com'), (3, 'Bob', NULL)`,
+		`INSERT INTO tags VALUES ('red'), ('blue')`,
+	)
+	target := newTestDBWithSchema(t, "today.db",
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT)`,
+		`CREATE TABLE tags (label TEXT)`,
+		`INSERT INTO users VALUES (1, 'John', 'john@example.com'), (3, 'Bob', 'bob@example.com'), (4, 'Alice', NULL)`,
+		`INSERT INTO tags VALUES ('red'), ('green')`,
+	)
+	return source, target
+}
+
+func TestDiffDataWithFile(t *testing.T) {
+	source, target := setupDataDiffDBs(t)
+
+	diff, err := source.DiffDataWithFile(target.Path(), models.DataDiffOptions{})
+	if err != nil {
+		t.Fatalf("DiffDataWithFile failed: %v", err)
+	}
+
+	if diff.Identical {
+		t.Fatal("Expected data to differ")
+	}
+	if len(diff.SkippedTables) != 1 || diff.SkippedTables[0].Table != "legacy" || diff.SkippedTables[0].Reason != "only in source" {
+		t.Errorf("Expected legacy to be skipped, got %+v", diff.SkippedTables)
+	}
+	if len(diff.Tables) != 2 {
+		t.Fatalf("Expected 2 changed tables, got %+v", diff.Tables)
+	}
+
+	tags := diff.Tables[0]
+	if tags.Table != "tags" || tags.Key != nil || tags.Inserted != 1 || tags.Deleted != 1 || tags.Updated != 0 {
+		t.Errorf("Unexpected tags diff: %+v", tags)
+	}
+
+	users := diff.Tables[1]
+	if users.Inserted != 1 || users.Deleted != 1 || users.Updated != 1 {
+		t.Fatalf("Expected 1 inserted, 1 deleted and 1 updated user, got %+v", users)
+	}
+	if users.InsertedRows[0]["name"] != "Alice" || users.DeletedRows[0]["name"] != "Jane" {
+		t.Errorf("Unexpected inserted/deleted rows: %v / %v", users.InsertedRows, users.DeletedRows)
+	}
+
+	update := users.UpdatedRows[0]
+	if update.Key["id"] != int64(3) || len(update.Changes) != 1 {
+		t.Fatalf("Unexpected update: %+v", update)
+	}
+	if change := update.Changes[0]; change.Column != "email" || change.From != nil || change.To != "bob@example.com" {
+		t.Errorf("Unexpected change: %+v", change)
+	}
+}
+
+func TestDiffDataWithFile_Options(t *testing.T) {
+	source, target := setupDataDiffDBs(t)
+
+	diff, err := source.DiffDataWithFile(target.Path(), models.DataDiffOptions{Table: "users", Limit: 1, FromOther: true})
+	if err != nil {
+		t.Fatalf("DiffDataWithFile failed: %v", err)
+	}
+	if len(diff.Tables) != 1 || len(diff.SkippedTables) != 0 {
+		t.Fatalf("Expected only users to be compared, got %+v", diff)
+	}
+
+	users := diff.Tables[0]
+	if users.InsertedRows[0]["name"] != "Jane" || users.DeletedRows[0]["name"] != "Alice" {
+		t.Errorf("Expected direction to be reversed, got %v / %v", users.InsertedRows, users.DeletedRows)
+	}
+
+	if _, err := source.DiffDataWithFile(target.Path(), models.DataDiffOptions{Table: "missing"}); err == nil {
+		t.Error("Expected error for missing table")
+	}
+
+	// The attachment must not outlive the diff
+	var attached int
+	if err := source.db.QueryRow("SELECT COUNT(*) FROM pragma_database_list WHERE name = ?", dataDiffSchema).Scan(&attached); err != nil {
+		t.Fatalf("Failed to list databases: %v", err)
+	}
+	if attached != 0 {
+		t.Error("Expected other database to be detached")
+	}
+}
+
+func TestDiffDataWithFile_Identical(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	diff, err := db.DiffDataWithFile(db.Path(), models.DataDiffOptions{})
+	if err != nil {
+		t.Fatalf("DiffDataWithFile failed: %v", err)
+	}
+	if !diff.Identical {
+		t.Errorf("Expected a database to be identical to itself, got %+v", diff)
+	}
+}
+
+func TestDiffDataWithFile_UnchangedTableWithNote(t *testing.T) {
+	source := newTestDBWithSchema(t, "source.db",
+		`CREATE TABLE tags (label TEXT, color TEXT)`,
+		`INSERT INTO tags VALUES ('red', 'r')`,
+	)
+	target := newTestDBWithSchema(t, "target.db",
+		`CREATE TABLE tags (label TEXT)`,
+		`INSERT INTO tags VALUES ('red')`,
+	)
+
+	diff, err := source.DiffDataWithFile(target.Path(), models.DataDiffOptions{})
+	if err != nil {
+		t.Fatalf("DiffDataWithFile failed: %v", err)
+	}
+	if !diff.Identical || len(diff.Tables) != 0 {
+		t.Errorf("Expected a table without changes to be left out despite its notes, got %+v", diff)
+	}
+}
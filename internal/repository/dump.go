@@ -0,0 +1,544 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+// Dump writes the database as SQL text in the format of the sqlite3 shell's
+// .dump command: table definitions with their rows as INSERT statements,
+// sqlite_sequence, then views, triggers and indexes, all inside one
+// transaction. The dump is read from a single snapshot. With table patterns
+// only matching tables and the objects belonging to them are dumped.
+func (s *SQLiteDB) Dump(w io.Writer, opts models.DumpOptions) (*models.DumpResult, error) {
+	s.logger.Debugf("Dumping database, tables: %v", opts.Tables)
+
+	ctx := context.Background()
+	conn, err := s.reader.Conn(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to get connection: %v", err)
+		return nil, fmt.Errorf("failed to dump database")
+	}
+	defer conn.Close()
+
+	// A read transaction keeps the schema and rows consistent with each other
+	if _, err := conn.ExecContext(ctx, "BEGIN"); err != nil {
+		s.logger.Errorf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to dump database")
+	}
+	defer conn.ExecContext(ctx, "ROLLBACK")
+
+	objects, err := dumpObjects(ctx, conn, opts.Tables)
+	if err != nil {
+		s.logger.Errorf("Failed to read schema: %v", err)
+		return nil, fmt.Errorf("failed to dump database")
+	}
+
+	counter := &countingWriter{w: w}
+	out := bufio.NewWriter(counter)
+	result := &models.DumpResult{}
+	var dumped []string
+
+	out.WriteString("PRAGMA foreign_keys=OFF;\nBEGIN TRANSACTION;\n")
+	for _, obj := range objects {
+		if obj.objType != "table" || obj.name == "sqlite_sequence" {
+			continue
+		}
+		fmt.Fprintf(out, "%s;\n", obj.sql)
+		rows, err := dumpRows(ctx, conn, out, obj.name, "")
+		if err != nil {
+			s.logger.Errorf("Failed to dump table %s: %v", obj.name, err)
+			return nil, fmt.Errorf("failed to dump table %s: %w", obj.name, err)
+		}
+		result.Tables++
+		result.Rows += rows
+		dumped = append(dumped, obj.name)
+	}
+
+	// sqlite_sequence is only listed when the database has AUTOINCREMENT tables
+	for _, obj := range objects {
+		if obj.name != "sqlite_sequence" {
+			continue
+		}
+		filter := ""
+		if len(opts.Tables) > 0 {
+			quoted := make([]string, len(dumped))
+			for i, name := range dumped {
+				quoted[i] = sqlLiteral(name)
+			}
+			filter = fmt.Sprintf(" WHERE name IN (%s)", strings.Join(quoted, ","))
+		}
+		out.WriteString("DELETE FROM sqlite_sequence;\n")
+		if _, err := dumpRows(ctx, conn, out, "sqlite_sequence", filter); err != nil {
+			s.logger.Errorf("Failed to dump sqlite_sequence: %v", err)
+			return nil, fmt.Errorf("failed to dump table sqlite_sequence")
+		}
+	}
+
+	for _, obj := range objects {
+		if obj.objType == "table" {
+			continue
+		}
+		fmt.Fprintf(out, "%s;\n", obj.sql)
+		result.Objects++
+	}
+	out.WriteString("COMMIT;\n")
+
+	if err := out.Flush(); err != nil {
+		return nil, err
+	}
+	result.Bytes = counter.n
+
+	s.logger.Infof("Dumped %d tables with %d rows (%d bytes)", result.Tables, result.Rows, result.Bytes)
+	return result, nil
+}
+
+// DumpToFile writes a dump to a file in the export directory
+func (s *SQLiteDB) DumpToFile(path string, overwrite bool, opts models.DumpOptions) (*models.DumpResult, error) {
+	resolved, err := s.resolveExportPath(path, overwrite)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *models.DumpResult
+	size, err := writeFileAtomically(resolved, func(w io.Writer) error {
+		var err error
+		result, err = s.Dump(w, opts)
+		return err
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to write dump to %s: %v", resolved, err)
+		return nil, fmt.Errorf("failed to write dump to %s: %v", path, err)
+	}
+
+	result.Path = resolved
+	result.Bytes = size
+	return result, nil
+}
+
+// LoadDump replays a SQL dump, such as one written by Dump or the sqlite3
+// shell's .dump, into an empty database. Statements run one by one on a
+// single connection, so the dump's own transaction applies; on failure the
+// transaction is rolled back and the error names the line of the statement.
+func (s *SQLiteDB) LoadDump(r io.Reader) (*models.LoadResult, error) {
+	s.logger.Debug("Loading dump")
+
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to get connection: %v", err)
+		return nil, fmt.Errorf("failed to load dump")
+	}
+	defer conn.Close()
+	restore, err := s.clientAuthorizer(conn)
+	if err != nil {
+		s.logger.Errorf("Failed to set the authorizer: %v", err)
+		return nil, fmt.Errorf("failed to load dump")
+	}
+	defer restore()
+
+	var objects, foreignKeys int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_schema").Scan(&objects); err != nil {
+		s.logger.Errorf("Failed to read schema: %v", err)
+		return nil, fmt.Errorf("failed to load dump")
+	}
+	if objects > 0 {
+		return nil, fmt.Errorf("database is not empty, a dump can only be loaded into a fresh database")
+	}
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		s.logger.Errorf("Failed to read foreign_keys: %v", err)
+		return nil, fmt.Errorf("failed to load dump")
+	}
+	// Dumps turn foreign key enforcement off, restore the connection's setting
+	defer conn.ExecContext(ctx, fmt.Sprintf("PRAGMA foreign_keys=%d", foreignKeys))
+
+	// sqlite3 shells from 3.50 write control characters with unistr(), which
+	// older SQLite libraries lack
+	var hasUnistr bool
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM pragma_function_list WHERE name = 'unistr'").Scan(&hasUnistr); err == nil && !hasUnistr {
+		err := conn.Raw(func(driverConn any) error {
+			if c, ok := driverConn.(*sqlite3.SQLiteConn); ok {
+				return c.RegisterFunc("unistr", unistr, true)
+			}
+			return nil
+		})
+		if err != nil {
+			s.logger.Warnf("Failed to register unistr: %v", err)
+		}
+	}
+
+	result := &models.LoadResult{}
+	if result.Statements, err = s.execScript(ctx, conn, r, "dump"); err != nil {
+		return nil, err
+	}
+	result.Tables = s.countTables(ctx, conn)
+
+	s.logger.Infof("Loaded dump with %d statements, %d tables", result.Statements, result.Tables)
+	return result, nil
+}
+
+// execScript runs the statements of a SQL script one by one on conn and
+// returns how many ran. A transaction the script leaves open, because it
+// failed or was truncated, is rolled back.
+func (s *SQLiteDB) execScript(ctx context.Context, conn *sql.Conn, r io.Reader, kind string) (int, error) {
+	rollback := func() {
+		if !connAutoCommit(conn) {
+			conn.ExecContext(ctx, "ROLLBACK")
+		}
+	}
+
+	count := 0
+	statements := newSQLStatementReader(r)
+	for {
+		statement, line, err := statements.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rollback()
+			return count, fmt.Errorf("failed to read %s: %v", kind, err)
+		}
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			rollback()
+			s.logger.Errorf("Statement at line %d of %s failed: %v", line, kind, err)
+			return count, fmt.Errorf("statement at line %d failed: %v", line, err)
+		}
+		count++
+	}
+	if !connAutoCommit(conn) {
+		rollback()
+		return count, fmt.Errorf("%s ended inside a transaction, it may be truncated", kind)
+	}
+	return count, nil
+}
+
+// countTables counts the user tables, including the server's _mcp_ tables
+func (s *SQLiteDB) countTables(ctx context.Context, conn *sql.Conn) int {
+	var tables int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_schema WHERE type = 'table' AND name NOT LIKE 'sqlite\\_%' ESCAPE '\\'").Scan(&tables); err != nil {
+		s.logger.Errorf("Failed to count tables: %v", err)
+	}
+	return tables
+}
+
+type dumpObject struct {
+	objType string
+	name    string
+	sql     string
+}
+
+// dumpObjects returns the schema objects to dump, skipping
+// internal SQLite tables and the shadow tables of virtual tables
+func dumpObjects(ctx context.Context, conn *sql.Conn, patterns []string) ([]dumpObject, error) {
+	query := `SELECT type, name, sql FROM sqlite_schema
+		WHERE sql IS NOT NULL
+		AND (name = 'sqlite_sequence' OR name NOT LIKE 'sqlite\_%' ESCAPE '\')
+		AND name NOT IN (SELECT name FROM pragma_table_list WHERE schema = 'main' AND type = 'shadow')`
+	var args []any
+	if len(patterns) > 0 {
+		conditions := make([]string, len(patterns))
+		for i, pattern := range patterns {
+			conditions[i] = "tbl_name LIKE ?"
+			args = append(args, pattern)
+		}
+		// sqlite_sequence entries are filtered by the dumped tables instead
+		query += fmt.Sprintf(" AND (name = 'sqlite_sequence' OR %s)", strings.Join(conditions, " OR "))
+	}
+	// Tables first, then views, triggers and indexes like the sqlite3 shell, so
+	// INSTEAD OF triggers find their views
+	query += " ORDER BY type = 'table' DESC, type COLLATE NOCASE DESC, rowid"
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []dumpObject
+	for rows.Next() {
+		var obj dumpObject
+		if err := rows.Scan(&obj.objType, &obj.name, &obj.sql); err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+	return objects, rows.Err()
+}
+
+// dumpRows writes the rows of a table as INSERT statements. Values are
+// rendered by SQLite's quote(), so they round-trip exactly. Generated and
+// hidden columns are left out, naming the remaining columns explicitly.
+func dumpRows(ctx context.Context, conn *sql.Conn, w *bufio.Writer, table, filter string) (int64, error) {
+	columns, err := conn.QueryContext(ctx, "SELECT name, hidden FROM pragma_table_xinfo(?)", table)
+	if err != nil {
+		return 0, err
+	}
+	var names, quoted []string
+	hidden := false
+	for columns.Next() {
+		var name string
+		var hiddenKind int
+		if err := columns.Scan(&name, &hiddenKind); err != nil {
+			columns.Close()
+			return 0, err
+		}
+		if hiddenKind != 0 {
+			hidden = true
+			continue
+		}
+		names = append(names, quoteIdent(name))
+		quoted = append(quoted, fmt.Sprintf("quote(%s)", quoteIdent(name)))
+	}
+	columns.Close()
+	if err := columns.Err(); err != nil {
+		return 0, err
+	}
+
+	prefix := "INSERT INTO " + quoteIdent(table)
+	if table == "sqlite_sequence" {
+		prefix = "INSERT INTO sqlite_sequence"
+	}
+	if hidden {
+		prefix += "(" + strings.Join(names, ",") + ")"
+	}
+	prefix += " VALUES("
+
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s%s", strings.Join(quoted, " || ',' || "), quoteIdent(table), filter))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var count int64
+	for rows.Next() {
+		var values string
+		if err := rows.Scan(&values); err != nil {
+			return count, err
+		}
+		w.WriteString(prefix)
+		w.WriteString(values)
+		// Stop early once the underlying writer failed
+		if _, err := w.WriteString(");\n"); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+// unistr implements SQLite's unistr(): \XXXX, \uXXXX, \+XXXXXX and
+// \UXXXXXXXX escapes are replaced by the code point and \\ by a backslash
+func unistr(text string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' {
+			b.WriteByte(text[i])
+			continue
+		}
+
+		rest := text[i+1:]
+		digits, skip := 0, 1
+		switch {
+		case strings.HasPrefix(rest, "\\"):
+			b.WriteByte('\\')
+			i++
+			continue
+		case strings.HasPrefix(rest, "u"):
+			digits = 4
+		case strings.HasPrefix(rest, "+"):
+			digits = 6
+		case strings.HasPrefix(rest, "U"):
+			digits = 8
+		default:
+			digits, skip = 4, 0
+		}
+		if len(rest) < skip+digits {
+			return "", fmt.Errorf("invalid Unicode escape")
+		}
+		code, err := strconv.ParseUint(rest[skip:skip+digits], 16, 32)
+		if err != nil || code > utf8.MaxRune {
+			return "", fmt.Errorf("invalid Unicode escape")
+		}
+		b.WriteRune(rune(code))
+		i += skip + digits
+	}
+	return b.String(), nil
+}
+
+// connAutoCommit reports whether the connection is outside a transaction
+func connAutoCommit(conn *sql.Conn) bool {
+	autoCommit := true
+	conn.Raw(func(driverConn any) error {
+		if c, ok := driverConn.(*sqlite3.SQLiteConn); ok {
+			autoCommit = c.AutoCommit()
+		}
+		return nil
+	})
+	return autoCommit
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// sqlStatementReader splits SQL text into statements at semicolons outside
+// quotes and comments. Semicolons inside a CREATE TRIGGER body, which runs
+// from BEGIN to the matching END, do not end the statement.
+type sqlStatementReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func newSQLStatementReader(r io.Reader) *sqlStatementReader {
+	return &sqlStatementReader{r: bufio.NewReader(r), line: 1}
+}
+
+// next returns the next statement and the line it starts on, or io.EOF.
+// Text consisting only of whitespace and comments is skipped.
+func (sr *sqlStatementReader) next() (string, int, error) {
+	var b, word strings.Builder
+	var leading []string // The first keywords, to detect CREATE TRIGGER
+	startLine, depth := 0, 0
+
+	endWord := func() {
+		if word.Len() == 0 {
+			return
+		}
+		w := strings.ToUpper(word.String())
+		word.Reset()
+		if len(leading) < 3 {
+			leading = append(leading, w)
+		}
+		if isCreateTrigger(leading) {
+			switch w {
+			case "BEGIN", "CASE":
+				depth++
+			case "END":
+				depth--
+			}
+		}
+	}
+
+	for {
+		c, err := sr.r.ReadByte()
+		if errors.Is(err, io.EOF) {
+			if startLine == 0 {
+				return "", 0, io.EOF
+			}
+			// A final statement without a semicolon
+			return b.String(), startLine, nil
+		}
+		if err != nil {
+			return "", 0, err
+		}
+
+		if isWordChar(c) {
+			word.WriteByte(c)
+		} else {
+			endWord()
+		}
+
+		switch {
+		case c == '\n':
+			sr.line++
+		case c == '-' && sr.peek() == '-':
+			b.WriteByte(c)
+			if err := sr.copyUntil(&b, "\n"); err != nil {
+				return "", 0, err
+			}
+			continue
+		case c == '/' && sr.peek() == '*':
+			b.WriteByte(c)
+			if err := sr.copyUntil(&b, "*/"); err != nil {
+				return "", 0, err
+			}
+			continue
+		}
+
+		if startLine == 0 {
+			if isSpace(c) {
+				continue
+			}
+			startLine = sr.line
+		}
+		b.WriteByte(c)
+
+		switch c {
+		case '\'', '"', '`', '[':
+			closing := string(c)
+			if c == '[' {
+				closing = "]"
+			}
+			if err := sr.copyUntil(&b, closing); err != nil {
+				return "", 0, err
+			}
+		case ';':
+			if depth <= 0 {
+				return b.String(), startLine, nil
+			}
+		}
+	}
+}
+
+func (sr *sqlStatementReader) peek() byte {
+	next, err := sr.r.Peek(1)
+	if err != nil {
+		return 0
+	}
+	return next[0]
+}
+
+// copyUntil copies input up to and including end. Doubled quote characters
+// inside quoted tokens are copied as part of the token.
+func (sr *sqlStatementReader) copyUntil(b *strings.Builder, end string) error {
+	for {
+		c, err := sr.r.ReadByte()
+		if errors.Is(err, io.EOF) {
+			// Unterminated comments end the input; SQLite reports unterminated quotes
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if c == '\n' {
+			sr.line++
+		}
+		b.WriteByte(c)
+
+		if strings.HasSuffix(b.String(), end) && (len(end) > 1 || c == end[0]) {
+			if (end == "'" || end == `"` || end == "`") && sr.peek() == end[0] {
+				next, _ := sr.r.ReadByte()
+				b.WriteByte(next)
+				continue
+			}
+			return nil
+		}
+	}
+}
+
+func isCreateTrigger(leading []string) bool {
+	if len(leading) < 2 || leading[0] != "CREATE" {
+		return false
+	}
+	if leading[1] == "TRIGGER" {
+		return true
+	}
+	return len(leading) == 3 && (leading[1] == "TEMP" || leading[1] == "TEMPORARY") && leading[2] == "TRIGGER"
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func newDumpTestDB(t *testing.T) *SQLiteDB {
+	t.Helper()
+
+	return newTestDBWithSchema(t, "source.db",
+		`CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, score REAL, avatar BLOB,
+			name_upper TEXT GENERATED ALWAYS AS (upper(name)) VIRTUAL)`,
+		`CREATE TABLE "order items" (user_id INTEGER REFERENCES users(id), note TEXT)`,
+		`CREATE INDEX idx_users_name ON users(name)`,
+		`CREATE TABLE audit (message TEXT)`,
+		`CREATE TRIGGER users_audit AFTER INSERT ON users BEGIN
+			INSERT INTO audit VALUES (CASE WHEN NEW.score > 1 THEN 'high; score' ELSE 'low' END);
+		END`,
+		`CREATE VIEW user_names AS SELECT name FROM users`,
+		`INSERT INTO users (name, score, avatar) VALUES ('Ann', 2.5, X'00FF'), ('O''Brien', 1.0, NULL), ('multi
+line', NULL, 'text')`,
+		`INSERT INTO "order items" VALUES (1, 'first; item'), (2, NULL)`,
+	)
+}
+
+func TestDump_RoundTrip(t *testing.T) {
+	db := newDumpTestDB(t)
+
+	var dump bytes.Buffer
+	result, err := db.Dump(&dump, models.DumpOptions{})
+	if err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	if result.Tables != 3 || result.Rows != 8 || result.Objects != 3 || result.Bytes != int64(dump.Len()) {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	text := dump.String()
+	for _, expected := range []string{
+		"PRAGMA foreign_keys=OFF;\nBEGIN TRANSACTION;\n",
+		`INSERT INTO "users"("id","name","score","avatar") VALUES(1,'Ann',2.5,X'00FF');`,
+		`INSERT INTO "users"("id","name","score","avatar") VALUES(2,'O''Brien',1.0,NULL);`,
+		`INSERT INTO "order items" VALUES(1,'first; item');`,
+		"DELETE FROM sqlite_sequence;\nINSERT INTO sqlite_sequence VALUES('users',3);\n",
+		"CREATE VIEW user_names AS SELECT name FROM users;\nCREATE TRIGGER users_audit",
+		"CREATE INDEX idx_users_name ON users(name);\nCOMMIT;\n",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in dump:\n%s", expected, text)
+		}
+	}
+	if strings.Index(text, "CREATE INDEX") < strings.Index(text, "DELETE FROM sqlite_sequence") {
+		t.Error("Expected indexes after the table data")
+	}
+
+	loaded := newTestDBWithSchema(t, "loaded.db")
+	loadResult, err := loaded.LoadDump(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("LoadDump failed: %v", err)
+	}
+	if loadResult.Tables != 3 {
+		t.Errorf("Unexpected load result: %+v", loadResult)
+	}
+
+	var again bytes.Buffer
+	if _, err := loaded.Dump(&again, models.DumpOptions{}); err != nil {
+		t.Fatalf("Dump of loaded database failed: %v", err)
+	}
+	if again.String() != text {
+		t.Errorf("Expected identical dump after loading, got:\n%s\nwant:\n%s", again.String(), text)
+	}
+
+	// Triggers are created after the data, so loading did not fire them again
+	rows, err := loaded.Query("SELECT COUNT(*) AS n FROM audit")
+	if err != nil || rows.Rows[0]["n"] != int64(3) {
+		t.Errorf("Expected 3 audit rows, got %v (%v)", rows, err)
+	}
+}
+
+func TestDump_TableFilter(t *testing.T) {
+	db := newDumpTestDB(t)
+
+	var dump bytes.Buffer
+	result, err := db.Dump(&dump, models.DumpOptions{Tables: []string{"order%"}})
+	if err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	text := dump.String()
+	if result.Tables != 1 || result.Rows != 2 || result.Objects != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if strings.Contains(text, "CREATE TABLE users") || !strings.Contains(text, "DELETE FROM sqlite_sequence;\nCOMMIT;") {
+		t.Errorf("Unexpected filtered dump:\n%s", text)
+	}
+
+	dump.Reset()
+	if _, err := db.Dump(&dump, models.DumpOptions{Tables: []string{"users"}}); err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	for _, expected := range []string{"CREATE INDEX idx_users_name", "CREATE TRIGGER users_audit", "INSERT INTO sqlite_sequence VALUES('users',3);"} {
+		if !strings.Contains(dump.String(), expected) {
+			t.Errorf("Expected %q in dump:\n%s", expected, dump.String())
+		}
+	}
+}
+
+func TestLoadDump_Errors(t *testing.T) {
+	db := newDumpTestDB(t)
+	if _, err := db.LoadDump(strings.NewReader("CREATE TABLE x (a);")); err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Errorf("Expected not empty error, got %v", err)
+	}
+
+	fresh := newTestDBWithSchema(t, "fresh.db")
+	dump := "BEGIN TRANSACTION;\n-- comment; with semicolon\nCREATE TABLE a (x);\nINSERT INTO a VALUES(1);\nINSERT INTO missing VALUES(2);\nCOMMIT;\n"
+	if _, err := fresh.LoadDump(strings.NewReader(dump)); err == nil || !strings.Contains(err.Error(), "line 5") {
+		t.Errorf("Expected error at line 5, got %v", err)
+	}
+	if exists, _ := fresh.tableExists("a"); exists {
+		t.Error("Expected the failed load to be rolled back")
+	}
+
+	if _, err := fresh.LoadDump(strings.NewReader("BEGIN TRANSACTION;\nCREATE TABLE a (x);\n")); err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("Expected truncated dump error, got %v", err)
+	}
+}
+
+func TestSQLStatementReader(t *testing.T) {
+	input := `/* header */ SELECT 'a;b', "c;d", [e;f];
+-- only a comment;
+CREATE TEMP TRIGGER t AFTER INSERT ON x BEGIN SELECT CASE WHEN 1 THEN 'x' END; SELECT 2; END;
+
+SELECT 'it''s'`
+
+	reader := newSQLStatementReader(strings.NewReader(input))
+	var statements []string
+	var lines []int
+	for {
+		statement, line, err := reader.next()
+		if err != nil {
+			break
+		}
+		statements = append(statements, strings.TrimSpace(statement))
+		lines = append(lines, line)
+	}
+
+	if len(statements) != 3 {
+		t.Fatalf("Expected 3 statements, got %d: %q", len(statements), statements)
+	}
+	if !strings.HasSuffix(statements[0], `SELECT 'a;b', "c;d", [e;f];`) || !strings.HasSuffix(statements[1], "SELECT 2; END;") || !strings.HasSuffix(statements[2], "SELECT 'it''s'") {
+		t.Errorf("Unexpected statements: %q", statements)
+	}
+	if lines[0] != 1 || lines[1] != 3 || lines[2] != 5 {
+		t.Errorf("Unexpected lines: %v", lines)
+	}
+}
+
+func TestLoadDump_Unistr(t *testing.T) {
+	db := newTestDBWithSchema(t, "unistr.db")
+
+	dump := "CREATE TABLE a (x);\nINSERT INTO a VALUES(unistr('a\\u000ab\\\\c\\+01F600\\0041'));\n"
+	if _, err := db.LoadDump(strings.NewReader(dump)); err != nil {
+		t.Fatalf("LoadDump failed: %v", err)
+	}
+	rows, err := db.Query("SELECT x FROM a")
+	if err != nil || rows.Rows[0]["x"] != "a\nb\\c\U0001F600A" {
+		t.Errorf("Unexpected value %v (%v)", rows, err)
+	}
+
+	if _, err := unistr(`\u12`); err == nil {
+		t.Error("Expected an error for a short escape")
+	}
+}
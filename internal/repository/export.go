@@ -0,0 +1,408 @@
+package repository
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+var errNoExportDir = errors.New("no export directory is configured")
+
+// SetExportDir sets the directory export tools write files to
+func (s *SQLiteDB) SetExportDir(dir string) {
+	s.exportDir = dir
+}
+
+// ExportQuery streams the full result of a SELECT query to a file in the
+// export directory. The query runs on the read pool within the query timeout.
+func (s *SQLiteDB) ExportQuery(opts models.ExportOptions) (*models.ExportResult, error) {
+	s.logger.Debugf("Exporting query to %s: %s", opts.Path, sanitizeQuery(opts.SQL))
+
+	if !isSelectQuery(opts.SQL) {
+		return nil, fmt.Errorf("only SELECT queries can be exported")
+	}
+	if !isSingleStatement(opts.SQL) {
+		return nil, fmt.Errorf("only a single SELECT statement can be exported")
+	}
+	format := strings.ToLower(opts.Format)
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(opts.Path)), ".")
+		if format == "ndjson" {
+			format = models.ExportJSONL
+		}
+	}
+	var exporter rowExporter
+	switch format {
+	case models.ExportCSV, "":
+		format = models.ExportCSV
+		exporter = &csvExporter{}
+	case models.ExportJSONL:
+		exporter = &jsonExporter{lines: true}
+	case models.ExportJSON:
+		exporter = &jsonExporter{}
+	case models.ExportSQL:
+		table := opts.Table
+		if table == "" {
+			table = strings.TrimSuffix(filepath.Base(opts.Path), filepath.Ext(opts.Path))
+		}
+		exporter = &sqlExporter{table: table}
+	default:
+		return nil, fmt.Errorf("unsupported export format %s, expected csv, jsonl, json or sql", format)
+	}
+
+	resolved, err := s.resolveExportPath(opts.Path, opts.Overwrite)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := s.Limits()
+	ctx, cancel := statementContext(limits)
+	defer cancel()
+
+	rows, err := s.reader.QueryContext(ctx, opts.SQL)
+	if err != nil {
+		s.logger.Errorf("Export query failed: %v", err)
+		return nil, limitError(ctx, limits, fmt.Sprintf("query execution failed: %v", err))
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve column information")
+	}
+
+	result := &models.ExportResult{Path: resolved, Format: format, Columns: columns}
+	result.Bytes, err = writeFileAtomically(resolved, func(w io.Writer) error {
+		if err := exporter.begin(w, columns); err != nil {
+			return err
+		}
+
+		values := make([]any, len(columns))
+		valuePtrs := make([]any, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		for rows.Next() {
+			if err := rows.Scan(valuePtrs...); err != nil {
+				return err
+			}
+			if err := exporter.row(values); err != nil {
+				return err
+			}
+			result.Rows++
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return exporter.end()
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to export to %s: %v", resolved, err)
+		return nil, fmt.Errorf("failed to export to %s: %v", opts.Path, err)
+	}
+
+	s.logger.Infof("Exported %d rows to %s (%d bytes)", result.Rows, resolved, result.Bytes)
+	return result, nil
+}
+
+// isSingleStatement reports whether query holds exactly one statement. The
+// driver runs every statement of a multi-statement string.
+func isSingleStatement(query string) bool {
+	reader := newSQLStatementReader(strings.NewReader(query))
+	count := 0
+	for {
+		_, _, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			return count == 1
+		}
+		if err != nil {
+			return false
+		}
+		count++
+	}
+}
+
+// resolveExportPath resolves path, relative paths against the export
+// directory, and checks that it lies inside it and may be written
+func (s *SQLiteDB) resolveExportPath(path string, overwrite bool) (string, error) {
+	if s.exportDir == "" {
+		return "", errNoExportDir
+	}
+	if path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	joined := path
+	if !filepath.IsAbs(joined) {
+		joined = filepath.Join(s.exportDir, joined)
+	}
+	resolved, err := resolveInDirs(joined, []string{s.exportDir})
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(resolved); err == nil {
+		if info.IsDir() {
+			return "", fmt.Errorf("%s is a directory", path)
+		}
+		if !overwrite {
+			return "", fmt.Errorf("file %s already exists, set overwrite to replace it", path)
+		}
+	}
+	return resolved, nil
+}
+
+// writeFileAtomically writes a file under a temporary name in the same
+// directory and renames it once complete, so a failed write leaves no partial
+// file behind. It returns the size of the written file.
+func writeFileAtomically(path string, write func(w io.Writer) error) (int64, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+	if err != nil {
+		return 0, err
+	}
+	tmpPath := file.Name()
+	defer os.Remove(tmpPath) // No-op once renamed
+
+	w := bufio.NewWriter(file)
+	err = write(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// rowExporter writes rows in one export format
+type rowExporter interface {
+	begin(w io.Writer, columns []string) error
+	row(values []any) error
+	end() error
+}
+
+// csvExporter writes a header row and one record per row. BLOBs that are not
+// valid UTF-8 are written as hex.
+type csvExporter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (e *csvExporter) begin(w io.Writer, columns []string) error {
+	e.w = csv.NewWriter(w)
+	e.record = make([]string, len(columns))
+	return e.w.Write(columns)
+}
+
+func (e *csvExporter) row(values []any) error {
+	for i, value := range values {
+		switch v := exportValue(value).(type) {
+		case nil:
+			e.record[i] = ""
+		case string:
+			e.record[i] = v
+		case []byte:
+			e.record[i] = hex.EncodeToString(v)
+		case float64:
+			e.record[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		default:
+			e.record[i] = fmt.Sprint(v)
+		}
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *csvExporter) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonExporter writes one object per row with keys in column order, either as
+// JSON lines or as the elements of a single array. BLOBs that are not valid
+// UTF-8 are written as base64.
+type jsonExporter struct {
+	lines   bool
+	w       io.Writer
+	keys    [][]byte
+	written bool
+}
+
+func (e *jsonExporter) begin(w io.Writer, columns []string) error {
+	e.w = w
+	e.keys = make([][]byte, len(columns))
+	for i, col := range columns {
+		key, err := json.Marshal(col)
+		if err != nil {
+			return err
+		}
+		e.keys[i] = key
+	}
+	if !e.lines {
+		_, err := io.WriteString(w, "[")
+		return err
+	}
+	return nil
+}
+
+func (e *jsonExporter) row(values []any) error {
+	var b []byte
+	switch {
+	case e.lines:
+	case e.written:
+		b = append(b, ",\n  "...)
+	default:
+		b = append(b, "\n  "...)
+	}
+	e.written = true
+
+	b = append(b, '{')
+	for i, value := range values {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, e.keys[i]...)
+		b = append(b, ':')
+
+		value = exportValue(value)
+		if f, ok := value.(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+			value = nil
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		b = append(b, encoded...)
+	}
+	b = append(b, '}')
+	if e.lines {
+		b = append(b, '\n')
+	}
+
+	_, err := e.w.Write(b)
+	return err
+}
+
+func (e *jsonExporter) end() error {
+	if e.lines {
+		return nil
+	}
+	closing := "]\n"
+	if e.written {
+		closing = "\n]\n"
+	}
+	_, err := io.WriteString(e.w, closing)
+	return err
+}
+
+// sqlExporter writes one INSERT statement per row inside a transaction
+type sqlExporter struct {
+	table  string
+	w      io.Writer
+	prefix string
+}
+
+func (e *sqlExporter) begin(w io.Writer, columns []string) error {
+	e.w = w
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = quoteIdent(col)
+	}
+	e.prefix = fmt.Sprintf("INSERT INTO %s (%s) VALUES (", quoteIdent(e.table), strings.Join(quoted, ", "))
+	_, err := io.WriteString(w, "BEGIN TRANSACTION;\n")
+	return err
+}
+
+func (e *sqlExporter) row(values []any) error {
+	var b strings.Builder
+	b.WriteString(e.prefix)
+	for i, value := range values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(sqlLiteral(value))
+	}
+	b.WriteString(");\n")
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *sqlExporter) end() error {
+	_, err := io.WriteString(e.w, "COMMIT;\n")
+	return err
+}
+
+// exportValue converts a scanned value for text formats: valid UTF-8 byte
+// slices become strings and times use SQLite's timestamp format
+func exportValue(value any) any {
+	switch v := value.(type) {
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+	case time.Time:
+		return v.Format(sqlite3.SQLiteTimestampFormats[0])
+	}
+	return value
+}
+
+// sqlLiteral renders a scanned value as an SQLite literal
+func sqlLiteral(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		switch {
+		case math.IsInf(v, 1):
+			return "1e999"
+		case math.IsInf(v, -1):
+			return "-1e999"
+		case math.IsNaN(v):
+			return "NULL"
+		}
+		literal := strconv.FormatFloat(v, 'g', -1, 64)
+		// Keep REAL values from turning into INTEGER
+		if !strings.ContainsAny(literal, ".e") {
+			literal += ".0"
+		}
+		return literal
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case []byte:
+		return "X'" + strings.ToUpper(hex.EncodeToString(v)) + "'"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case time.Time:
+		return "'" + v.Format(sqlite3.SQLiteTimestampFormats[0]) + "'"
+	}
+	return "'" + strings.ReplaceAll(fmt.Sprint(value), "'", "''") + "'"
+}
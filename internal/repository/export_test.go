@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func setupExportDB(t *testing.T) (*SQLiteDB, string, func()) {
+	t.Helper()
+
+	db, cleanup := setupTestDB(t)
+	for _, statement := range []string{
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT, price REAL, data BLOB)",
+		`INSERT INTO items VALUES (1, 'Widget, large', 2.0, X'00FF'), (2, 'O''Brien "quoted"', 3.5, NULL), (3, NULL, NULL, 'text')`,
+	} {
+		if _, err := db.db.Exec(statement); err != nil {
+			t.Fatalf("Failed to set up data: %v", err)
+		}
+	}
+
+	dir := t.TempDir()
+	db.SetExportDir(dir)
+	return db, dir, cleanup
+}
+
+func TestExportQuery_Formats(t *testing.T) {
+	db, dir, cleanup := setupExportDB(t)
+	defer cleanup()
+
+	tests := []struct {
+		path     string
+		format   string
+		expected string
+	}{
+		{"items.csv", "", "id,name,price,data\n1,\"Widget, large\",2,00ff\n2,\"O'Brien \"\"quoted\"\"\",3.5,\n3,,,text\n"},
+		{"items.ndjson", "", `{"id":1,"name":"Widget, large","price":2,"data":"AP8="}` + "\n" +
+			`{"id":2,"name":"O'Brien \"quoted\"","price":3.5,"data":null}` + "\n" +
+			`{"id":3,"name":null,"price":null,"data":"text"}` + "\n"},
+		{"items.out", models.ExportJSON, "[\n  " + `{"id":1,"name":"Widget, large","price":2,"data":"AP8="}` + ",\n  " +
+			`{"id":2,"name":"O'Brien \"quoted\"","price":3.5,"data":null}` + ",\n  " +
+			`{"id":3,"name":null,"price":null,"data":"text"}` + "\n]\n"},
+		{"items.sql", "", "BEGIN TRANSACTION;\n" +
+			`INSERT INTO "items" ("id", "name", "price", "data") VALUES (1, 'Widget, large', 2.0, X'00FF');` + "\n" +
+			`INSERT INTO "items" ("id", "name", "price", "data") VALUES (2, 'O''Brien "quoted"', 3.5, NULL);` + "\n" +
+			`INSERT INTO "items" ("id", "name", "price", "data") VALUES (3, NULL, NULL, 'text');` + "\n" +
+			"COMMIT;\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result, err := db.ExportQuery(models.ExportOptions{SQL: "SELECT * FROM items ORDER BY id", Path: tt.path, Format: tt.format})
+			if err != nil {
+				t.Fatalf("ExportQuery failed: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(dir, tt.path))
+			if err != nil {
+				t.Fatalf("Failed to read export: %v", err)
+			}
+			if string(content) != tt.expected {
+				t.Errorf("Unexpected content:\n%s\nexpected:\n%s", content, tt.expected)
+			}
+			if result.Rows != 3 || result.Bytes != int64(len(content)) || !strings.HasSuffix(result.Path, tt.path) {
+				t.Errorf("Unexpected result: %+v", result)
+			}
+		})
+	}
+}
+
+func TestExportQuery_SQLRoundTrip(t *testing.T) {
+	db, dir, cleanup := setupExportDB(t)
+	defer cleanup()
+
+	if _, err := db.ExportQuery(models.ExportOptions{SQL: "SELECT * FROM items", Path: "copy.sql", Table: "items_copy"}); err != nil {
+		t.Fatalf("ExportQuery failed: %v", err)
+	}
+	script, err := os.ReadFile(filepath.Join(dir, "copy.sql"))
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if _, err := db.db.Exec("CREATE TABLE items_copy (id INTEGER PRIMARY KEY, name TEXT, price REAL, data BLOB)"); err != nil {
+		t.Fatalf("Failed to create copy: %v", err)
+	}
+	if _, err := db.db.Exec(string(script)); err != nil {
+		t.Fatalf("Failed to load export: %v", err)
+	}
+
+	var differing int
+	err = db.db.QueryRow(`SELECT COUNT(*) FROM (SELECT id, name, price, data, typeof(price), typeof(data) FROM items
+		EXCEPT SELECT id, name, price, data, typeof(price), typeof(data) FROM items_copy)`).Scan(&differing)
+	if err != nil || differing != 0 {
+		t.Errorf("Expected identical copy, got %d differing rows (%v)", differing, err)
+	}
+}
+
+func TestExportQuery_Restrictions(t *testing.T) {
+	db, dir, cleanup := setupExportDB(t)
+	defer cleanup()
+
+	if _, err := db.ExportQuery(models.ExportOptions{SQL: "SELECT 1", Path: "one.csv"}); err != nil {
+		t.Fatalf("ExportQuery failed: %v", err)
+	}
+
+	for name, opts := range map[string]models.ExportOptions{
+		"existing file": {SQL: "SELECT 2", Path: "one.csv"},
+		"not a select":  {SQL: "DELETE FROM items", Path: "x.csv"},
+		"outside":       {SQL: "SELECT 1", Path: "../x.csv"},
+		"bad format":    {SQL: "SELECT 1", Path: "x.xml"},
+		"bad query":     {SQL: "SELECT * FROM missing", Path: "x.csv"},
+		"two queries":   {SQL: "SELECT 1; DROP TABLE items", Path: "x.csv"},
+		"write in CTE":  {SQL: "WITH x AS (SELECT 1) DELETE FROM items", Path: "x.csv"},
+	} {
+		if _, err := db.ExportQuery(opts); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if exists, err := db.tableExists("items"); err != nil || !exists {
+		t.Fatalf("Expected exports to leave the items table alone, got %t (%v)", exists, err)
+	}
+	if count, _ := db.Query("SELECT COUNT(*) AS n FROM items"); count.Rows[0]["n"] == int64(0) {
+		t.Error("Expected exports not to delete rows")
+	}
+
+	// Failed exports leave no files behind
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected only one.csv in the export directory, got %v (%v)", entries, err)
+	}
+
+	if _, err := db.ExportQuery(models.ExportOptions{SQL: "SELECT 2 AS two", Path: "one.csv", Overwrite: true}); err != nil {
+		t.Fatalf("ExportQuery with overwrite failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "one.csv")); string(content) != "two\n2\n" {
+		t.Errorf("Expected overwritten file, got %q", content)
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// hllPrecision is the number of hash bits used to select a register (2^14 registers, ~0.8% error)
+const hllPrecision = 14
+
+// hyperLogLog is a minimal HyperLogLog sketch used to estimate distinct counts
+// without holding every value in memory
+type hyperLogLog struct {
+	registers []uint8
+}
+
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{registers: make([]uint8, 1<<hllPrecision)}
+}
+
+// Add records a value in the sketch
+func (h *hyperLogLog) Add(value []byte) {
+	hasher := fnv.New64a()
+	hasher.Write(value)
+	x := mix64(hasher.Sum64())
+
+	idx := x >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// Estimate returns the estimated number of distinct values added
+func (h *hyperLogLog) Estimate() int64 {
+	m := float64(len(h.registers))
+	alpha := 0.7213 / (1 + 1.079/m)
+
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha * m * m / sum
+	// Small range correction using linear counting
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int64(math.Round(estimate))
+}
+
+// mix64 is the splitmix64 finalizer, it spreads FNV output across all bits
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,360 @@
+package repository
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+const (
+	defaultImportBatchSize = 1000
+	maxImportBatchSize     = 50000
+	// importInferenceRows is the number of leading rows used to infer column types
+	importInferenceRows = 1000
+	// maxRejectedRows caps the rejected rows listed in an import result
+	maxRejectedRows = 100
+)
+
+// SetImportDirs sets the directories import tools may read files from
+func (s *SQLiteDB) SetImportDirs(dirs []string) {
+	s.importDirs = dirs
+}
+
+// ImportDirs returns the directories import tools may read files from
+func (s *SQLiteDB) ImportDirs() []string {
+	return s.importDirs
+}
+
+// resolveImportPath resolves path, relative paths against the first import
+// directory, and checks that it is an existing file inside an import directory
+func (s *SQLiteDB) resolveImportPath(path string) (string, error) {
+	if len(s.importDirs) == 0 {
+		return "", fmt.Errorf("no import directories are configured")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.importDirs[0], path)
+	}
+
+	resolved, err := resolveInDirs(path, s.importDirs)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil || info.IsDir() {
+		return "", fmt.Errorf("file %s does not exist", path)
+	}
+	return resolved, nil
+}
+
+// resolveInDirs returns the absolute path with symlinks resolved, failing when
+// it lies outside all of dirs. The file itself does not have to exist yet.
+func resolveInDirs(path string, dirs []string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %s", path)
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(abs))
+	if err != nil {
+		return "", fmt.Errorf("directory of %s does not exist", path)
+	}
+	resolved := filepath.Join(parent, filepath.Base(abs))
+	if target, err := filepath.EvalSymlinks(resolved); err == nil {
+		resolved = target
+	}
+
+	for _, dir := range dirs {
+		root, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		if real, err := filepath.EvalSymlinks(root); err == nil {
+			root = real
+		}
+		rel, err := filepath.Rel(root, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("path %s is outside the allowed directories", path)
+}
+
+// importRow is one row of input values, or a row rejected while parsing
+type importRow struct {
+	line   int
+	values []any
+	reject string
+}
+
+// rowSource yields rows until io.EOF
+type rowSource func() (importRow, error)
+
+// importRows inserts rows into table in batched transactions with a prepared
+// statement. A missing table is created with the given column types; for an
+// existing table the columns must exist. Rows failing to insert are rejected
+// with the SQLite error as reason instead of aborting the import.
+func (s *SQLiteDB) importRows(table string, columns []models.ImportColumn, next rowSource, batchSize int) (*models.ImportResult, error) {
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+	if batchSize > maxImportBatchSize {
+		batchSize = maxImportBatchSize
+	}
+
+	result := &models.ImportResult{Table: table}
+	var err error
+	var insert string
+	result.Columns, result.Created, insert, err = s.prepareImportTable(table, columns, "")
+	if err != nil {
+		return nil, err
+	}
+
+	reject := func(line int, reason string) {
+		result.RowsRejected++
+		if len(result.Rejected) < maxRejectedRows {
+			result.Rejected = append(result.Rejected, models.RejectedRow{Line: line, Reason: reason})
+		}
+	}
+
+	for done := false; !done; {
+		tx, err := s.db.Begin()
+		if err != nil {
+			s.logger.Errorf("Failed to begin transaction: %v", err)
+			return result, fmt.Errorf("failed to import into table %s", table)
+		}
+		stmt, err := tx.Prepare(insert)
+		if err != nil {
+			tx.Rollback()
+			s.logger.Errorf("Failed to prepare insert: %v", err)
+			return result, fmt.Errorf("failed to import into table %s", table)
+		}
+
+		var inserted int64
+		for n := 0; n < batchSize; n++ {
+			row, err := next()
+			if errors.Is(err, io.EOF) {
+				done = true
+				break
+			}
+			if err != nil {
+				stmt.Close()
+				tx.Rollback()
+				return result, err
+			}
+			if row.reject != "" {
+				reject(row.line, row.reject)
+				continue
+			}
+			// A failed statement only rolls back itself, the batch continues
+			if _, err := stmt.Exec(row.values...); err != nil {
+				reject(row.line, err.Error())
+				continue
+			}
+			inserted++
+		}
+
+		stmt.Close()
+		if err := tx.Commit(); err != nil {
+			s.logger.Errorf("Failed to commit import batch: %v", err)
+			return result, fmt.Errorf("failed to import into table %s", table)
+		}
+		result.RowsInserted += inserted
+	}
+
+	s.logger.Infof("Imported %d rows into %s, rejected %d", result.RowsInserted, table, result.RowsRejected)
+	return result, nil
+}
+
+// prepareImportTable creates a missing table with the given columns, or maps
+// the columns onto an existing table, and returns the resolved columns and the
+// INSERT statement for them. keyDefinition is an extra column definition, such
+// as a generated primary key, that is only used when creating the table.
+func (s *SQLiteDB) prepareImportTable(table string, columns []models.ImportColumn, keyDefinition string) ([]models.ImportColumn, bool, string, error) {
+	if isInternalTable(table) {
+		return nil, false, "", fmt.Errorf("cannot import into internal table %s", table)
+	}
+
+	exists, err := s.tableExists(table)
+	if err != nil {
+		s.logger.Errorf("Failed to check table existence: %v", err)
+		return nil, false, "", fmt.Errorf("failed to import into table %s", table)
+	}
+
+	resolved := make([]models.ImportColumn, len(columns))
+	if exists {
+		tableInfo, err := s.getTableInfo(table)
+		if err != nil {
+			s.logger.Errorf("Failed to get table info: %v", err)
+			return nil, false, "", fmt.Errorf("failed to import into table %s", table)
+		}
+		for i, col := range columns {
+			existing := findColumnByName(*tableInfo, col.Name)
+			if existing.Name == "" {
+				return nil, false, "", fmt.Errorf("column %s does not exist in table %s", col.Name, table)
+			}
+			resolved[i] = models.ImportColumn{Name: existing.Name, Type: existing.Type}
+		}
+	} else {
+		var definitions []string
+		if keyDefinition != "" {
+			definitions = append(definitions, keyDefinition)
+		}
+		for _, col := range columns {
+			definitions = append(definitions, joinNonEmpty(quoteIdent(col.Name), col.Type))
+		}
+		if _, err := s.db.Exec(fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdent(table), strings.Join(definitions, ", "))); err != nil {
+			s.logger.Errorf("Failed to create table %s: %v", table, err)
+			return nil, false, "", fmt.Errorf("failed to create table %s: %v", table, err)
+		}
+		copy(resolved, columns)
+	}
+
+	names := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	for i, col := range resolved {
+		names[i] = quoteIdent(col.Name)
+		placeholders[i] = "?"
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdent(table), strings.Join(names, ", "), strings.Join(placeholders, ", "))
+
+	return resolved, !exists, insert, nil
+}
+
+// inferColumnType returns the narrowest of INTEGER, REAL and TEXT that fits all
+// non-null values. Numbers with leading zeros such as zip codes stay TEXT.
+func inferColumnType(values []any) string {
+	inferred := ""
+	for _, value := range values {
+		inferred = widenType(inferred, valueType(value))
+	}
+	if inferred == "" {
+		return "TEXT"
+	}
+	return inferred
+}
+
+// widenType combines two inferred types, "" standing for NULL
+func widenType(current, next string) string {
+	switch {
+	case next == "":
+		return current
+	case current == "" || next == "TEXT" || (next == "REAL" && current == "INTEGER"):
+		return next
+	}
+	return current
+}
+
+func valueType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case bool, int, int64:
+		return "INTEGER"
+	case float64:
+		return "REAL"
+	case string:
+		if v == "" {
+			return ""
+		}
+		if len(v) > 1 && v[0] == '0' && v[1] != '.' {
+			return "TEXT"
+		}
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return "INTEGER"
+		}
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return "REAL"
+		}
+	}
+	return "TEXT"
+}
+
+// importColumnNames turns header fields into unique, non-empty column names
+func importColumnNames(header []string) []string {
+	names := make([]string, len(header))
+	seen := make(map[string]int)
+	for i, field := range header {
+		name := strings.TrimSpace(field)
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		base := name
+		for seen[strings.ToLower(name)] > 0 {
+			seen[strings.ToLower(base)]++
+			name = fmt.Sprintf("%s_%d", base, seen[strings.ToLower(base)])
+		}
+		seen[strings.ToLower(name)]++
+		names[i] = name
+	}
+	return names
+}
+
+// windows1252 maps the bytes 0x80-0x9F, which differ from Latin-1
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// newDecodingReader converts input in the given encoding to UTF-8 and strips a
+// UTF-8 byte order mark
+func newDecodingReader(r io.Reader, encoding string) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	switch strings.ToLower(strings.ReplaceAll(encoding, "_", "-")) {
+	case "", "utf-8", "utf8":
+		if bom, err := buffered.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+			buffered.Discard(3)
+		}
+		return buffered, nil
+	case "latin1", "latin-1", "iso-8859-1":
+		return &singleByteReader{r: buffered}, nil
+	case "windows-1252", "cp1252":
+		return &singleByteReader{r: buffered, table: &windows1252}, nil
+	}
+	return nil, fmt.Errorf("unsupported encoding %s, expected utf-8, latin1 or windows-1252", encoding)
+}
+
+// singleByteReader decodes Latin-1, optionally with the Windows-1252 overrides
+type singleByteReader struct {
+	r       *bufio.Reader
+	table   *[32]rune
+	pending []byte
+}
+
+func (d *singleByteReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.pending) > 0 {
+			copied := copy(p[n:], d.pending)
+			d.pending = d.pending[copied:]
+			n += copied
+			continue
+		}
+
+		b, err := d.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+
+		r := rune(b)
+		if d.table != nil && b >= 0x80 && b <= 0x9F {
+			r = d.table[b-0x80]
+		}
+		if r < utf8.RuneSelf {
+			p[n] = byte(r)
+			n++
+			continue
+		}
+		d.pending = utf8.AppendRune(d.pending[:0], r)
+	}
+	return n, nil
+}
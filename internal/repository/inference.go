@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+const (
+	defaultInferenceSampleSize    = 1000
+	maxInferenceSampleSize        = 100000
+	defaultInferenceMinConfidence = 0.5
+
+	// Base scores for the naming evidence behind a candidate
+	namingConfidence   = 0.5
+	sameNameConfidence = 0.4
+	// typeConfidence is added when both columns have compatible affinities
+	typeConfidence = 0.1
+	// containmentConfidence is added, scaled by the containment ratio, when
+	// nearly all sampled values exist in the referenced column
+	containmentConfidence = 0.4
+	containmentThreshold  = 0.9
+)
+
+// InferRelationships proposes likely relationships that are not declared as
+// foreign keys. Candidates come from column naming (user_id -> users.id, or a
+// column sharing the name of another table's primary key), are dropped when
+// the column affinities are incompatible, and are scored by how many sampled
+// distinct values of the referencing column exist in the referenced column.
+func (s *SQLiteDB) InferRelationships(opts models.InferenceOptions) ([]models.Relationship, error) {
+	s.logger.Debug("Inferring relationships")
+
+	tables, err := s.mainTables()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Table != "" {
+		if _, ok := findTable(tables, opts.Table); !ok {
+			return nil, fmt.Errorf("table %s does not exist", opts.Table)
+		}
+	}
+
+	relationships, err := s.inferRelationships(tables, declaredRelationships(tables), opts)
+	if err != nil {
+		s.logger.Errorf("Failed to infer relationships: %v", err)
+		return nil, fmt.Errorf("failed to infer relationships")
+	}
+
+	s.logger.Infof("Inferred %d relationships", len(relationships))
+	return relationships, nil
+}
+
+func (s *SQLiteDB) inferRelationships(tables []models.Table, declared []models.Relationship, opts models.InferenceOptions) ([]models.Relationship, error) {
+	opts = normalizeInferenceOptions(opts)
+
+	candidates := namingRelationships(tables, declared)
+	candidates = append(candidates, sameNameRelationships(tables, append(declared, candidates...))...)
+
+	var relationships []models.Relationship
+	for _, candidate := range candidates {
+		if opts.Table != "" && !strings.EqualFold(candidate.FromTable, opts.Table) && !strings.EqualFold(candidate.ToTable, opts.Table) {
+			continue
+		}
+
+		child, _ := findTable(tables, candidate.FromTable)
+		parent, _ := findTable(tables, candidate.ToTable)
+		childType := columnType(child, candidate.FromColumns[0])
+		parentType := columnType(parent, candidate.ToColumns[0])
+		if !compatibleAffinities(typeAffinity(childType), typeAffinity(parentType)) {
+			continue
+		}
+
+		reasons := []string{candidate.Reason, fmt.Sprintf("compatible types %s/%s", displayType(childType), displayType(parentType))}
+		confidence := candidate.Confidence + typeConfidence
+
+		sampled, found, err := s.containment(candidate, opts.SampleSize)
+		if err != nil {
+			return nil, err
+		}
+		if sampled > 0 {
+			ratio := float64(found) / float64(sampled)
+			if ratio >= containmentThreshold {
+				confidence += containmentConfidence * ratio
+			} else {
+				confidence *= ratio
+			}
+			reasons = append(reasons, fmt.Sprintf("%d of %d sampled values found in %s.%s", found, sampled, candidate.ToTable, candidate.ToColumns[0]))
+		} else {
+			reasons = append(reasons, "no values to check containment")
+		}
+
+		candidate.Confidence = math.Round(math.Min(confidence, 1)*100) / 100
+		candidate.Reason = strings.Join(reasons, "; ")
+		if candidate.Confidence < *opts.MinConfidence {
+			continue
+		}
+		relationships = append(relationships, candidate)
+	}
+
+	sort.SliceStable(relationships, func(i, j int) bool {
+		return relationships[i].Confidence > relationships[j].Confidence
+	})
+
+	return relationships, nil
+}
+
+// containment samples distinct non-null values of the referencing column and
+// counts how many of them exist in the referenced column
+func (s *SQLiteDB) containment(r models.Relationship, sampleSize int) (sampled, found int64, err error) {
+	child, childCol := quoteIdent(r.FromTable), quoteIdent(r.FromColumns[0])
+	parent, parentCol := quoteIdent(r.ToTable), quoteIdent(r.ToColumns[0])
+
+	query := fmt.Sprintf(`SELECT COUNT(*), COALESCE(SUM(EXISTS (SELECT 1 FROM %s p WHERE p.%s = v.value)), 0)
+		FROM (SELECT DISTINCT %s AS value FROM %s WHERE %s IS NOT NULL LIMIT ?) v`,
+		parent, parentCol, childCol, child, childCol)
+
+	err = s.reader.QueryRow(query, sampleSize).Scan(&sampled, &found)
+	return sampled, found, err
+}
+
+// sameNameRelationships proposes relationships for columns that share the name
+// of another table's single-column primary key, such as orders.customer_code
+// -> customers.customer_code. Generic key names like id are ignored.
+func sameNameRelationships(tables []models.Table, known []models.Relationship) []models.Relationship {
+	covered := make(map[string]bool)
+	for _, r := range known {
+		for _, col := range r.FromColumns {
+			covered[strings.ToLower(r.FromTable+"."+col)] = true
+		}
+	}
+
+	var relationships []models.Relationship
+	for _, parent := range tables {
+		pk := primaryKeyColumns(parent)
+		if len(pk) != 1 || strings.EqualFold(pk[0], "id") || strings.EqualFold(pk[0], "rowid") {
+			continue
+		}
+
+		for _, child := range tables {
+			if strings.EqualFold(child.Name, parent.Name) {
+				continue
+			}
+			if childPK := primaryKeyColumns(child); len(childPK) == 1 && strings.EqualFold(childPK[0], pk[0]) {
+				continue
+			}
+
+			for _, column := range child.Columns {
+				if !strings.EqualFold(column.Name, pk[0]) || covered[strings.ToLower(child.Name+"."+column.Name)] {
+					continue
+				}
+				relationships = append(relationships, models.Relationship{
+					FromTable:   child.Name,
+					FromColumns: []string{column.Name},
+					ToTable:     parent.Name,
+					ToColumns:   []string{pk[0]},
+					Inferred:    true,
+					Confidence:  sameNameConfidence,
+					Reason:      fmt.Sprintf("column %s shares the name of the primary key of %s", column.Name, parent.Name),
+				})
+			}
+		}
+	}
+
+	return relationships
+}
+
+// typeAffinity applies SQLite's column affinity rules to a declared type
+func typeAffinity(declared string) string {
+	t := strings.ToUpper(declared)
+	switch {
+	case strings.Contains(t, "INT"):
+		return "INTEGER"
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return "TEXT"
+	case t == "", strings.Contains(t, "BLOB"):
+		return "BLOB"
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		return "REAL"
+	}
+	return "NUMERIC"
+}
+
+// compatibleAffinities reports whether values of two affinities can compare equal.
+// BLOB affinity stores values as given, so it is compatible with everything.
+func compatibleAffinities(a, b string) bool {
+	if a == b || a == "BLOB" || b == "BLOB" {
+		return true
+	}
+	numeric := func(affinity string) bool {
+		return affinity == "INTEGER" || affinity == "REAL" || affinity == "NUMERIC"
+	}
+	return numeric(a) && numeric(b)
+}
+
+func columnType(table models.Table, column string) string {
+	for _, col := range table.Columns {
+		if strings.EqualFold(col.Name, column) {
+			return col.Type
+		}
+	}
+	return ""
+}
+
+func displayType(declared string) string {
+	if declared == "" {
+		return "untyped"
+	}
+	return declared
+}
+
+func normalizeInferenceOptions(opts models.InferenceOptions) models.InferenceOptions {
+	if opts.SampleSize <= 0 {
+		opts.SampleSize = defaultInferenceSampleSize
+	}
+	if opts.SampleSize > maxInferenceSampleSize {
+		opts.SampleSize = maxInferenceSampleSize
+	}
+	if opts.MinConfidence == nil {
+		minConfidence := defaultInferenceMinConfidence
+		opts.MinConfidence = &minConfidence
+	}
+	return opts
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func setupInferenceTestDB(t *testing.T) (*SQLiteDB, func()) {
+	db, cleanup := setupTestDB(t)
+
+	statements := []string{
+		`CREATE TABLE customers (customer_code TEXT PRIMARY KEY, name TEXT)`,
+		`CREATE TABLE categories (id INTEGER PRIMARY KEY, name TEXT)`,
+		`CREATE TABLE tags (id INTEGER PRIMARY KEY, label TEXT)`,
+		`CREATE TABLE invoices (
+            id INTEGER PRIMARY KEY,
+            test_user_id INTEGER,
+            customer_code TEXT,
+            category_id INTEGER,
+            tag_id TEXT
+        )`,
+		`INSERT INTO test_users (id, name) VALUES (1, 'John'), (2, 'Jane')`,
+		`INSERT INTO customers VALUES ('C1', 'Acme'), ('C2', 'Globex')`,
+		`INSERT INTO categories VALUES (1, 'Books')`,
+		`INSERT INTO invoices (test_user_id, customer_code, category_id, tag_id) VALUES
+            (1, 'C1', 1, 'a'), (2, 'C2', 7, 'b'), (2, 'C1', 8, 'c'), (1, NULL, 9, 'd')`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Execute(statement); err != nil {
+			cleanup()
+			t.Fatalf("Failed to set up test data: %v", err)
+		}
+	}
+
+	return db, cleanup
+}
+
+func TestInferRelationships(t *testing.T) {
+	db, cleanup := setupInferenceTestDB(t)
+	defer cleanup()
+
+	relationships, err := db.InferRelationships(models.InferenceOptions{})
+	if err != nil {
+		t.Fatalf("InferRelationships failed: %v", err)
+	}
+
+	found := make(map[string]models.Relationship)
+	for _, r := range relationships {
+		found[r.FromTable+"."+r.FromColumns[0]+"->"+r.ToTable+"."+r.ToColumns[0]] = r
+	}
+
+	user, ok := found["invoices.test_user_id->test_users.id"]
+	if !ok {
+		t.Fatalf("Expected invoices.test_user_id -> test_users.id, got %v", relationships)
+	}
+	if !user.Inferred || user.Confidence != 1 {
+		t.Errorf("Expected fully contained naming match to score 1, got %.2f", user.Confidence)
+	}
+
+	if _, ok := found["invoices.customer_code->customers.customer_code"]; !ok {
+		t.Error("Expected relationship on shared primary key name customer_code")
+	}
+
+	if _, ok := found["invoices.category_id->categories.id"]; ok {
+		t.Error("Expected category_id to be dropped, most of its values are missing from categories")
+	}
+
+	if _, ok := found["invoices.tag_id->tags.id"]; ok {
+		t.Error("Expected tag_id to be dropped for incompatible types")
+	}
+}
+
+func TestInferRelationships_TableFilter(t *testing.T) {
+	db, cleanup := setupInferenceTestDB(t)
+	defer cleanup()
+
+	relationships, err := db.InferRelationships(models.InferenceOptions{Table: "customers"})
+	if err != nil {
+		t.Fatalf("InferRelationships failed: %v", err)
+	}
+
+	if len(relationships) != 1 || relationships[0].ToTable != "customers" {
+		t.Errorf("Expected only the customers relationship, got %v", relationships)
+	}
+
+	if _, err := db.InferRelationships(models.InferenceOptions{Table: "missing"}); err == nil {
+		t.Error("Expected error for missing table")
+	}
+}
+
+func TestInferRelationships_ZeroMinConfidence(t *testing.T) {
+	db, cleanup := setupInferenceTestDB(t)
+	defer cleanup()
+
+	minConfidence := 0.0
+	relationships, err := db.InferRelationships(models.InferenceOptions{Table: "categories", MinConfidence: &minConfidence})
+	if err != nil {
+		t.Fatalf("InferRelationships failed: %v", err)
+	}
+
+	if len(relationships) != 1 || relationships[0].FromColumns[0] != "category_id" || relationships[0].Confidence >= 0.5 {
+		t.Errorf("Expected the low confidence category_id candidate with a zero minimum, got %v", relationships)
+	}
+}
+
+func TestTypeAffinity(t *testing.T) {
+	cases := map[string]string{
+		"INTEGER":       "INTEGER",
+		"BIGINT":        "INTEGER",
+		"VARCHAR(255)":  "TEXT",
+		"":              "BLOB",
+		"DOUBLE":        "REAL",
+		"DECIMAL(10,2)": "NUMERIC",
+		"DATETIME":      "NUMERIC",
+	}
+	for declared, expected := range cases {
+		if affinity := typeAffinity(declared); affinity != expected {
+			t.Errorf("typeAffinity(%q) = %s, expected %s", declared, affinity, expected)
+		}
+	}
+}
@@ -0,0 +1,627 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+// flatRecord is a JSON object flattened into column values, with its nested
+// arrays kept aside when they are split into child tables
+type flatRecord struct {
+	number int
+	values *jsonObject
+	arrays map[string][]any
+	reject string
+}
+
+// ImportJSON imports a JSON array of objects, or newline delimited JSON
+// objects, from an import directory into a table. Nested objects are flattened
+// into prefixed columns or stored as JSON text. With SplitArrays nested arrays
+// become child tables named <table>_<field> whose <table>_id column references
+// the parent row; otherwise they are stored as JSON text. Each record and its
+// child rows are inserted together or rejected together.
+func (s *SQLiteDB) ImportJSON(opts models.JSONImportOptions) (*models.ImportResult, error) {
+	s.logger.Debugf("Importing JSON %s into %s", opts.Path, opts.Table)
+
+	if opts.Table == "" {
+		return nil, fmt.Errorf("table name is required")
+	}
+	switch opts.Flatten {
+	case "":
+		opts.Flatten = models.FlattenColumns
+	case models.FlattenColumns, models.FlattenJSON:
+	default:
+		return nil, fmt.Errorf("invalid flatten mode %s, expected columns or json", opts.Flatten)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+	if opts.BatchSize > maxImportBatchSize {
+		opts.BatchSize = maxImportBatchSize
+	}
+
+	path, err := s.resolveImportPath(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		s.logger.Errorf("Failed to open %s: %v", path, err)
+		return nil, fmt.Errorf("failed to open %s", opts.Path)
+	}
+	defer file.Close()
+
+	decoded, err := newDecodingReader(file, "")
+	if err != nil {
+		return nil, err
+	}
+	records, err := newJSONRecordReader(decoded, opts.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	next := func() (flatRecord, error) {
+		value, number, err := records.next()
+		if err != nil {
+			return flatRecord{}, err
+		}
+		object, ok := value.(*jsonObject)
+		if !ok {
+			return flatRecord{number: number, reject: "expected a JSON object"}, nil
+		}
+		record := flatRecord{number: number, values: newJSONObject()}
+		if opts.SplitArrays {
+			record.arrays = make(map[string][]any)
+		}
+		flattenJSON("", object, record.values, record.arrays, opts.Flatten)
+		return record, nil
+	}
+
+	// Buffer the leading records to discover columns and child tables
+	var buffered []flatRecord
+	for len(buffered) < importInferenceRows {
+		record, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		buffered = append(buffered, record)
+	}
+
+	parentColumns := jsonColumns(buffered, func(r flatRecord) []*jsonObject {
+		return []*jsonObject{r.values}
+	})
+	if len(parentColumns) == 0 {
+		return nil, fmt.Errorf("%s has no objects to import", opts.Path)
+	}
+
+	childPaths := make(map[string]bool)
+	for _, record := range buffered {
+		for path := range record.arrays {
+			childPaths[path] = true
+		}
+	}
+
+	result := &models.ImportResult{Table: opts.Table}
+	var keyColumn, keyDefinition string
+	if len(childPaths) > 0 {
+		keyColumn, keyDefinition, err = s.importKeyColumn(opts.Table, parentColumns)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var parentInsert string
+	result.Columns, result.Created, parentInsert, err = s.prepareImportTable(opts.Table, parentColumns, keyDefinition)
+	if err != nil {
+		return nil, err
+	}
+
+	type childTable struct {
+		path    string
+		result  *models.ImportResult
+		columns []models.ImportColumn // Element columns, without the foreign key
+		insert  string
+	}
+	var children []*childTable
+	for _, path := range sortedKeys(childPaths) {
+		columns := jsonColumns(buffered, func(r flatRecord) []*jsonObject {
+			return arrayElements(r.arrays[path], opts.Flatten)
+		})
+		foreignKey := models.ImportColumn{
+			Name: opts.Table + "_id",
+			Type: fmt.Sprintf("INTEGER REFERENCES %s(%s)", quoteIdent(opts.Table), quoteIdent(keyColumn)),
+		}
+
+		child := &childTable{path: path, columns: columns, result: &models.ImportResult{Table: opts.Table + "_" + path}}
+		child.result.Columns, child.result.Created, child.insert, err = s.prepareImportTable(child.result.Table, append([]models.ImportColumn{foreignKey}, columns...), "")
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	reject := func(record int, reason string) {
+		result.RowsRejected++
+		if len(result.Rejected) < maxRejectedRows {
+			result.Rejected = append(result.Rejected, models.RejectedRow{Record: record, Reason: reason})
+		}
+	}
+
+	nextRecord := func() (flatRecord, error) {
+		if len(buffered) > 0 {
+			record := buffered[0]
+			buffered = buffered[1:]
+			return record, nil
+		}
+		return next()
+	}
+
+	for done := false; !done; {
+		tx, err := s.db.Begin()
+		if err != nil {
+			s.logger.Errorf("Failed to begin transaction: %v", err)
+			return result, fmt.Errorf("failed to import into table %s", opts.Table)
+		}
+
+		// insertRecord inserts the record and its child rows, returning the
+		// number of child rows per child table
+		insertRecord := func(record flatRecord) ([]int64, error) {
+			values, err := columnValues(parentColumns, record.values)
+			if err != nil {
+				return nil, err
+			}
+			for path := range record.arrays {
+				if !childPaths[path] {
+					return nil, fmt.Errorf("unexpected nested array %s", path)
+				}
+			}
+
+			inserted, err := tx.Exec(parentInsert, values...)
+			if err != nil {
+				return nil, err
+			}
+			parentID, err := inserted.LastInsertId()
+			if err != nil {
+				return nil, err
+			}
+
+			counts := make([]int64, len(children))
+			for i, child := range children {
+				for _, element := range arrayElements(record.arrays[child.path], opts.Flatten) {
+					values, err := columnValues(child.columns, element)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %v", child.path, err)
+					}
+					if _, err := tx.Exec(child.insert, append([]any{parentID}, values...)...); err != nil {
+						return nil, fmt.Errorf("%s: %v", child.path, err)
+					}
+					counts[i]++
+				}
+			}
+			return counts, nil
+		}
+
+		var inserted int64
+		childInserted := make([]int64, len(children))
+		for n := 0; n < opts.BatchSize; n++ {
+			record, err := nextRecord()
+			if errors.Is(err, io.EOF) {
+				done = true
+				break
+			}
+			if err != nil {
+				tx.Rollback()
+				return result, err
+			}
+			if record.reject != "" {
+				reject(record.number, record.reject)
+				continue
+			}
+
+			// A savepoint keeps a record and its child rows together
+			if _, err := tx.Exec("SAVEPOINT import_record"); err != nil {
+				tx.Rollback()
+				s.logger.Errorf("Failed to create savepoint: %v", err)
+				return result, fmt.Errorf("failed to import into table %s", opts.Table)
+			}
+			counts, err := insertRecord(record)
+			if err != nil {
+				if _, rollbackErr := tx.Exec("ROLLBACK TO import_record"); rollbackErr != nil {
+					tx.Rollback()
+					s.logger.Errorf("Failed to roll back to savepoint: %v", rollbackErr)
+					return result, fmt.Errorf("failed to import into table %s", opts.Table)
+				}
+				reject(record.number, err.Error())
+			} else {
+				inserted++
+				for i, count := range counts {
+					childInserted[i] += count
+				}
+			}
+			if _, err := tx.Exec("RELEASE import_record"); err != nil {
+				tx.Rollback()
+				s.logger.Errorf("Failed to release savepoint: %v", err)
+				return result, fmt.Errorf("failed to import into table %s", opts.Table)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			s.logger.Errorf("Failed to commit import batch: %v", err)
+			return result, fmt.Errorf("failed to import into table %s", opts.Table)
+		}
+		result.RowsInserted += inserted
+		for i, child := range children {
+			child.result.RowsInserted += childInserted[i]
+		}
+	}
+
+	for _, child := range children {
+		result.ChildTables = append(result.ChildTables, *child.result)
+	}
+
+	s.logger.Infof("Imported %d records into %s, rejected %d", result.RowsInserted, opts.Table, result.RowsRejected)
+	return result, nil
+}
+
+// importKeyColumn returns the INTEGER PRIMARY KEY column child tables reference.
+// A new table uses an integer id field as its key, or gets a generated one.
+func (s *SQLiteDB) importKeyColumn(table string, columns []models.ImportColumn) (string, string, error) {
+	exists, err := s.tableExists(table)
+	if err != nil {
+		s.logger.Errorf("Failed to check table existence: %v", err)
+		return "", "", fmt.Errorf("failed to import into table %s", table)
+	}
+
+	if exists {
+		tableInfo, err := s.getTableInfo(table)
+		if err != nil {
+			s.logger.Errorf("Failed to get table info: %v", err)
+			return "", "", fmt.Errorf("failed to import into table %s", table)
+		}
+		pk := primaryKeyColumns(*tableInfo)
+		if len(pk) == 1 && strings.EqualFold(findColumnByName(*tableInfo, pk[0]).Type, "INTEGER") {
+			return pk[0], "", nil
+		}
+		return "", "", fmt.Errorf("table %s needs an INTEGER PRIMARY KEY to link child tables", table)
+	}
+
+	for i, col := range columns {
+		if strings.EqualFold(col.Name, "id") {
+			if col.Type == "INTEGER" {
+				columns[i].Type = "INTEGER PRIMARY KEY"
+				return col.Name, "", nil
+			}
+			return "_id", `"_id" INTEGER PRIMARY KEY`, nil
+		}
+	}
+	return "id", `"id" INTEGER PRIMARY KEY`, nil
+}
+
+// jsonColumns collects the columns of the objects returned by objects for each
+// record, in the order their fields first appear, with types inferred from
+// their values
+func jsonColumns(records []flatRecord, objects func(flatRecord) []*jsonObject) []models.ImportColumn {
+	types := make(map[string]string)
+	var names []string
+	for _, record := range records {
+		if record.reject != "" {
+			continue
+		}
+		for _, object := range objects(record) {
+			for _, name := range object.keys {
+				if _, ok := types[name]; !ok {
+					names = append(names, name)
+				}
+				types[name] = widenType(types[name], jsonValueType(object.values[name]))
+			}
+		}
+	}
+
+	columns := make([]models.ImportColumn, 0, len(names))
+	for _, name := range names {
+		t := types[name]
+		if t == "" {
+			t = "TEXT"
+		}
+		columns = append(columns, models.ImportColumn{Name: name, Type: t})
+	}
+	return columns
+}
+
+// columnValues orders the values of a flattened object by columns
+func columnValues(columns []models.ImportColumn, object *jsonObject) ([]any, error) {
+	values := make([]any, len(columns))
+	known := 0
+	for i, col := range columns {
+		if value, ok := object.values[col.Name]; ok {
+			values[i] = value
+			known++
+		}
+	}
+	if known < len(object.keys) {
+		for _, name := range object.keys {
+			if !containsImportColumn(columns, name) {
+				return nil, fmt.Errorf("unexpected field %s", name)
+			}
+		}
+	}
+	return values, nil
+}
+
+func containsImportColumn(columns []models.ImportColumn, name string) bool {
+	for _, col := range columns {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// arrayElements flattens the elements of a nested array, wrapping scalars in a value column
+func arrayElements(array []any, mode string) []*jsonObject {
+	elements := make([]*jsonObject, 0, len(array))
+	for _, element := range array {
+		flat := newJSONObject()
+		if object, ok := element.(*jsonObject); ok {
+			flattenJSON("", object, flat, nil, mode)
+		} else {
+			wrapped := newJSONObject()
+			wrapped.set("value", element)
+			flattenJSON("", wrapped, flat, nil, mode)
+		}
+		elements = append(elements, flat)
+	}
+	return elements
+}
+
+// flattenJSON writes the fields of object into values. Nested objects become
+// prefix_field columns or JSON text depending on mode; nested arrays are
+// collected in arrays when it is not nil and stored as JSON text otherwise.
+func flattenJSON(prefix string, object *jsonObject, values *jsonObject, arrays map[string][]any, mode string) {
+	for _, key := range object.keys {
+		name := prefix + key
+		switch v := object.values[key].(type) {
+		case *jsonObject:
+			if mode == models.FlattenJSON {
+				values.set(name, jsonText(v))
+			} else {
+				flattenJSON(name+"_", v, values, arrays, mode)
+			}
+		case []any:
+			if arrays != nil {
+				arrays[name] = v
+			} else {
+				values.set(name, jsonText(v))
+			}
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				values.set(name, i)
+			} else if f, err := v.Float64(); err == nil {
+				values.set(name, f)
+			} else {
+				values.set(name, v.String())
+			}
+		default:
+			values.set(name, v)
+		}
+	}
+}
+
+// jsonObject is a JSON object that keeps its keys in the order they first
+// appear, so imported columns follow the source rather than the alphabet
+type jsonObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func newJSONObject() *jsonObject {
+	return &jsonObject{values: make(map[string]any)}
+}
+
+// set stores a value, keeping the position of a key seen before
+func (o *jsonObject) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeJSONValue reads the next value from decoder token by token, returning
+// objects as *jsonObject, arrays as []any and numbers as json.Number
+func decodeJSONValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	// The value has started, running out of input now truncates it
+	truncated := func(err error) error {
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := newJSONObject()
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, truncated(err)
+			}
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, truncated(err)
+			}
+			object.set(key.(string), value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, truncated(err)
+		}
+		return object, nil
+	case json.Delim('['):
+		array := []any{}
+		for decoder.More() {
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, truncated(err)
+			}
+			array = append(array, value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, truncated(err)
+		}
+		return array, nil
+	}
+	return token, nil
+}
+
+func jsonText(value any) string {
+	text, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(text)
+}
+
+// jsonValueType infers the column type of a flattened JSON value. Unlike CSV
+// fields, JSON strings are never treated as numbers.
+func jsonValueType(value any) string {
+	if _, ok := value.(string); ok {
+		return "TEXT"
+	}
+	return valueType(value)
+}
+
+// jsonRecordReader yields the elements of a JSON array, optionally nested
+// under root in a top-level object, or a stream of JSON values such as NDJSON
+type jsonRecordReader struct {
+	decoder *json.Decoder
+	inArray bool
+	number  int
+}
+
+func newJSONRecordReader(r io.Reader, root string) (*jsonRecordReader, error) {
+	buffered := bufio.NewReader(r)
+	first, err := peekNonSpace(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("input is empty")
+	}
+
+	reader := &jsonRecordReader{decoder: json.NewDecoder(buffered)}
+	reader.decoder.UseNumber()
+
+	switch {
+	case first == '[':
+		if root != "" {
+			return nil, fmt.Errorf("root %s requires a top-level object", root)
+		}
+		if _, err := reader.decoder.Token(); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+		reader.inArray = true
+	case root != "":
+		if err := reader.descend(strings.Split(root, ".")); err != nil {
+			return nil, err
+		}
+		reader.inArray = true
+	}
+	return reader, nil
+}
+
+// descend moves the decoder to the array found by following path through nested objects
+func (r *jsonRecordReader) descend(path []string) error {
+	for i, key := range path {
+		if token, err := r.decoder.Token(); err != nil || token != json.Delim('{') {
+			return fmt.Errorf("root %s not found: expected an object", strings.Join(path[:i], "."))
+		}
+		for {
+			token, err := r.decoder.Token()
+			if err != nil {
+				return fmt.Errorf("invalid JSON: %v", err)
+			}
+			if token == json.Delim('}') {
+				return fmt.Errorf("root %s not found", strings.Join(path[:i+1], "."))
+			}
+			if token == key {
+				break
+			}
+			var skipped json.RawMessage
+			if err := r.decoder.Decode(&skipped); err != nil {
+				return fmt.Errorf("invalid JSON: %v", err)
+			}
+		}
+	}
+
+	if token, err := r.decoder.Token(); err != nil || token != json.Delim('[') {
+		return fmt.Errorf("root %s is not an array", strings.Join(path, "."))
+	}
+	return nil
+}
+
+// next returns the next record and its 1-based number, or io.EOF
+func (r *jsonRecordReader) next() (any, int, error) {
+	if r.inArray && !r.decoder.More() {
+		return nil, 0, io.EOF
+	}
+
+	value, err := decodeJSONValue(r.decoder)
+	if err != nil {
+		if errors.Is(err, io.EOF) && !r.inArray {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, fmt.Errorf("invalid JSON after record %d: %v", r.number, err)
+	}
+	r.number++
+	return value, r.number, nil
+}
+
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !isSpace(b[0]) {
+			return b[0], nil
+		}
+		if _, err := r.Discard(1); err != nil {
+			return 0, err
+		}
+	}
+}
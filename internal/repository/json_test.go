@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func importColumnList(columns []models.ImportColumn) string {
+	var list []string
+	for _, col := range columns {
+		list = append(list, col.Name+" "+col.Type)
+	}
+	return strings.Join(list, ", ")
+}
+
+func TestImportJSON_FlattensObjects(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	writeImportFile(t, db, "people.json", `[
+		{"name": "Ann", "age": 31, "zip": "02134", "address": {"city": "Boston", "geo": {"lat": 42.3}}, "tags": ["a", "b"]},
+		{"name": "Bob", "age": 2.5, "active": true, "address": {"city": "NYC"}},
+		"not an object",
+		{"name": "Cy", "unknown": 1}
+	]`)
+
+	result, err := db.ImportJSON(models.JSONImportOptions{Path: "people.json", Table: "people"})
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+
+	if !result.Created || result.RowsInserted != 3 || result.RowsRejected != 1 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if result.Rejected[0].Record != 3 || result.Rejected[0].Reason != "expected a JSON object" {
+		t.Errorf("Unexpected rejection: %+v", result.Rejected[0])
+	}
+	expected := "name TEXT, age REAL, zip TEXT, address_city TEXT, address_geo_lat REAL, tags TEXT, active INTEGER, unknown INTEGER"
+	if got := importColumnList(result.Columns); got != expected {
+		t.Errorf("Unexpected columns: %s", got)
+	}
+
+	rows, err := db.Query("SELECT name, zip, address_city, tags, active FROM people ORDER BY name")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if rows.Rows[0]["zip"] != "02134" || rows.Rows[0]["address_city"] != "Boston" || rows.Rows[0]["tags"] != `["a","b"]` || rows.Rows[1]["active"] != int64(1) {
+		t.Errorf("Unexpected rows: %v", rows.Rows)
+	}
+
+	// NDJSON into the existing table, whose columns must include every field
+	writeImportFile(t, db, "more.ndjson", "{\"name\": \"Dee\"}\n{\"name\": \"Eve\", \"age\": 20}\n")
+	result, err = db.ImportJSON(models.JSONImportOptions{Path: "more.ndjson", Table: "people"})
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if result.Created || result.RowsInserted != 2 || result.RowsRejected != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	writeImportFile(t, db, "extra.ndjson", "{\"name\": \"Fay\", \"email\": \"f@x\"}\n")
+	if _, err := db.ImportJSON(models.JSONImportOptions{Path: "extra.ndjson", Table: "people"}); err == nil || !strings.Contains(err.Error(), "column email does not exist") {
+		t.Errorf("Expected missing column error, got: %v", err)
+	}
+}
+
+func TestImportJSON_SplitArrays(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	writeImportFile(t, db, "orders.json", `{"meta": {"count": 2}, "data": {"orders": [
+		{"id": 10, "customer": {"name": "Ann"}, "items": [{"sku": "A1", "qty": 2}, {"sku": "B2", "qty": 1}], "notes": ["gift"]},
+		{"id": 11, "customer": {"name": "Bob"}, "items": [{"sku": "C3", "qty": 5}]},
+		{"id": 10, "items": [{"sku": "D4", "qty": 1}]}
+	]}}`)
+
+	result, err := db.ImportJSON(models.JSONImportOptions{
+		Path:        "orders.json",
+		Table:       "orders",
+		Root:        "data.orders",
+		Flatten:     models.FlattenJSON,
+		SplitArrays: true,
+	})
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+
+	if result.RowsInserted != 2 || result.RowsRejected != 1 || result.Rejected[0].Record != 3 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if got := importColumnList(result.Columns); got != "id INTEGER PRIMARY KEY, customer TEXT" {
+		t.Errorf("Unexpected columns: %s", got)
+	}
+	if len(result.ChildTables) != 2 || result.ChildTables[0].Table != "orders_items" || result.ChildTables[0].RowsInserted != 3 || result.ChildTables[1].RowsInserted != 1 {
+		t.Fatalf("Unexpected child tables: %+v", result.ChildTables)
+	}
+	if got := importColumnList(result.ChildTables[1].Columns); got != `orders_id INTEGER REFERENCES "orders"("id"), value TEXT` {
+		t.Errorf("Unexpected child columns: %s", got)
+	}
+
+	rows, err := db.Query("SELECT o.customer, i.sku FROM orders o JOIN orders_items i ON i.orders_id = o.id ORDER BY i.sku")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(rows.Rows) != 3 || rows.Rows[2]["sku"] != "C3" || rows.Rows[2]["customer"] != `{"name":"Bob"}` {
+		t.Errorf("Unexpected rows: %v", rows.Rows)
+	}
+}
+
+func TestImportJSON_GeneratedKeyAndErrors(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	writeImportFile(t, db, "posts.json", `[{"title": "Hi", "comments": [{"body": "first"}]}]`)
+	result, err := db.ImportJSON(models.JSONImportOptions{Path: "posts.json", Table: "posts", SplitArrays: true})
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if result.RowsInserted != 1 || result.ChildTables[0].RowsInserted != 1 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	schema, err := db.Query("SELECT sql FROM sqlite_master WHERE name = 'posts'")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if !strings.Contains(schema.Rows[0]["sql"].(string), `"id" INTEGER PRIMARY KEY`) {
+		t.Errorf("Expected generated key, got: %v", schema.Rows[0]["sql"])
+	}
+
+	for name, opts := range map[string]models.JSONImportOptions{
+		"missing root": {Path: "posts.json", Table: "x", Root: "data"},
+		"bad flatten":  {Path: "posts.json", Table: "x", Flatten: "yaml"},
+		"internal":     {Path: "posts.json", Table: "_mcp_annotations"},
+		"outside":      {Path: "../posts.json", Table: "x"},
+	} {
+		if _, err := db.ImportJSON(opts); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+var memoryDatabases atomic.Int64
+
+// memoryDSN names a new in-memory database. The memdb VFS lets every
+// connection of the pool open that one database, a plain :memory: gives each
+// connection its own empty database. Unlike a shared cache it uses file
+// locking, so readers wait out a write with busy_timeout instead of failing
+// with SQLITE_LOCKED.
+func memoryDSN() string {
+	return fmt.Sprintf("file:/sqlite-mcp-memory-%d?vfs=memdb", memoryDatabases.Add(1))
+}
+
+// InMemory reports whether the database lives in memory only
+func (s *SQLiteDB) InMemory() bool {
+	return s.path == models.MemoryDatabase
+}
+
+// pinMemory holds a connection for the lifetime of an in-memory database, so
+// the pool closing idle connections does not drop the database
+func (s *SQLiteDB) pinMemory() error {
+	conn, err := s.reader.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	s.memoryConn = conn
+	return nil
+}
+
+// EphemeralDir is a temporary directory holding copies of template databases,
+// which are served instead of the templates and removed on exit
+type EphemeralDir struct {
+	dir string
+}
+
+func NewEphemeralDir() (*EphemeralDir, error) {
+	dir, err := os.MkdirTemp("", "sqlite-mcp-ephemeral-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	return &EphemeralDir{dir: dir}, nil
+}
+
+// Copy copies a template database for the database name and returns the path
+// of the copy. For a template that does not exist it returns the path a new
+// database can be created at.
+func (d *EphemeralDir) Copy(name, template string) (string, error) {
+	path := filepath.Join(d.dir, name+".db")
+	if _, err := os.Stat(template); os.IsNotExist(err) {
+		return path, nil
+	}
+
+	src, err := sql.Open("sqlite3", readOnlyDSN(template))
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", template, err)
+	}
+	defer src.Close()
+	if err := copyDatabaseToFile(context.Background(), path, src); err != nil {
+		return "", fmt.Errorf("failed to copy %s: %w", template, err)
+	}
+	return path, nil
+}
+
+// Remove deletes the directory with all copies
+func (d *EphemeralDir) Remove() error {
+	return os.RemoveAll(d.dir)
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func TestMemory_ConnectionsShareOneDatabase(t *testing.T) {
+	db, err := NewSQLiteDB(models.MemoryDatabase, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to open in-memory database: %v", err)
+	}
+	defer db.Close()
+	if !db.InMemory() {
+		t.Error("Expected the database to be in memory")
+	}
+
+	if _, err := db.Execute("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, err := db.Execute("INSERT INTO notes (body) VALUES ('hello')"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		conn, err := db.reader.Conn(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get connection: %v", err)
+		}
+		defer conn.Close()
+		var count int
+		if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM notes").Scan(&count); err != nil || count != 1 {
+			t.Errorf("Connection %d: expected 1 note, got %d (%v)", i, count, err)
+		}
+	}
+
+	// The database survives the pools closing their idle connections
+	db.db.SetMaxIdleConns(0)
+	db.reader.SetMaxIdleConns(0)
+	if n := countRows(t, db, "SELECT COUNT(*) FROM notes"); n != 1 {
+		t.Errorf("Expected 1 note, got %d", n)
+	}
+
+	// Every in-memory database is a separate one
+	other, err := NewSQLiteDB(models.MemoryDatabase, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to open in-memory database: %v", err)
+	}
+	defer other.Close()
+	if exists, _ := other.tableExists("notes"); exists {
+		t.Error("Expected a second in-memory database to be empty")
+	}
+
+	if _, err := NewSQLiteDBWithOptions(models.MemoryDatabase, models.DatabaseOptions{ReadOnly: true}, logger.NewTestLogger()); err == nil {
+		t.Error("Expected an error for a read-only in-memory database")
+	}
+}
+
+func TestMemory_ConcurrentReadsAndWrites(t *testing.T) {
+	db, err := NewSQLiteDB(models.MemoryDatabase, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to open in-memory database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Execute("CREATE TABLE t (id INTEGER PRIMARY KEY, v TEXT)"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// Readers wait for the writer instead of failing with a table lock
+	var wg sync.WaitGroup
+	errs := make(chan error, 4*200+8*200)
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if _, err := db.InsertRows(models.InsertRowsOptions{Table: "t", Rows: []map[string]any{{"v": "x"}}}); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	for r := 0; r < 8; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if _, err := db.Query("SELECT COUNT(*) FROM t"); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	for err := range errs {
+		if failed == 0 {
+			t.Errorf("Concurrent statement failed: %v", err)
+		}
+		failed++
+	}
+	if failed > 0 {
+		t.Errorf("%d concurrent statements failed", failed)
+	}
+	if n := countRows(t, db, "SELECT COUNT(*) FROM t"); n != 800 {
+		t.Errorf("Expected 800 rows, got %d", n)
+	}
+}
+
+func TestMemory_SeedDespitePolicy(t *testing.T) {
+	script := writeScript(t, t.TempDir(), "seed.sql", "CREATE TABLE notes (body TEXT);\nINSERT INTO notes VALUES ('a'), ('b');\n")
+	db := openWithOptions(t, models.MemoryDatabase, models.DatabaseOptions{Policy: models.PolicyNoDDL})
+
+	result, err := db.Seed([]string{script})
+	if err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	if result.Statements != 2 || result.Tables != 1 {
+		t.Errorf("Expected 2 statements and 1 table, got %+v", result)
+	}
+	if _, err := db.Execute("CREATE TABLE other (x)"); err == nil {
+		t.Error("Expected the policy to apply again after seeding")
+	}
+}
+
+func TestEphemeralDir_CopiesTemplates(t *testing.T) {
+	template := newTestDBWithSchema(t, "template.db",
+		"CREATE TABLE items (id INTEGER PRIMARY KEY)",
+		"INSERT INTO items VALUES (1), (2)")
+	templatePath := template.Path()
+	template.Close()
+
+	dir, err := NewEphemeralDir()
+	if err != nil {
+		t.Fatalf("NewEphemeralDir failed: %v", err)
+	}
+	path, err := dir.Copy("shop", templatePath)
+	if err != nil {
+		t.Fatalf("Copy failed: %v", err)
+	}
+
+	db, err := NewSQLiteDB(path, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to open copy: %v", err)
+	}
+	if _, err := db.Execute("DELETE FROM items"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	db.Close()
+
+	original := openWithOptions(t, templatePath, models.DatabaseOptions{ReadOnly: true})
+	if n := countRows(t, original, "SELECT COUNT(*) FROM items"); n != 2 {
+		t.Errorf("Expected the template to keep 2 items, got %d", n)
+	}
+
+	missing, err := dir.Copy("new", filepath.Join(t.TempDir(), "missing.db"))
+	if err != nil || filepath.Dir(missing) != filepath.Dir(path) {
+		t.Errorf("Expected a path in the directory for a missing template, got %s (%v)", missing, err)
+	}
+
+	if err := dir.Remove(); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the copy to be removed, got: %v", err)
+	}
+}
@@ -0,0 +1,524 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+// migrationsTable records applied migrations. It keeps the conventional name
+// used by other migration tools rather than the _mcp_ prefix, so it shows up in
+// the schema like any other table.
+const migrationsTable = "schema_migrations"
+
+// migrationFilePattern matches 0001_create_users.up.sql, 0001_create_users.down.sql
+// and 0001_create_users.sql (up only)
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w[\w-]*?)(?:\.(up|down))?\.sql$`)
+
+var errNoMigrationsDir = errors.New("migrations directory is not configured")
+
+// SetMigrationsDir sets the directory holding the numbered migration files
+func (s *SQLiteDB) SetMigrationsDir(dir string) {
+	s.migrationsDir = dir
+}
+
+// ListMigrations returns the migrations found in the migrations directory and
+// in schema_migrations, ordered by version, with their status
+func (s *SQLiteDB) ListMigrations() ([]models.Migration, error) {
+	s.logger.Debug("Listing migrations")
+
+	migrations, err := s.migrations(context.Background(), s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Listed %d migrations", len(migrations))
+	return migrations, nil
+}
+
+// ApplyMigrations applies pending migrations in version order up to and
+// including target, or all of them when target is 0. Each migration runs in
+// its own transaction together with its schema_migrations record. Nothing is
+// applied when an already applied migration file was modified.
+func (s *SQLiteDB) ApplyMigrations(target int64) ([]models.Migration, error) {
+	s.logger.Debugf("Applying migrations up to %d", target)
+
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to get connection: %v", err)
+		return nil, fmt.Errorf("failed to apply migrations")
+	}
+	defer conn.Close()
+	// Migration files are written by clients, the policy applies to them in full
+	restore, err := s.clientAuthorizer(conn)
+	if err != nil {
+		s.logger.Errorf("Failed to set the authorizer: %v", err)
+		return nil, fmt.Errorf("failed to apply migrations")
+	}
+	defer restore()
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		s.logger.Errorf("Failed to create %s table: %v", migrationsTable, err)
+		return nil, fmt.Errorf("failed to create %s table", migrationsTable)
+	}
+
+	migrations, err := s.migrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range migrations {
+		if m.Status == models.MigrationModified {
+			return nil, fmt.Errorf("checksum mismatch: migration %s was modified after it was applied", migrationLabel(m))
+		}
+	}
+
+	var applied []models.Migration
+	for _, m := range migrations {
+		if m.Status != models.MigrationPending || (target > 0 && m.Version > target) {
+			continue
+		}
+
+		up, err := os.ReadFile(m.UpPath)
+		if err != nil {
+			s.logger.Errorf("Failed to read migration %s: %v", m.UpPath, err)
+			return applied, fmt.Errorf("failed to read migration %s", migrationLabel(m))
+		}
+		if checksum(up) != m.Checksum {
+			return applied, fmt.Errorf("checksum mismatch: migration %s changed while applying", migrationLabel(m))
+		}
+		if err := s.checkClientStatement(string(up)); err != nil {
+			return applied, fmt.Errorf("migration %s failed: %w", migrationLabel(m), err)
+		}
+
+		err = runMigration(ctx, conn, string(up), func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "INSERT INTO "+migrationsTable+" (version, name, checksum) VALUES (?, ?, ?)",
+				m.Version, m.Name, m.Checksum)
+			return err
+		})
+		if err != nil {
+			s.logger.Errorf("Migration %s failed: %v", migrationLabel(m), err)
+			return applied, fmt.Errorf("migration %s failed: %v", migrationLabel(m), err)
+		}
+
+		s.logger.Infof("Applied migration %s", migrationLabel(m))
+		m.Status = models.MigrationApplied
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+// RevertMigrations rolls back the given number of most recently applied
+// migrations by running their down files, newest first
+func (s *SQLiteDB) RevertMigrations(steps int) ([]models.Migration, error) {
+	s.logger.Debugf("Reverting %d migrations", steps)
+
+	if steps <= 0 {
+		steps = 1
+	}
+
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to get connection: %v", err)
+		return nil, fmt.Errorf("failed to revert migrations")
+	}
+	defer conn.Close()
+	// Migration files are written by clients, the policy applies to them in full
+	restore, err := s.clientAuthorizer(conn)
+	if err != nil {
+		s.logger.Errorf("Failed to set the authorizer: %v", err)
+		return nil, fmt.Errorf("failed to revert migrations")
+	}
+	defer restore()
+
+	migrations, err := s.migrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []models.Migration
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		m := migrations[i]
+		switch m.Status {
+		case models.MigrationPending:
+			continue
+		case models.MigrationMissing:
+			return reverted, fmt.Errorf("cannot revert migration %s: migration file is missing", migrationLabel(m))
+		case models.MigrationModified:
+			return reverted, fmt.Errorf("checksum mismatch: migration %s was modified after it was applied", migrationLabel(m))
+		}
+		if !m.HasDown {
+			return reverted, fmt.Errorf("cannot revert migration %s: no down migration", migrationLabel(m))
+		}
+
+		down, err := os.ReadFile(m.DownPath)
+		if err != nil {
+			s.logger.Errorf("Failed to read migration %s: %v", m.DownPath, err)
+			return reverted, fmt.Errorf("failed to read migration %s", migrationLabel(m))
+		}
+		if err := s.checkClientStatement(string(down)); err != nil {
+			return reverted, fmt.Errorf("reverting migration %s failed: %w", migrationLabel(m), err)
+		}
+
+		err = runMigration(ctx, conn, string(down), func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "DELETE FROM "+migrationsTable+" WHERE version = ?", m.Version)
+			return err
+		})
+		if err != nil {
+			s.logger.Errorf("Reverting migration %s failed: %v", migrationLabel(m), err)
+			return reverted, fmt.Errorf("reverting migration %s failed: %v", migrationLabel(m), err)
+		}
+
+		s.logger.Infof("Reverted migration %s", migrationLabel(m))
+		m.Status = models.MigrationPending
+		m.AppliedAt = ""
+		reverted = append(reverted, m)
+	}
+
+	return reverted, nil
+}
+
+// CreateMigration writes up (and optionally down) SQL as the next numbered
+// migration file. The SQL is checked by running it after any pending
+// migrations in a transaction that is rolled back, so the migration is
+// recorded but not applied.
+func (s *SQLiteDB) CreateMigration(name, up, down string) (*models.Migration, error) {
+	s.logger.Debugf("Creating migration %s", name)
+
+	if s.migrationsDir == "" {
+		return nil, errNoMigrationsDir
+	}
+	slug := migrationSlug(name)
+	if slug == "" {
+		return nil, fmt.Errorf("migration name must contain letters or digits")
+	}
+	if strings.TrimSpace(up) == "" {
+		return nil, fmt.Errorf("migration SQL is empty")
+	}
+
+	ctx := context.Background()
+	migrations, err := s.migrations(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkMigrationSQL(ctx, migrations, up); err != nil {
+		return nil, err
+	}
+
+	var version int64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	if err := os.MkdirAll(s.migrationsDir, 0o755); err != nil {
+		s.logger.Errorf("Failed to create migrations directory: %v", err)
+		return nil, fmt.Errorf("failed to create migrations directory")
+	}
+
+	base := fmt.Sprintf("%04d_%s", version, slug)
+	m := &models.Migration{
+		Version:  version,
+		Name:     slug,
+		Status:   models.MigrationPending,
+		Checksum: checksum([]byte(migrationContent(up))),
+		UpPath:   filepath.Join(s.migrationsDir, base+".up.sql"),
+	}
+	if err := writeNewFile(m.UpPath, migrationContent(up)); err != nil {
+		s.logger.Errorf("Failed to write migration %s: %v", m.UpPath, err)
+		return nil, fmt.Errorf("failed to write migration file")
+	}
+	if strings.TrimSpace(down) != "" {
+		m.HasDown = true
+		m.DownPath = filepath.Join(s.migrationsDir, base+".down.sql")
+		if err := writeNewFile(m.DownPath, migrationContent(down)); err != nil {
+			s.logger.Errorf("Failed to write migration %s: %v", m.DownPath, err)
+			os.Remove(m.UpPath)
+			return nil, fmt.Errorf("failed to write migration file")
+		}
+	}
+
+	s.logger.Infof("Created migration %s", m.UpPath)
+	return m, nil
+}
+
+// checkMigrationSQL runs the pending migrations followed by the new statements
+// in a transaction that is always rolled back
+func (s *SQLiteDB) checkMigrationSQL(ctx context.Context, migrations []models.Migration, statements string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Errorf("Failed to begin transaction: %v", err)
+		return fmt.Errorf("failed to check migration SQL")
+	}
+	defer tx.Rollback()
+
+	for _, m := range migrations {
+		if m.Status != models.MigrationPending {
+			continue
+		}
+		pending, err := os.ReadFile(m.UpPath)
+		if err != nil {
+			s.logger.Errorf("Failed to read migration %s: %v", m.UpPath, err)
+			return fmt.Errorf("failed to read migration %s", migrationLabel(m))
+		}
+		if err := checkTransactionControl(string(pending)); err != nil {
+			return fmt.Errorf("pending migration %s is invalid: %v", migrationLabel(m), err)
+		}
+		if _, err := tx.ExecContext(ctx, string(pending)); err != nil {
+			return fmt.Errorf("pending migration %s is invalid: %v", migrationLabel(m), err)
+		}
+	}
+
+	if err := checkTransactionControl(statements); err != nil {
+		return fmt.Errorf("migration SQL is invalid: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return fmt.Errorf("migration SQL is invalid: %v", err)
+	}
+	return nil
+}
+
+// transactionKeywords start the statements that control transactions
+var transactionKeywords = map[string]bool{
+	"BEGIN": true, "COMMIT": true, "END": true, "SAVEPOINT": true, "RELEASE": true, "ROLLBACK": true,
+}
+
+// checkTransactionControl rejects SQL that controls transactions itself.
+// Migrations run inside a transaction of their own, which a COMMIT in the
+// migration would end, leaving the rest of it applied outside of it.
+func checkTransactionControl(statements string) error {
+	reader := newSQLStatementReader(strings.NewReader(statements))
+	for {
+		statement, line, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		words := strings.Fields(strings.ReplaceAll(sqlLiteralPattern.ReplaceAllString(statement, " "), ";", " "))
+		if len(words) == 0 {
+			continue
+		}
+		if keyword := strings.ToUpper(words[0]); transactionKeywords[keyword] {
+			return fmt.Errorf("statement at line %d is %s, migrations run in a transaction of their own and cannot control transactions", line, keyword)
+		}
+	}
+}
+
+// queryer is implemented by both *sql.DB and *sql.Conn
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// migrations merges the migration files with the applied migrations
+func (s *SQLiteDB) migrations(ctx context.Context, q queryer) ([]models.Migration, error) {
+	if s.migrationsDir == "" {
+		return nil, errNoMigrationsDir
+	}
+
+	files, err := readMigrationFiles(s.migrationsDir)
+	if err != nil {
+		s.logger.Errorf("Failed to read migrations directory %s: %v", s.migrationsDir, err)
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(ctx, q)
+	if err != nil {
+		s.logger.Errorf("Failed to read %s: %v", migrationsTable, err)
+		return nil, fmt.Errorf("failed to read %s", migrationsTable)
+	}
+
+	for version, record := range applied {
+		file, ok := files[version]
+		if !ok {
+			record.Status = models.MigrationMissing
+			files[version] = &record
+			continue
+		}
+		file.AppliedAt = record.AppliedAt
+		file.Status = models.MigrationApplied
+		if file.Checksum != record.Checksum {
+			file.Status = models.MigrationModified
+		}
+	}
+
+	migrations := make([]models.Migration, 0, len(files))
+	for _, m := range files {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// readMigrationFiles indexes the migration files of dir by version. A missing
+// directory has no migrations.
+func readMigrationFiles(dir string) (map[int64]*models.Migration, error) {
+	migrations := make(map[int64]*models.Migration)
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return migrations, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory %s", dir)
+	}
+
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+
+		m, ok := migrations[version]
+		if !ok {
+			m = &models.Migration{Version: version, Name: match[2], Status: models.MigrationPending}
+			migrations[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, m.Name, match[2])
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if match[3] == "down" {
+			m.DownPath = path
+			m.HasDown = true
+			continue
+		}
+		if m.UpPath != "" {
+			return nil, fmt.Errorf("duplicate up migration for version %d", version)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s", entry.Name())
+		}
+		m.UpPath = path
+		m.Checksum = checksum(content)
+	}
+
+	for _, m := range migrations {
+		if m.UpPath == "" {
+			return nil, fmt.Errorf("migration %s has a down file but no up file", migrationLabel(*m))
+		}
+	}
+
+	return migrations, nil
+}
+
+func appliedMigrations(ctx context.Context, q queryer) (map[int64]models.Migration, error) {
+	applied := make(map[int64]models.Migration)
+
+	var count int
+	err := q.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", migrationsTable).Scan(&count)
+	if err != nil || count == 0 {
+		return applied, err
+	}
+
+	rows, err := q.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM "+migrationsTable+" ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m models.Migration
+		if err := rows.Scan(&m.Version, &m.Name, &m.Checksum, &m.AppliedAt); err != nil {
+			return nil, err
+		}
+		m.Status = models.MigrationApplied
+		applied[m.Version] = m
+	}
+	return applied, rows.Err()
+}
+
+func ensureMigrationsTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+migrationsTable+` (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+// runMigration executes the migration statements and its bookkeeping in one transaction
+func runMigration(ctx context.Context, conn *sql.Conn, statements string, record func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTransactionControl(statements); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return err
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// migrationSlug turns a free form name into a file name friendly one
+func migrationSlug(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+func migrationContent(statements string) string {
+	return strings.TrimSpace(statements) + "\n"
+}
+
+func migrationLabel(m models.Migration) string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// writeNewFile writes content to path, failing if the file already exists
+func writeNewFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func setupMigrationsDB(t *testing.T, files map[string]string) *SQLiteDB {
+	t.Helper()
+
+	db := newTestDBWithSchema(t, "migrations.db")
+	dir := filepath.Join(t.TempDir(), "migrations")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create migrations directory: %v", err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	db.SetMigrationsDir(dir)
+	return db
+}
+
+func migrationStatuses(t *testing.T, db *SQLiteDB) string {
+	t.Helper()
+
+	migrations, err := db.ListMigrations()
+	if err != nil {
+		t.Fatalf("ListMigrations failed: %v", err)
+	}
+	statuses := make([]string, len(migrations))
+	for i, m := range migrations {
+		statuses[i] = migrationLabel(m) + "=" + m.Status
+	}
+	return strings.Join(statuses, " ")
+}
+
+func TestApplyAndRevertMigrations(t *testing.T) {
+	db := setupMigrationsDB(t, map[string]string{
+		"0001_create_accounts.up.sql":   "CREATE TABLE accounts (id INTEGER PRIMARY KEY, name TEXT);",
+		"0001_create_accounts.down.sql": "DROP TABLE accounts;",
+		"0002_add_balance.up.sql":       "ALTER TABLE accounts ADD COLUMN balance INTEGER NOT NULL DEFAULT 0;",
+		"0002_add_balance.down.sql":     "ALTER TABLE accounts DROP COLUMN balance;",
+		"README.md":                     "not a migration",
+	})
+
+	if got := migrationStatuses(t, db); got != "0001_create_accounts=pending 0002_add_balance=pending" {
+		t.Fatalf("Unexpected statuses: %s", got)
+	}
+
+	applied, err := db.ApplyMigrations(1)
+	if err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 1 {
+		t.Fatalf("Expected only migration 1 to be applied, got %+v", applied)
+	}
+
+	if _, err := db.ApplyMigrations(0); err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+	if got := migrationStatuses(t, db); got != "0001_create_accounts=applied 0002_add_balance=applied" {
+		t.Fatalf("Unexpected statuses: %s", got)
+	}
+	if _, err := db.Execute("INSERT INTO accounts (name, balance) VALUES ('a', 10)"); err != nil {
+		t.Fatalf("Expected migrated schema, got %v", err)
+	}
+
+	reverted, err := db.RevertMigrations(1)
+	if err != nil {
+		t.Fatalf("RevertMigrations failed: %v", err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Fatalf("Expected migration 2 to be reverted, got %+v", reverted)
+	}
+	if got := migrationStatuses(t, db); got != "0001_create_accounts=applied 0002_add_balance=pending" {
+		t.Errorf("Unexpected statuses: %s", got)
+	}
+}
+
+func TestApplyMigrations_FailureRollsBack(t *testing.T) {
+	db := setupMigrationsDB(t, map[string]string{
+		"0001_good.sql": "CREATE TABLE good (id INTEGER PRIMARY KEY);",
+		"0002_bad.sql":  "CREATE TABLE partial (id INTEGER); INSERT INTO missing VALUES (1);",
+	})
+
+	applied, err := db.ApplyMigrations(0)
+	if err == nil {
+		t.Fatal("Expected migration 2 to fail")
+	}
+	if len(applied) != 1 {
+		t.Errorf("Expected migration 1 to be applied, got %+v", applied)
+	}
+
+	exists, err := db.tableExists("partial")
+	if err != nil {
+		t.Fatalf("tableExists failed: %v", err)
+	}
+	if exists {
+		t.Error("Expected failed migration to be rolled back")
+	}
+	if got := migrationStatuses(t, db); got != "0001_good=applied 0002_bad=pending" {
+		t.Errorf("Unexpected statuses: %s", got)
+	}
+
+	// Migration files are checked too, a COMMIT would end the migration's own transaction
+	if err := os.WriteFile(filepath.Join(db.migrationsDir, "0002_bad.sql"), []byte("CREATE TABLE partial (id INTEGER); COMMIT;"), 0o644); err != nil {
+		t.Fatalf("Failed to write migration: %v", err)
+	}
+	if _, err := db.ApplyMigrations(0); err == nil || !strings.Contains(err.Error(), "cannot control transactions") {
+		t.Errorf("Expected transaction control to be rejected, got %v", err)
+	}
+	if exists, _ := db.tableExists("partial"); exists {
+		t.Error("Expected the rejected migration not to run")
+	}
+}
+
+func TestApplyMigrations_ChecksumMismatch(t *testing.T) {
+	db := setupMigrationsDB(t, map[string]string{
+		"0001_init.sql": "CREATE TABLE init (id INTEGER PRIMARY KEY);",
+	})
+	if _, err := db.ApplyMigrations(0); err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+
+	path := filepath.Join(db.migrationsDir, "0001_init.sql")
+	if err := os.WriteFile(path, []byte("CREATE TABLE init (id INTEGER PRIMARY KEY, name TEXT);"), 0o644); err != nil {
+		t.Fatalf("Failed to modify migration: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(db.migrationsDir, "0002_next.sql"), []byte("CREATE TABLE next (id INTEGER);"), 0o644); err != nil {
+		t.Fatalf("Failed to write migration: %v", err)
+	}
+
+	if got := migrationStatuses(t, db); got != "0001_init="+models.MigrationModified+" 0002_next=pending" {
+		t.Errorf("Unexpected statuses: %s", got)
+	}
+	if _, err := db.ApplyMigrations(0); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected checksum mismatch error, got %v", err)
+	}
+
+	os.Remove(path)
+	if got := migrationStatuses(t, db); got != "0001_init="+models.MigrationMissing+" 0002_next=pending" {
+		t.Errorf("Unexpected statuses: %s", got)
+	}
+}
+
+func TestCreateMigration(t *testing.T) {
+	db := setupMigrationsDB(t, map[string]string{
+		"0001_create_items.sql": "CREATE TABLE items (id INTEGER PRIMARY KEY);",
+	})
+
+	// Validated against the pending migration 1
+	m, err := db.CreateMigration("Add items.price!", "ALTER TABLE items ADD COLUMN price REAL;", "ALTER TABLE items DROP COLUMN price;")
+	if err != nil {
+		t.Fatalf("CreateMigration failed: %v", err)
+	}
+	if m.Version != 2 || m.Name != "add_items_price" || !m.HasDown {
+		t.Errorf("Unexpected migration: %+v", m)
+	}
+	if filepath.Base(m.UpPath) != "0002_add_items_price.up.sql" {
+		t.Errorf("Unexpected file name: %s", m.UpPath)
+	}
+
+	exists, err := db.tableExists("items")
+	if err != nil {
+		t.Fatalf("tableExists failed: %v", err)
+	}
+	if exists {
+		t.Error("Expected CreateMigration not to apply any migration")
+	}
+
+	if _, err := db.CreateMigration("broken", "ALTER TABLE nope ADD COLUMN x;", ""); err == nil {
+		t.Error("Expected invalid SQL to be rejected")
+	}
+
+	// A COMMIT would end the transaction the check rolls back
+	for _, up := range []string{
+		"CREATE TABLE escaped (id INTEGER); COMMIT; CREATE TABLE after (id INTEGER);",
+		"-- note\nbegin transaction; CREATE TABLE escaped (id INTEGER); end;",
+		"SAVEPOINT s; CREATE TABLE escaped (id INTEGER); RELEASE s;",
+	} {
+		if _, err := db.CreateMigration("escape", up, ""); err == nil || !strings.Contains(err.Error(), "cannot control transactions") {
+			t.Errorf("Expected transaction control to be rejected in %q, got %v", up, err)
+		}
+	}
+	if exists, _ := db.tableExists("escaped"); exists {
+		t.Error("Expected the rejected migration not to change the database")
+	}
+	if _, err := db.CreateMigration("trigger", "CREATE TABLE log (id INTEGER); CREATE TRIGGER items_log AFTER INSERT ON items BEGIN INSERT INTO log VALUES (NEW.id); END;", ""); err != nil {
+		t.Errorf("Expected a trigger body to be allowed, got %v", err)
+	}
+
+	applied, err := db.ApplyMigrations(0)
+	if err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+	if len(applied) != 3 {
+		t.Errorf("Expected 3 migrations to be applied, got %+v", applied)
+	}
+}
+
+func TestMigrations_NotConfigured(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.ListMigrations(); err != errNoMigrationsDir {
+		t.Errorf("Expected %v, got %v", errNoMigrationsDir, err)
+	}
+}
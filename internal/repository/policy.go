@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"go.uber.org/zap"
+)
+
+// ErrNotAllowed is returned for statements the database's read-only mode or
+// policy rejects
+var ErrNotAllowed = errors.New("not allowed")
+
+// NewSQLiteDBWithOptions opens a database read-only or under a policy, with
+// secondary databases attached. The policy is enforced by an SQLite authorizer
+// on every connection, so it applies to every statement regardless of which
+// tool runs it, and can be changed with SetPolicy while the database is open.
+// The same authorizer denies ATTACH and DETACH, leaving the configured
+// attachments as the only other files statements can reach.
+func NewSQLiteDBWithOptions(dbPath string, opts models.DatabaseOptions, logger *zap.SugaredLogger) (*SQLiteDB, error) {
+	policy, err := newAccessPolicy(opts.Policy)
+	if err != nil {
+		return nil, err
+	}
+	access := &accessControl{}
+	access.policy.Store(policy)
+	return openWithAccess(dbPath, opts, access, logger)
+}
+
+// openWithAccess opens a database enforcing the policy of access, which may be
+// shared with another database so that SetPolicy on either applies to both.
+// The Policy of opts is ignored.
+func openWithAccess(dbPath string, opts models.DatabaseOptions, access *accessControl, logger *zap.SugaredLogger) (*SQLiteDB, error) {
+	if err := validateAttachments(opts.Attach); err != nil {
+		return nil, err
+	}
+	pragmas, err := connectionPragmas(opts.Connection, opts.ReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	readers := opts.Connection.Readers
+	if readers == 0 {
+		readers = defaultReaders
+	}
+
+	dsn := dbPath
+	switch {
+	case dbPath == models.MemoryDatabase:
+		if opts.ReadOnly {
+			return nil, fmt.Errorf("an in-memory database cannot be read-only")
+		}
+		dsn = memoryDSN()
+	case opts.ReadOnly:
+		if _, err := os.Stat(dbPath); err != nil {
+			return nil, fmt.Errorf("database file %s does not exist", dbPath)
+		}
+		dsn = readOnlyDSN(dbPath)
+	}
+
+	writer := newDriver(access, opts.Attach, pragmas)
+	reader := newDriver(access, opts.Attach, append(slices.Clone(pragmas), "PRAGMA query_only = ON"))
+	db, err := openSQLiteDB(writer, reader, dsn, readers, logger)
+	if err != nil {
+		return nil, err
+	}
+	if dbPath == models.MemoryDatabase {
+		if err := db.pinMemory(); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to open in-memory database: %w", err)
+		}
+	}
+	db.path = dbPath
+	db.readOnly = opts.ReadOnly
+	db.access = access
+	db.attachments = opts.Attach
+	db.connection = opts.Connection
+	return db, nil
+}
+
+// ReadOnly reports whether the database was opened read-only
+func (s *SQLiteDB) ReadOnly() bool {
+	return s.readOnly
+}
+
+// Policy returns the policy limiting the statements run against the database
+func (s *SQLiteDB) Policy() string {
+	return s.access.policy.Load().name
+}
+
+// SetPolicy replaces the policy of the database. It applies to the statements
+// prepared afterwards on every connection, including those already open, and
+// to the open branches of the database.
+func (s *SQLiteDB) SetPolicy(policy string) error {
+	access, err := newAccessPolicy(policy)
+	if err != nil {
+		return err
+	}
+	s.access.policy.Store(access)
+	return nil
+}
+
+// checkFullAccess rejects operations that replace the database file as a
+// whole, which bypasses the authorizer
+func (s *SQLiteDB) checkFullAccess() error {
+	if s.readOnly {
+		return fmt.Errorf("%w: the database is read-only", ErrNotAllowed)
+	}
+	if policy := s.Policy(); policy != models.PolicyFull {
+		return fmt.Errorf("%w: the %s policy of this database only permits this with the full policy", ErrNotAllowed, policy)
+	}
+	return nil
+}
+
+// statementError explains errors caused by the read-only mode, policy or
+// ATTACH guard, which are otherwise reported as "not authorized" or
+// "readonly database"
+func (s *SQLiteDB) statementError(query string, err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return nil
+	}
+	switch sqliteErr.Code {
+	case sqlite3.ErrAuth:
+		policy := s.Policy()
+		if attachPattern.MatchString(query) || policy == models.PolicyFull {
+			return fmt.Errorf("%w: statements may not attach other database files, only the databases attached by the server configuration can be used", ErrNotAllowed)
+		}
+		return fmt.Errorf("%w: the %s policy of this database rejects this statement", ErrNotAllowed, policy)
+	case sqlite3.ErrReadonly:
+		if s.readOnly {
+			return fmt.Errorf("%w: the database is read-only", ErrNotAllowed)
+		}
+		if len(s.attachments) > 0 {
+			return fmt.Errorf("%w: the statement writes to an attached database that is attached read-only", ErrNotAllowed)
+		}
+	}
+	return nil
+}
+
+// newDriver returns a driver whose connections run the pragmas, attach the
+// given databases and then install the authorizer of the access control
+func newDriver(access *accessControl, attachments []models.Attachment, pragmas []string) *sqlite3.SQLiteDriver {
+	attachments = slices.Clone(attachments)
+	pragmas = slices.Clone(pragmas)
+	return &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			for _, pragma := range pragmas {
+				if _, err := conn.Exec(pragma, nil); err != nil {
+					return fmt.Errorf("failed to run %s: %w", pragma, err)
+				}
+			}
+			if err := attachDatabases(conn, attachments); err != nil {
+				return err
+			}
+			conn.RegisterAuthorizer(access.authorize)
+			return nil
+		},
+	}
+}
+
+// connector opens connections to one database through a driver, so each
+// database gets its own connect hook without registering a driver by name
+type connector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// accessControl holds the policy of a database. Every connection's authorizer
+// reads it, so replacing the policy applies to connections already open.
+type accessControl struct {
+	policy atomic.Pointer[accessPolicy]
+}
+
+// accessPolicy is a policy with the authorizers enforcing it
+type accessPolicy struct {
+	name     string
+	restrict authorizer // For the server's own statements, nil for the full policy
+	client   authorizer // For statements written by clients, nil for the full policy
+}
+
+func newAccessPolicy(policy string) (*accessPolicy, error) {
+	if policy == "" {
+		policy = models.PolicyFull
+	}
+	access := &accessPolicy{name: policy}
+	if policy != models.PolicyFull {
+		if access.restrict = policyAuthorizer(policy, true); access.restrict == nil {
+			return nil, fmt.Errorf("invalid policy %s, expected %s, %s or %s", policy, models.PolicyFull, models.PolicyNoDDL, models.PolicyAppendOnly)
+		}
+		access.client = policyAuthorizer(policy, false)
+	}
+	return access, nil
+}
+
+// authorize is the authorizer of every connection: it enforces the current
+// policy and denies ATTACH and DETACH. The filename SQLite passes for ATTACH
+// cannot tell a literal from an expression, so the repository's own attaches
+// lift the authorizer on their connection instead, see withoutAuthorizer.
+func (a *accessControl) authorize(op int, arg1, arg2, dbName string) int {
+	if op == sqlite3.SQLITE_ATTACH || op == sqlite3.SQLITE_DETACH {
+		return sqlite3.SQLITE_DENY
+	}
+	if restrict := a.policy.Load().restrict; restrict != nil {
+		return restrict(op, arg1, arg2, dbName)
+	}
+	return sqlite3.SQLITE_OK
+}
+
+// authorizeClient is the authorizer of connections running statements written
+// by clients, see asClient. Unlike authorize it gives the server's _mcp_
+// tables no exemption from the policy.
+func (a *accessControl) authorizeClient(op int, arg1, arg2, dbName string) int {
+	if op == sqlite3.SQLITE_ATTACH || op == sqlite3.SQLITE_DETACH {
+		return sqlite3.SQLITE_DENY
+	}
+	if client := a.policy.Load().client; client != nil {
+		return client(op, arg1, arg2, dbName)
+	}
+	return sqlite3.SQLITE_OK
+}
+
+// asClient runs fn on a pinned writer connection whose authorizer treats the
+// statements as written by a client rather than by the server itself
+func (s *SQLiteDB) asClient(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	restore, err := s.clientAuthorizer(conn)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	return fn(conn)
+}
+
+// clientAuthorizer switches a pinned connection to the authorizer of client
+// statements and returns a function switching it back
+func (s *SQLiteDB) clientAuthorizer(conn *sql.Conn) (func(), error) {
+	if err := setAuthorizer(conn, s.access.authorizeClient); err != nil {
+		return nil, err
+	}
+	return func() {
+		if err := setAuthorizer(conn, s.access.authorize); err != nil {
+			s.logger.Errorf("Failed to restore the authorizer: %v", err)
+		}
+	}, nil
+}
+
+// replacePattern matches REPLACE INTO and INSERT OR REPLACE, which delete the
+// conflicting rows without asking the authorizer
+var replacePattern = regexp.MustCompile(`(?i)\b(OR\s+REPLACE|REPLACE\s+INTO)\b`)
+
+// checkClientStatement rejects what the authorizer cannot see: replacing rows
+// under the append-only policy
+func (s *SQLiteDB) checkClientStatement(query string) error {
+	if s.Policy() == models.PolicyAppendOnly && replacePattern.MatchString(sqlLiteralPattern.ReplaceAllString(query, "")) {
+		return fmt.Errorf("%w: the %s policy of this database rejects replacing rows", ErrNotAllowed, models.PolicyAppendOnly)
+	}
+	return nil
+}
+
+// authorizer is an SQLite authorizer callback
+type authorizer func(op int, arg1, arg2, dbName string) int
+
+// policyAuthorizer returns the authorizer callback enforcing a policy.
+// Temporary objects are exempt, and with exemptInternal the server's own _mcp_
+// tables too.
+func policyAuthorizer(policy string, exemptInternal bool) authorizer {
+	internal := func(table string) bool {
+		return exemptInternal && isInternalTable(table)
+	}
+	switch policy {
+	case models.PolicyNoDDL:
+		return func(op int, arg1, arg2, dbName string) int {
+			if changesSchema(op, arg1, arg2, internal) {
+				return sqlite3.SQLITE_DENY
+			}
+			return sqlite3.SQLITE_OK
+		}
+	case models.PolicyAppendOnly:
+		return func(op int, arg1, arg2, dbName string) int {
+			if changesSchema(op, arg1, arg2, internal) {
+				return sqlite3.SQLITE_DENY
+			}
+			if (op == sqlite3.SQLITE_UPDATE || op == sqlite3.SQLITE_DELETE) && dbName != "temp" &&
+				!internal(arg1) && !strings.HasPrefix(strings.ToLower(arg1), "sqlite_") {
+				return sqlite3.SQLITE_DENY
+			}
+			return sqlite3.SQLITE_OK
+		}
+	}
+	return nil
+}
+
+// changesSchema reports whether an authorizer action creates, alters or drops
+// a persistent schema object other than an exempt table and its indexes
+func changesSchema(op int, arg1, arg2 string, exempt func(table string) bool) bool {
+	switch op {
+	case sqlite3.SQLITE_CREATE_TABLE, sqlite3.SQLITE_DROP_TABLE:
+		return !exempt(arg1)
+	case sqlite3.SQLITE_CREATE_INDEX, sqlite3.SQLITE_DROP_INDEX:
+		// The table is the second argument
+		return !exempt(arg2)
+	case sqlite3.SQLITE_ALTER_TABLE,
+		// The authorizer is not told the new name of ALTER TABLE RENAME, which
+		// could turn an exempt table into a user table
+		sqlite3.SQLITE_CREATE_TRIGGER, sqlite3.SQLITE_DROP_TRIGGER,
+		sqlite3.SQLITE_CREATE_VIEW, sqlite3.SQLITE_DROP_VIEW,
+		sqlite3.SQLITE_CREATE_VTABLE, sqlite3.SQLITE_DROP_VTABLE:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func openWithOptions(t *testing.T, path string, opts models.DatabaseOptions) *SQLiteDB {
+	t.Helper()
+
+	db, err := NewSQLiteDBWithOptions(path, opts, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestPolicy_RestrictsStatements(t *testing.T) {
+	path := newTestDBWithSchema(t, "policy.db", "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)").Path()
+
+	tests := []struct {
+		policy  string
+		allowed []string
+		denied  []string
+	}{
+		{
+			policy:  models.PolicyNoDDL,
+			allowed: []string{"INSERT INTO items (name) VALUES ('a')", "UPDATE items SET name = 'b'", "DELETE FROM items", "CREATE TEMP TABLE scratch (x)"},
+			denied:  []string{"CREATE TABLE other (x)", "ALTER TABLE items ADD COLUMN qty INTEGER", "CREATE INDEX idx ON items (name)", "DROP TABLE items", "CREATE VIEW v AS SELECT 1"},
+		},
+		{
+			policy:  models.PolicyAppendOnly,
+			allowed: []string{"INSERT INTO items (name) VALUES ('a')", "CREATE TEMP TABLE scratch (x)", "DELETE FROM temp.scratch"},
+			denied:  []string{"UPDATE items SET name = 'b'", "DELETE FROM items", "INSERT INTO items (id, name) VALUES (1, 'c') ON CONFLICT (id) DO UPDATE SET name = 'c'", "DROP TABLE items"},
+		},
+	}
+	for _, tt := range tests {
+		db := openWithOptions(t, path, models.DatabaseOptions{Policy: tt.policy})
+		for _, statement := range tt.allowed {
+			if _, err := db.Execute(statement); err != nil {
+				t.Errorf("%s: expected %q to be allowed, got: %v", tt.policy, statement, err)
+			}
+		}
+		for _, statement := range tt.denied {
+			if _, err := db.Execute(statement); !errors.Is(err, ErrNotAllowed) {
+				t.Errorf("%s: expected %q to be denied, got: %v", tt.policy, statement, err)
+			}
+		}
+		// The server's own tables stay usable
+		if _, err := db.Annotate(models.Annotation{Table: "items", Description: "Things"}); err != nil {
+			t.Errorf("%s: expected annotations to work, got: %v", tt.policy, err)
+		}
+		db.Close()
+	}
+
+	if _, err := NewSQLiteDBWithOptions(path, models.DatabaseOptions{Policy: "yolo"}, logger.NewTestLogger()); err == nil {
+		t.Error("Expected an error for an invalid policy")
+	}
+}
+
+func TestPolicy_ReadOnly(t *testing.T) {
+	setup := newTestDBWithSchema(t, "ro.db", "CREATE TABLE items (id INTEGER PRIMARY KEY)")
+	path := setup.Path()
+	setup.Close()
+
+	db := openWithOptions(t, path, models.DatabaseOptions{ReadOnly: true})
+	if !db.ReadOnly() {
+		t.Error("Expected the database to be read-only")
+	}
+	if _, err := db.Execute("INSERT INTO items VALUES (1)"); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Expected the insert to be denied, got: %v", err)
+	}
+	if _, err := db.Query("SELECT * FROM items"); err != nil {
+		t.Errorf("Expected queries to work, got: %v", err)
+	}
+
+	db.SetBackupDir(t.TempDir(), 0)
+	if _, err := db.Restore("x.db"); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Expected restore to be denied, got: %v", err)
+	}
+
+	if _, err := NewSQLiteDBWithOptions(filepath.Join(t.TempDir(), "missing.db"), models.DatabaseOptions{ReadOnly: true}, logger.NewTestLogger()); err == nil {
+		t.Error("Expected an error for a missing read-only database")
+	}
+}
+
+func TestPolicy_SetPolicyAppliesToOpenConnections(t *testing.T) {
+	path := newTestDBWithSchema(t, "reload.db", "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)").Path()
+	db := openWithOptions(t, path, models.DatabaseOptions{Connection: models.ConnectionOptions{Readers: 1}})
+
+	// Open the connections before the policy changes
+	if _, err := db.Execute("INSERT INTO items (name) VALUES ('a')"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if err := db.SetPolicy(models.PolicyAppendOnly); err != nil {
+		t.Fatalf("SetPolicy failed: %v", err)
+	}
+	if db.Policy() != models.PolicyAppendOnly {
+		t.Errorf("Expected the append-only policy, got %s", db.Policy())
+	}
+	if _, err := db.Execute("DELETE FROM items"); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Expected the delete to be denied, got: %v", err)
+	}
+	if _, err := db.Execute("INSERT INTO items (name) VALUES ('b')"); err != nil {
+		t.Errorf("Expected the insert to be allowed, got: %v", err)
+	}
+
+	if err := db.SetPolicy("yolo"); err == nil {
+		t.Error("Expected an error for an invalid policy")
+	}
+	if err := db.SetPolicy(models.PolicyFull); err != nil {
+		t.Fatalf("SetPolicy failed: %v", err)
+	}
+	if _, err := db.Execute("DELETE FROM items"); err != nil {
+		t.Errorf("Expected the delete to be allowed again, got: %v", err)
+	}
+}
+
+func TestPolicy_InternalTablesAndReplace(t *testing.T) {
+	path := newTestDBWithSchema(t, "internal.db", "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)").Path()
+
+	db := openWithOptions(t, path, models.DatabaseOptions{Policy: models.PolicyNoDDL})
+	if _, err := db.Annotate(models.Annotation{Table: "items", Description: "Things"}); err != nil {
+		t.Fatalf("Annotate failed: %v", err)
+	}
+	// The exemption of the server's own tables does not extend to clients
+	for _, statement := range []string{
+		"CREATE TABLE _mcp_evil (x)",
+		"ALTER TABLE _mcp_annotations RENAME TO evil",
+		"DROP TABLE _mcp_annotations",
+		"CREATE INDEX _mcp_idx ON items (name)",
+	} {
+		if _, err := db.Execute(statement); !errors.Is(err, ErrNotAllowed) {
+			t.Errorf("no-ddl: expected %q to be denied, got: %v", statement, err)
+		}
+	}
+	db.Close()
+
+	db = openWithOptions(t, path, models.DatabaseOptions{Policy: models.PolicyAppendOnly})
+	if _, err := db.SaveQuery(models.SavedQuery{Name: "all_items", Description: "All items", SQL: "SELECT * FROM items"}); err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+	if _, err := db.Execute("INSERT INTO items (id, name) VALUES (1, 'a')"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	for _, statement := range []string{
+		"DELETE FROM _mcp_queries",
+		"UPDATE _mcp_queries SET sql = 'SELECT 1'",
+		"DELETE FROM _mcp_annotations",
+		"INSERT OR REPLACE INTO items (id, name) VALUES (1, 'b')",
+		"REPLACE INTO items (id, name) VALUES (1, 'b')",
+		"insert or\nreplace into items (id, name) values (1, 'b')",
+	} {
+		if _, err := db.Execute(statement); !errors.Is(err, ErrNotAllowed) {
+			t.Errorf("append-only: expected %q to be denied, got: %v", statement, err)
+		}
+	}
+	if _, err := db.Execute("INSERT INTO items (name) VALUES ('or replace into'), (replace('a', 'a', 'b'))"); err != nil {
+		t.Errorf("append-only: expected REPLACE in a literal or function to be allowed, got: %v", err)
+	}
+	if err := db.RemoveSavedQuery("all_items"); err != nil {
+		t.Errorf("append-only: expected the server to manage its saved queries, got: %v", err)
+	}
+}
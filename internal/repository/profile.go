@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+const (
+	defaultProfileTopN       = 5
+	maxProfileTopN           = 50
+	defaultProfileSampleRows = 100000
+	maxProfileSampleRows     = 1000000
+)
+
+// ProfileTable computes row count and per-column statistics for a table.
+// Tables larger than the sample limit are profiled on a sample of rows and
+// their distinct counts are estimated with HyperLogLog. Results are cached
+// until the database data version changes.
+func (s *SQLiteDB) ProfileTable(tableName string, opts models.ProfileOptions) (*models.TableProfile, error) {
+	s.logger.Debugf("Profiling table: %s", tableName)
+
+	opts = normalizeProfileOptions(opts)
+
+	exists, err := s.tableExists(tableName)
+	if err != nil {
+		s.logger.Errorf("Failed to look up table %s: %v", tableName, err)
+		return nil, fmt.Errorf("failed to retrieve table information")
+	}
+	if !exists {
+		return nil, fmt.Errorf("table %s does not exist", tableName)
+	}
+
+	version, err := s.dataVersion()
+	if err != nil {
+		s.logger.Errorf("Failed to read data version: %v", err)
+		return nil, fmt.Errorf("failed to profile table")
+	}
+
+	cacheKey := fmt.Sprintf("%s\x00%d\x00%d", tableName, opts.TopN, opts.SampleRows)
+	s.profileMu.Lock()
+	cached, ok := s.profileCache[cacheKey]
+	s.profileMu.Unlock()
+	if ok && cached.DataVersion == version {
+		s.logger.Debugf("Using cached profile for table %s at data version %d", tableName, version)
+		profile := *cached
+		profile.Cached = true
+		return &profile, nil
+	}
+
+	tableInfo, err := s.getTableInfo(tableName)
+	if err != nil {
+		s.logger.Errorf("Failed to get table info for table %s: %v", tableName, err)
+		return nil, fmt.Errorf("failed to retrieve table information")
+	}
+
+	quoted := quoteIdent(tableName)
+
+	var rowCount int64
+	if err := s.reader.QueryRow("SELECT COUNT(*) FROM " + quoted).Scan(&rowCount); err != nil {
+		s.logger.Errorf("Failed to count rows of table %s: %v", tableName, err)
+		return nil, fmt.Errorf("failed to profile table")
+	}
+
+	profile := &models.TableProfile{
+		Table:        tableName,
+		RowCount:     rowCount,
+		SampleRows:   rowCount,
+		SampleMethod: "all",
+		DataVersion:  version,
+		Columns:      make([]models.ColumnProfile, 0, len(tableInfo.Columns)),
+	}
+
+	source := profileSource{query: quoted}
+	if rowCount > int64(opts.SampleRows) {
+		source, err = s.profileSample(tableName, opts.SampleRows, profile)
+		if err != nil {
+			s.logger.Errorf("Failed to sample table %s: %v", tableName, err)
+			return nil, fmt.Errorf("failed to profile table")
+		}
+	}
+
+	for _, column := range tableInfo.Columns {
+		columnProfile, err := s.profileColumn(source, column, profile.SampleRows, profile.Sampled, opts.TopN)
+		if err != nil {
+			s.logger.Errorf("Failed to profile column %s.%s: %v", tableName, column.Name, err)
+			return nil, fmt.Errorf("failed to profile table")
+		}
+		profile.Columns = append(profile.Columns, *columnProfile)
+	}
+
+	s.profileMu.Lock()
+	s.profileCache[cacheKey] = profile
+	s.profileMu.Unlock()
+
+	s.logger.Infof("Profiled table %s, row_count: %d, sample_method: %s", tableName, rowCount, profile.SampleMethod)
+
+	result := *profile
+	return &result, nil
+}
+
+// profileSource is what column statistics are computed on, the table or a
+// subquery selecting a sample of it, with the arguments of the subquery
+type profileSource struct {
+	query string
+	args  []any
+}
+
+// profileSample samples n rows the way SampleRows does, so statistics are not
+// biased towards the first rows of tables ordered by time or key. Rowid
+// tables are sampled once by probing random rowids; tables without rowid use
+// ORDER BY RANDOM(), drawn anew by each statistics query.
+func (s *SQLiteDB) profileSample(tableName string, n int, profile *models.TableProfile) (profileSource, error) {
+	sampler := &rowSampler{db: s.reader, table: quoteIdent(tableName)}
+	profile.Sampled = true
+	if !sampler.checkRowid() {
+		profile.SampleMethod = "random_order"
+		profile.SampleRows = int64(n)
+		return profileSource{query: fmt.Sprintf("(SELECT * FROM %s ORDER BY RANDOM() LIMIT %d)", sampler.table, n)}, nil
+	}
+
+	rowids, err := sampler.sampleRowids(n)
+	if err != nil {
+		return profileSource{}, err
+	}
+	points, err := json.Marshal(rowids)
+	if err != nil {
+		return profileSource{}, err
+	}
+	profile.SampleMethod = "rowid"
+	profile.SampleRows = int64(len(rowids))
+	return profileSource{
+		query: fmt.Sprintf("(SELECT * FROM %s WHERE rowid IN (SELECT value FROM json_each(?)))", sampler.table),
+		args:  []any{string(points)},
+	}, nil
+}
+
+func (s *SQLiteDB) profileColumn(source profileSource, column models.Column, rowCount int64, sampled bool, topN int) (*models.ColumnProfile, error) {
+	col := quoteIdent(column.Name)
+	profile := &models.ColumnProfile{
+		Name:           column.Name,
+		DeclaredType:   column.Type,
+		StorageClasses: make(map[string]int64),
+	}
+
+	// Null count, min and max, plus the exact distinct count when the whole table is scanned
+	var nonNull int64
+	var minValue, maxValue any
+	if sampled {
+		query := fmt.Sprintf("SELECT COUNT(%s), MIN(%s), MAX(%s) FROM %s", col, col, col, source.query)
+		if err := s.reader.QueryRow(query, source.args...).Scan(&nonNull, &minValue, &maxValue); err != nil {
+			return nil, err
+		}
+
+		distinct, err := s.estimateDistinct(source, col)
+		if err != nil {
+			return nil, err
+		}
+		profile.DistinctCount = distinct
+		profile.DistinctApproximate = true
+	} else {
+		query := fmt.Sprintf("SELECT COUNT(%s), COUNT(DISTINCT %s), MIN(%s), MAX(%s) FROM %s", col, col, col, col, source.query)
+		if err := s.reader.QueryRow(query, source.args...).Scan(&nonNull, &profile.DistinctCount, &minValue, &maxValue); err != nil {
+			return nil, err
+		}
+	}
+
+	if rowCount > 0 {
+		profile.NullFraction = float64(rowCount-nonNull) / float64(rowCount)
+	}
+	profile.Min = normalizeValue(minValue)
+	profile.Max = normalizeValue(maxValue)
+
+	// Storage class distribution, SQLite column types are only affinities
+	classRows, err := s.reader.Query(fmt.Sprintf("SELECT typeof(%s), COUNT(*) FROM %s GROUP BY 1", col, source.query), source.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer classRows.Close()
+
+	for classRows.Next() {
+		var class string
+		var count int64
+		if err := classRows.Scan(&class, &count); err != nil {
+			return nil, err
+		}
+		profile.StorageClasses[class] = count
+	}
+	if err := classRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Most frequent non-null values
+	topRows, err := s.reader.Query(fmt.Sprintf(
+		"SELECT %s, COUNT(*) AS frequency FROM %s WHERE %s IS NOT NULL GROUP BY %s ORDER BY frequency DESC, %s LIMIT ?",
+		col, source.query, col, col, col), append(slices.Clone(source.args), topN)...)
+	if err != nil {
+		return nil, err
+	}
+	defer topRows.Close()
+
+	for topRows.Next() {
+		var value any
+		var count int64
+		if err := topRows.Scan(&value, &count); err != nil {
+			return nil, err
+		}
+		profile.TopValues = append(profile.TopValues, models.ValueFrequency{
+			Value: normalizeValue(value),
+			Count: count,
+		})
+	}
+
+	return profile, topRows.Err()
+}
+
+// estimateDistinct streams a column through a HyperLogLog sketch
+func (s *SQLiteDB) estimateDistinct(source profileSource, col string) (int64, error) {
+	rows, err := s.reader.Query(fmt.Sprintf("SELECT %s FROM %s WHERE %s IS NOT NULL", col, source.query, col), source.args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	sketch := newHyperLogLog()
+	for rows.Next() {
+		var value sql.RawBytes
+		if err := rows.Scan(&value); err != nil {
+			return 0, err
+		}
+		sketch.Add(value)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	return sketch.Estimate(), nil
+}
+
+func normalizeProfileOptions(opts models.ProfileOptions) models.ProfileOptions {
+	if opts.TopN <= 0 {
+		opts.TopN = defaultProfileTopN
+	}
+	if opts.TopN > maxProfileTopN {
+		opts.TopN = maxProfileTopN
+	}
+	if opts.SampleRows <= 0 {
+		opts.SampleRows = defaultProfileSampleRows
+	}
+	if opts.SampleRows > maxProfileSampleRows {
+		opts.SampleRows = maxProfileSampleRows
+	}
+	return opts
+}
+
+// normalizeValue converts driver values into JSON and text friendly forms
+func normalizeValue(value any) any {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
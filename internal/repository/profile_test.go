@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func TestProfileTable(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := db.Execute(`
+        INSERT INTO test_users (name, email) VALUES
+        ('John Doe', 'john@example.com'),
+        ('Jane Doe', NULL),
+        ('John Doe', 'other@example.com'),
+        ('Bob', NULL)
+    `)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %v", err)
+	}
+
+	profile, err := db.ProfileTable("test_users", models.ProfileOptions{})
+	if err != nil {
+		t.Fatalf("ProfileTable failed: %v", err)
+	}
+
+	if profile.RowCount != 4 {
+		t.Errorf("Expected row count 4, got %d", profile.RowCount)
+	}
+	if profile.Sampled {
+		t.Error("Expected small table not to be sampled")
+	}
+
+	columns := make(map[string]models.ColumnProfile)
+	for _, col := range profile.Columns {
+		columns[col.Name] = col
+	}
+
+	email := columns["email"]
+	if email.NullFraction != 0.5 {
+		t.Errorf("Expected email null fraction 0.5, got %f", email.NullFraction)
+	}
+	if email.StorageClasses["null"] != 2 || email.StorageClasses["text"] != 2 {
+		t.Errorf("Unexpected email storage classes: %v", email.StorageClasses)
+	}
+
+	name := columns["name"]
+	if name.DistinctCount != 3 {
+		t.Errorf("Expected 3 distinct names, got %d", name.DistinctCount)
+	}
+	if len(name.TopValues) == 0 || name.TopValues[0].Value != "John Doe" || name.TopValues[0].Count != 2 {
+		t.Errorf("Expected 'John Doe' as most frequent name, got %v", name.TopValues)
+	}
+	if name.Min != "Bob" || name.Max != "John Doe" {
+		t.Errorf("Unexpected name min/max: %v/%v", name.Min, name.Max)
+	}
+}
+
+func TestProfileTable_Cache(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.Execute("INSERT INTO test_users (name) VALUES ('John')"); err != nil {
+		t.Fatalf("Failed to insert test data: %v", err)
+	}
+
+	first, err := db.ProfileTable("test_users", models.ProfileOptions{})
+	if err != nil {
+		t.Fatalf("ProfileTable failed: %v", err)
+	}
+	if first.Cached {
+		t.Error("Expected first profile not to be cached")
+	}
+
+	second, err := db.ProfileTable("test_users", models.ProfileOptions{})
+	if err != nil {
+		t.Fatalf("ProfileTable failed: %v", err)
+	}
+	if !second.Cached {
+		t.Error("Expected second profile to be served from cache")
+	}
+
+	if _, err := db.Execute("INSERT INTO test_users (name) VALUES ('Jane')"); err != nil {
+		t.Fatalf("Failed to insert test data: %v", err)
+	}
+
+	third, err := db.ProfileTable("test_users", models.ProfileOptions{})
+	if err != nil {
+		t.Fatalf("ProfileTable failed: %v", err)
+	}
+	if third.Cached || third.RowCount != 2 {
+		t.Errorf("Expected fresh profile with 2 rows after a write, got cached=%t rows=%d", third.Cached, third.RowCount)
+	}
+}
+
+func TestProfileTable_Sampled(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := db.db.Exec(`
+        WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < 500)
+        INSERT INTO test_users (name, email) SELECT 'user' || (n % 50), CASE WHEN n > 250 THEN n || '@example.com' END FROM seq
+    `)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %v", err)
+	}
+
+	profile, err := db.ProfileTable("test_users", models.ProfileOptions{SampleRows: 200})
+	if err != nil {
+		t.Fatalf("ProfileTable failed: %v", err)
+	}
+
+	if !profile.Sampled || profile.SampleRows != 200 || profile.SampleMethod != "rowid" {
+		t.Fatalf("Expected a 200 row rowid sample, got sampled=%t rows=%d method=%s", profile.Sampled, profile.SampleRows, profile.SampleMethod)
+	}
+	if profile.RowCount != 500 {
+		t.Errorf("Expected exact row count 500, got %d", profile.RowCount)
+	}
+	for _, col := range profile.Columns {
+		if col.Name == "name" {
+			if !col.DistinctApproximate || col.DistinctCount < 48 || col.DistinctCount > 52 {
+				t.Errorf("Expected approximate distinct count of 50, got %d", col.DistinctCount)
+			}
+		}
+		// Only the second half of the rows has an email, the first 200 rows none
+		if col.Name == "email" && (col.NullFraction < 0.3 || col.NullFraction > 0.7) {
+			t.Errorf("Expected a null fraction near 0.5 from a random sample, got %.2f", col.NullFraction)
+		}
+	}
+}
+
+func TestProfileTable_MissingTable(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.ProfileTable("missing", models.ProfileOptions{}); err == nil {
+		t.Error("Expected error for missing table")
+	}
+}
+
+func TestHyperLogLog(t *testing.T) {
+	sketch := newHyperLogLog()
+	for i := 0; i < 100000; i++ {
+		sketch.Add(fmt.Appendf(nil, "value-%d", i%20000))
+	}
+
+	estimate := sketch.Estimate()
+	if estimate < 19000 || estimate > 21000 {
+		t.Errorf("Expected estimate within 5%% of 20000, got %d", estimate)
+	}
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// foreignKeyGuardTable is the temporary table whose CHECK constraint fails a
+// migration that leaves foreign key violations behind
+const foreignKeyGuardTable = "_mcp_foreign_key_check"
+
+// createTablePrefix matches "CREATE TABLE [IF NOT EXISTS] <name>" up to the column list
+var createTablePrefix = regexp.MustCompile("(?is)^(\\s*CREATE\\s+(?:TEMP\\s+|TEMPORARY\\s+)?TABLE\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?)" +
+	"(\"(?:[^\"]|\"\")*\"|`[^`]*`|\\[[^\\]]*\\]|[^\\s(]+)")
+
+// tableRebuild describes SQLite's generalized ALTER TABLE procedure
+// (https://www.sqlite.org/lang_altertable.html#otheralter): create the new
+// definition under a temporary name, copy the data across, drop the old table,
+// rename the new one into place and restore its indexes and triggers. The
+// caller is responsible for running the statements inside a transaction with
+// foreign key enforcement disabled and for dropping and recreating views.
+type tableRebuild struct {
+	Table     string
+	CreateSQL string   // CREATE TABLE statement of the new definition
+	Columns   []string // Columns of the new table filled from the old table
+	Sources   []string // Expressions over the old table, one per column
+	Indexes   []string // CREATE INDEX statements to restore
+	Triggers  []string // CREATE TRIGGER statements to restore
+}
+
+func (r tableRebuild) statements() ([]string, error) {
+	tempName := "_mcp_rebuild_" + r.Table
+	createTemp, err := renameCreateTable(r.CreateSQL, tempName)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []string{createTemp}
+	if len(r.Columns) > 0 {
+		columns := make([]string, len(r.Columns))
+		for i, col := range r.Columns {
+			columns[i] = quoteIdent(col)
+		}
+		statements = append(statements, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s",
+			quoteIdent(tempName), strings.Join(columns, ", "), strings.Join(r.Sources, ", "), quoteIdent(r.Table)))
+	}
+	statements = append(statements,
+		"DROP TABLE "+quoteIdent(r.Table),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quoteIdent(tempName), quoteIdent(r.Table)),
+	)
+	statements = append(statements, r.Indexes...)
+	statements = append(statements, r.Triggers...)
+
+	return statements, nil
+}
+
+// renameCreateTable rewrites the table name of a CREATE TABLE statement
+func renameCreateTable(createSQL, newName string) (string, error) {
+	loc := createTablePrefix.FindStringSubmatchIndex(createSQL)
+	if loc == nil {
+		return "", fmt.Errorf("not a CREATE TABLE statement: %s", sanitizeQuery(createSQL))
+	}
+	return createSQL[:loc[3]] + quoteIdent(newName) + createSQL[loc[5]:], nil
+}
+
+// wrapMigration runs statements in one transaction. Table rebuilds additionally
+// need a foreign key check before committing, which fails the migration when
+// references are broken, and foreign key enforcement switched off around the
+// transaction when it is on for the database (foreignKeys), to be switched on
+// again afterwards.
+func wrapMigration(statements []string, rebuild, foreignKeys bool) []string {
+	if len(statements) == 0 {
+		return nil
+	}
+
+	var wrapped []string
+	if rebuild && foreignKeys {
+		wrapped = append(wrapped, "PRAGMA foreign_keys=OFF")
+	}
+	wrapped = append(wrapped, "BEGIN TRANSACTION")
+	wrapped = append(wrapped, statements...)
+	if rebuild {
+		// PRAGMA foreign_key_check only returns rows, the CHECK turns them into an error
+		wrapped = append(wrapped,
+			"CREATE TEMP TABLE "+foreignKeyGuardTable+" (violations INTEGER CONSTRAINT foreign_key_violations CHECK (violations = 0))",
+			"INSERT INTO "+foreignKeyGuardTable+" SELECT COUNT(*) FROM pragma_foreign_key_check",
+			"DROP TABLE "+foreignKeyGuardTable)
+	}
+	wrapped = append(wrapped, "COMMIT")
+	if rebuild && foreignKeys {
+		wrapped = append(wrapped, "PRAGMA foreign_keys=ON")
+	}
+	return wrapped
+}
+
+// normalizeSQL collapses whitespace and case so equivalent definitions compare equal
+func normalizeSQL(sqlText string) string {
+	return strings.ToLower(strings.Join(strings.Fields(sqlText), " "))
+}
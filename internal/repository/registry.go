@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+// Registry holds the databases served by one process by name, each with its
+// own branches. The first database added is the default.
+type Registry struct {
+	names     []string
+	databases map[string]*registryEntry
+}
+
+type registryEntry struct {
+	db       *SQLiteDB
+	branches *BranchManager
+}
+
+func NewRegistry() *Registry {
+	return &Registry{databases: make(map[string]*registryEntry)}
+}
+
+// Add registers a database under a name; the registry closes it on Close
+func (r *Registry) Add(name string, db *SQLiteDB) error {
+	if name == "" {
+		return fmt.Errorf("database name is required")
+	}
+	if _, ok := r.databases[name]; ok {
+		return fmt.Errorf("database %s is configured twice", name)
+	}
+	r.names = append(r.names, name)
+	r.databases[name] = &registryEntry{db: db, branches: NewBranchManager(db)}
+	return nil
+}
+
+// Names returns the database names in the order they were added
+func (r *Registry) Names() []string {
+	return r.names
+}
+
+// Default returns the name of the default database
+func (r *Registry) Default() string {
+	if len(r.names) == 0 {
+		return ""
+	}
+	return r.names[0]
+}
+
+// Get returns a database by name, the default one when name is empty
+func (r *Registry) Get(name string) (*SQLiteDB, error) {
+	entry, err := r.entry(name)
+	if err != nil {
+		return nil, err
+	}
+	return entry.db, nil
+}
+
+// Branches returns the branches of a database, the default one when name is empty
+func (r *Registry) Branches(name string) (*BranchManager, error) {
+	entry, err := r.entry(name)
+	if err != nil {
+		return nil, err
+	}
+	return entry.branches, nil
+}
+
+// List describes the databases with their table count and file size
+func (r *Registry) List() []models.Database {
+	databases := make([]models.Database, 0, len(r.names))
+	for i, name := range r.names {
+		db := r.databases[name].db
+		database := models.Database{
+			Name:     name,
+			Path:     db.path,
+			Default:  i == 0,
+			ReadOnly: db.readOnly,
+			Policy:   db.Policy(),
+			Attached: db.attachments,
+		}
+		if err := db.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name NOT LIKE '\_mcp\_%' ESCAPE '\'`).Scan(&database.Tables); err != nil {
+			db.logger.Errorf("Failed to count tables of %s: %v", name, err)
+		}
+		if db.InMemory() {
+			if err := db.db.QueryRow("SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()").Scan(&database.Size); err != nil {
+				db.logger.Errorf("Failed to read size of %s: %v", name, err)
+			}
+		} else if info, err := os.Stat(db.path); err == nil {
+			database.Size = info.Size()
+		}
+		databases = append(databases, database)
+	}
+	return databases
+}
+
+// Close removes all branches and closes the databases
+func (r *Registry) Close() error {
+	var firstErr error
+	for _, name := range r.names {
+		entry := r.databases[name]
+		entry.branches.Close()
+		if err := entry.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ResolveFile resolves a database file named by a client, for tools that
+// open a second database: the name or path of a served database, the path of
+// a database attached to one, or a file inside dirs, relative paths against
+// the first of them. Other files stay out of reach.
+func (r *Registry) ResolveFile(ref string, dirs []string) (string, error) {
+	if entry, ok := r.databases[ref]; ok && !entry.db.InMemory() {
+		return entry.db.path, nil
+	}
+
+	if target, err := realPath(ref); err == nil {
+		for _, name := range r.names {
+			db := r.databases[name].db
+			known := make([]string, 0, len(db.attachments)+1)
+			if !db.InMemory() {
+				known = append(known, db.path)
+			}
+			for _, attachment := range db.attachments {
+				known = append(known, attachment.Path)
+			}
+			for _, path := range known {
+				if real, err := realPath(path); err == nil && real == target {
+					return path, nil
+				}
+			}
+		}
+	}
+
+	if len(dirs) > 0 {
+		path := ref
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dirs[0], path)
+		}
+		if resolved, err := resolveInDirs(path, dirs); err == nil {
+			if info, err := os.Stat(resolved); err == nil && !info.IsDir() {
+				return resolved, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("database %s is not served, attached or inside an import directory", ref)
+}
+
+// SetPolicies replaces the policies of databases by name. Every policy is
+// checked before any is applied, so either all of them change or none.
+func (r *Registry) SetPolicies(policies map[string]string) error {
+	prepared := make(map[*SQLiteDB]*accessPolicy, len(policies))
+	for name, policy := range policies {
+		entry, err := r.entry(name)
+		if err != nil {
+			return err
+		}
+		access, err := newAccessPolicy(policy)
+		if err != nil {
+			return fmt.Errorf("database %s: %w", name, err)
+		}
+		prepared[entry.db] = access
+	}
+	for db, access := range prepared {
+		db.access.policy.Store(access)
+	}
+	return nil
+}
+
+// realPath returns the absolute path of an existing file with symlinks resolved
+func realPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+func (r *Registry) entry(name string) (*registryEntry, error) {
+	if name == "" {
+		name = r.Default()
+	}
+	entry, ok := r.databases[name]
+	if !ok {
+		return nil, fmt.Errorf("database %s does not exist, see list_databases", name)
+	}
+	return entry, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func TestRegistry(t *testing.T) {
+	sales := newTestDBWithSchema(t, "sales.db", "CREATE TABLE orders (id INTEGER PRIMARY KEY)", "CREATE TABLE customers (id INTEGER PRIMARY KEY)")
+	logs := newTestDBWithSchema(t, "logs.db")
+
+	registry := NewRegistry()
+	defer registry.Close()
+	if err := registry.Add("sales", sales); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := registry.Add("logs", logs); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := registry.Add("logs", logs); err == nil {
+		t.Error("Expected an error for a duplicate name")
+	}
+
+	if db, err := registry.Get(""); err != nil || db != sales {
+		t.Errorf("Expected the first database as default, got %v (%v)", db, err)
+	}
+	if db, err := registry.Get("logs"); err != nil || db != logs {
+		t.Errorf("Expected logs, got %v (%v)", db, err)
+	}
+	if _, err := registry.Get("missing"); err == nil {
+		t.Error("Expected an error for an unknown database")
+	}
+
+	branches, err := registry.Branches("logs")
+	if err != nil || branches.Current("s1") != logs {
+		t.Errorf("Expected the branches of logs, got %v (%v)", branches, err)
+	}
+
+	list := registry.List()
+	if len(list) != 2 || list[0].Name != "sales" || !list[0].Default || list[0].Tables != 2 || list[1].Default || list[1].Policy != "full" {
+		t.Errorf("Unexpected list: %+v", list)
+	}
+}
+
+func TestRegistry_ResolveFile(t *testing.T) {
+	crm := newTestDBWithSchema(t, "crm.db", "CREATE TABLE contacts (id INTEGER PRIMARY KEY)")
+	crm.Close()
+	sales := newTestDBWithSchema(t, "sales.db")
+	salesPath := sales.Path()
+	sales.Close()
+	sales = openWithOptions(t, salesPath, models.DatabaseOptions{Attach: []models.Attachment{{Alias: "crm", Path: crm.Path()}}})
+
+	registry := NewRegistry()
+	defer registry.Close()
+	if err := registry.Add("sales", sales); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	copyPath := filepath.Join(dir, "copy.db")
+	if err := os.WriteFile(copyPath, nil, 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	outside := newTestDBWithSchema(t, "outside.db").Path()
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"sales", salesPath},
+		{salesPath, salesPath},
+		{crm.Path(), crm.Path()},
+		{"copy.db", copyPath},
+		{copyPath, copyPath},
+	}
+	for _, tt := range tests {
+		got, err := registry.ResolveFile(tt.ref, []string{dir})
+		if err != nil {
+			t.Errorf("ResolveFile(%q) failed: %v", tt.ref, err)
+			continue
+		}
+		if real, _ := filepath.EvalSymlinks(tt.want); got != tt.want && got != real {
+			t.Errorf("ResolveFile(%q) = %s, want %s", tt.ref, got, tt.want)
+		}
+	}
+
+	for _, ref := range []string{outside, "../outside.db", filepath.Join(dir, "missing.db"), "logs"} {
+		if _, err := registry.ResolveFile(ref, []string{dir}); err == nil {
+			t.Errorf("Expected %q to be refused", ref)
+		}
+	}
+	if _, err := registry.ResolveFile(copyPath, nil); err == nil {
+		t.Error("Expected files to be refused without import directories")
+	}
+}
+
+func TestRegistry_SetPolicies(t *testing.T) {
+	sales := newTestDBWithSchema(t, "sales.db")
+	logs := newTestDBWithSchema(t, "logs.db")
+
+	registry := NewRegistry()
+	defer registry.Close()
+	registry.Add("sales", sales)
+	registry.Add("logs", logs)
+
+	// One invalid policy leaves every database unchanged
+	err := registry.SetPolicies(map[string]string{"sales": models.PolicyNoDDL, "logs": "everything"})
+	if err == nil {
+		t.Fatal("Expected an error for an invalid policy")
+	}
+	if sales.Policy() != models.PolicyFull || logs.Policy() != models.PolicyFull {
+		t.Errorf("Expected no policy to change, got %s and %s", sales.Policy(), logs.Policy())
+	}
+	if err := registry.SetPolicies(map[string]string{"sales": models.PolicyNoDDL, "missing": models.PolicyNoDDL}); err == nil || sales.Policy() != models.PolicyFull {
+		t.Errorf("Expected an unknown database to fail the change, got %v and %s", err, sales.Policy())
+	}
+
+	if err := registry.SetPolicies(map[string]string{"sales": models.PolicyNoDDL, "logs": models.PolicyAppendOnly}); err != nil {
+		t.Fatalf("SetPolicies failed: %v", err)
+	}
+	if sales.Policy() != models.PolicyNoDDL || logs.Policy() != models.PolicyAppendOnly {
+		t.Errorf("Expected both policies to change, got %s and %s", sales.Policy(), logs.Policy())
+	}
+}
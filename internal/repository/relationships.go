@@ -0,0 +1,368 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+const (
+	defaultJoinMaxDepth = 4
+	maxJoinMaxDepth     = 6
+	defaultJoinLimit    = 3
+	maxJoinLimit        = 20
+	// maxJoinQueue bounds the search frontier on densely connected schemas
+	maxJoinQueue = 10000
+)
+
+var plainIdentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// FindJoinPaths returns the shortest join chains between two tables, following
+// declared foreign keys in either direction and, optionally, relationships
+// proposed by InferRelationships such as user_id -> users.id
+func (s *SQLiteDB) FindJoinPaths(fromTable, toTable string, opts models.JoinPathOptions) ([]models.JoinPath, error) {
+	s.logger.Debugf("Finding join paths from %s to %s", fromTable, toTable)
+
+	opts = normalizeJoinPathOptions(opts)
+
+	tables, err := s.mainTables()
+	if err != nil {
+		return nil, err
+	}
+
+	from, ok := findTable(tables, fromTable)
+	if !ok {
+		return nil, fmt.Errorf("table %s does not exist", fromTable)
+	}
+	to, ok := findTable(tables, toTable)
+	if !ok {
+		return nil, fmt.Errorf("table %s does not exist", toTable)
+	}
+
+	relationships := declaredRelationships(tables)
+	if opts.IncludeInferred {
+		inferred, err := s.inferRelationships(tables, relationships, models.InferenceOptions{})
+		if err != nil {
+			s.logger.Errorf("Failed to infer relationships: %v", err)
+			return nil, fmt.Errorf("failed to infer relationships")
+		}
+		relationships = append(relationships, inferred...)
+	}
+
+	paths := shortestJoinPaths(relationships, from.Name, to.Name, opts.MaxDepth, opts.Limit)
+
+	s.logger.Infof("Found %d join paths from %s to %s", len(paths), from.Name, to.Name)
+	return paths, nil
+}
+
+// declaredRelationships groups FOREIGN KEY rows by id into one relationship
+// per constraint, ordering multi-column keys by their Seq
+func declaredRelationships(tables []models.Table) []models.Relationship {
+	var relationships []models.Relationship
+
+	for _, table := range tables {
+		byID := make(map[int][]models.ForeignKey)
+		var ids []int
+		for _, fk := range table.ForeignKeys {
+			if _, ok := byID[fk.ID]; !ok {
+				ids = append(ids, fk.ID)
+			}
+			byID[fk.ID] = append(byID[fk.ID], fk)
+		}
+		sort.Ints(ids)
+
+		for _, id := range ids {
+			fks := byID[id]
+			sort.Slice(fks, func(i, j int) bool { return fks[i].Seq < fks[j].Seq })
+
+			relationship := models.Relationship{
+				FromTable:  table.Name,
+				ToTable:    fks[0].Table,
+				Confidence: 1,
+			}
+
+			// A REFERENCES clause without columns targets the parent's primary key
+			var parentKey []string
+			if parent, ok := findTable(tables, fks[0].Table); ok {
+				relationship.ToTable = parent.Name
+				parentKey = primaryKeyColumns(parent)
+			}
+
+			for i, fk := range fks {
+				relationship.FromColumns = append(relationship.FromColumns, fk.From)
+				to := fk.To
+				if to == "" && i < len(parentKey) {
+					to = parentKey[i]
+				}
+				relationship.ToColumns = append(relationship.ToColumns, to)
+			}
+
+			relationships = append(relationships, relationship)
+		}
+	}
+
+	return relationships
+}
+
+// namingRelationships proposes single-column relationships from names like
+// user_id or userId pointing at the primary key of a users/user table. Columns
+// already covered by a known relationship are skipped.
+func namingRelationships(tables []models.Table, known []models.Relationship) []models.Relationship {
+	covered := make(map[string]bool)
+	for _, r := range known {
+		for _, col := range r.FromColumns {
+			covered[strings.ToLower(r.FromTable+"."+col)] = true
+		}
+	}
+
+	var relationships []models.Relationship
+	for _, table := range tables {
+		for _, column := range table.Columns {
+			if covered[strings.ToLower(table.Name+"."+column.Name)] {
+				continue
+			}
+
+			base := referenceBaseName(column.Name)
+			if base == "" {
+				continue
+			}
+
+			for _, candidate := range pluralCandidates(base) {
+				parent, ok := findTable(tables, candidate)
+				if !ok || strings.EqualFold(parent.Name, table.Name) {
+					continue
+				}
+
+				target := referenceTargetColumn(parent)
+				if target == "" {
+					continue
+				}
+
+				relationships = append(relationships, models.Relationship{
+					FromTable:   table.Name,
+					FromColumns: []string{column.Name},
+					ToTable:     parent.Name,
+					ToColumns:   []string{target},
+					Inferred:    true,
+					Confidence:  namingConfidence,
+					Reason:      fmt.Sprintf("column name %s matches table %s", column.Name, parent.Name),
+				})
+				break
+			}
+		}
+	}
+
+	return relationships
+}
+
+// referenceBaseName extracts "user" from user_id, UserID or userId
+func referenceBaseName(column string) string {
+	lower := strings.ToLower(column)
+	switch {
+	case strings.HasSuffix(lower, "_id") && len(lower) > 3:
+		return lower[:len(lower)-3]
+	case strings.HasSuffix(column, "Id") && len(column) > 2:
+		return strings.ToLower(column[:len(column)-2])
+	case strings.HasSuffix(column, "ID") && len(column) > 2:
+		return strings.ToLower(column[:len(column)-2])
+	}
+	return ""
+}
+
+// pluralCandidates lists table names a reference base name may point at
+func pluralCandidates(base string) []string {
+	candidates := []string{base + "s", base, base + "es"}
+	if strings.HasSuffix(base, "y") {
+		candidates = append(candidates, base[:len(base)-1]+"ies")
+	}
+	return candidates
+}
+
+// referenceTargetColumn returns the column a naming-convention reference
+// points at: the single-column primary key, or a column named id
+func referenceTargetColumn(table models.Table) string {
+	pk := primaryKeyColumns(table)
+	if len(pk) == 1 {
+		return pk[0]
+	}
+	for _, col := range table.Columns {
+		if strings.EqualFold(col.Name, "id") {
+			return col.Name
+		}
+	}
+	return ""
+}
+
+// shortestJoinPaths runs a breadth-first search over relationships treated as
+// undirected edges and returns up to limit simple paths, shortest first,
+// preferring declared relationships over inferred ones at equal length
+func shortestJoinPaths(relationships []models.Relationship, from, to string, maxDepth, limit int) []models.JoinPath {
+	type edge struct {
+		next         string
+		relationship models.Relationship
+	}
+
+	graph := make(map[string][]edge)
+	for _, r := range relationships {
+		from, to := strings.ToLower(r.FromTable), strings.ToLower(r.ToTable)
+		graph[from] = append(graph[from], edge{next: r.ToTable, relationship: r})
+		if from != to {
+			graph[to] = append(graph[to], edge{next: r.FromTable, relationship: r})
+		}
+	}
+
+	type partial struct {
+		tables []string
+		steps  []models.JoinStep
+	}
+
+	var paths []models.JoinPath
+	if strings.EqualFold(from, to) {
+		return paths
+	}
+
+	queue := []partial{{tables: []string{from}}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if len(current.steps) >= maxDepth {
+			continue
+		}
+		// Paths are found in length order, stop once longer paths can no longer make the cut
+		if len(paths) >= limit && len(current.steps)+1 > len(paths[len(paths)-1].Steps) {
+			break
+		}
+
+		last := current.tables[len(current.tables)-1]
+		for _, e := range graph[strings.ToLower(last)] {
+			if containsFold(current.tables, e.next) {
+				continue
+			}
+
+			step := models.JoinStep{
+				Table:        e.next,
+				On:           joinCondition(e.relationship),
+				Relationship: e.relationship,
+			}
+			next := partial{
+				tables: append(append([]string{}, current.tables...), e.next),
+				steps:  append(append([]models.JoinStep{}, current.steps...), step),
+			}
+
+			if strings.EqualFold(e.next, to) {
+				paths = append(paths, buildJoinPath(next.tables, next.steps))
+				continue
+			}
+			if len(queue) < maxJoinQueue {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	// BFS yields paths in length order; keep that but rank declared paths first within a length
+	sort.SliceStable(paths, func(i, j int) bool {
+		if len(paths[i].Steps) != len(paths[j].Steps) {
+			return len(paths[i].Steps) < len(paths[j].Steps)
+		}
+		return !paths[i].Inferred && paths[j].Inferred
+	})
+
+	if len(paths) > limit {
+		paths = paths[:limit]
+	}
+	return paths
+}
+
+func buildJoinPath(tables []string, steps []models.JoinStep) models.JoinPath {
+	path := models.JoinPath{
+		Tables: tables,
+		Steps:  steps,
+	}
+
+	var b strings.Builder
+	b.WriteString("FROM " + sqlIdent(tables[0]))
+	for _, step := range steps {
+		b.WriteString("\nJOIN " + sqlIdent(step.Table) + " ON " + step.On)
+		if step.Relationship.Inferred {
+			path.Inferred = true
+		}
+	}
+	path.SQL = b.String()
+
+	return path
+}
+
+// joinCondition renders the ON clause for a relationship, one equality per column pair
+func joinCondition(r models.Relationship) string {
+	conditions := make([]string, 0, len(r.FromColumns))
+	for i := range r.FromColumns {
+		conditions = append(conditions, fmt.Sprintf("%s.%s = %s.%s",
+			sqlIdent(r.FromTable), sqlIdent(r.FromColumns[i]),
+			sqlIdent(r.ToTable), sqlIdent(r.ToColumns[i])))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// sqlIdent quotes an identifier only when it is not a plain name or is a keyword
+func sqlIdent(name string) string {
+	if plainIdentPattern.MatchString(name) && !isSQLiteKeyword(name) {
+		return name
+	}
+	return quoteIdent(name)
+}
+
+// primaryKeyColumns returns the primary key columns in key order, which for a
+// composite key may differ from the column order
+func primaryKeyColumns(table models.Table) []string {
+	var columns []models.Column
+	for _, col := range table.Columns {
+		if col.PrimaryKey {
+			columns = append(columns, col)
+		}
+	}
+	sort.SliceStable(columns, func(i, j int) bool { return columns[i].PrimaryKeyIndex < columns[j].PrimaryKeyIndex })
+
+	pk := make([]string, len(columns))
+	for i, col := range columns {
+		pk[i] = col.Name
+	}
+	return pk
+}
+
+func findTable(tables []models.Table, name string) (models.Table, bool) {
+	for _, table := range tables {
+		if strings.EqualFold(table.Name, name) {
+			return table, true
+		}
+	}
+	return models.Table{}, false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeJoinPathOptions(opts models.JoinPathOptions) models.JoinPathOptions {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = defaultJoinMaxDepth
+	}
+	if opts.MaxDepth > maxJoinMaxDepth {
+		opts.MaxDepth = maxJoinMaxDepth
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultJoinLimit
+	}
+	if opts.Limit > maxJoinLimit {
+		opts.Limit = maxJoinLimit
+	}
+	return opts
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func setupJoinTestDB(t *testing.T) (*SQLiteDB, func()) {
+	db, cleanup := setupTestDB(t)
+
+	statements := []string{
+		`CREATE TABLE test_orders (
+            id INTEGER PRIMARY KEY,
+            user_id INTEGER REFERENCES test_users(id)
+        )`,
+		`CREATE TABLE test_shipments (
+            id INTEGER PRIMARY KEY,
+            order_id INTEGER,
+            region TEXT,
+            warehouse_code TEXT,
+            FOREIGN KEY (order_id) REFERENCES test_orders
+        )`,
+		`CREATE TABLE test_warehouses (
+            region TEXT,
+            code TEXT,
+            PRIMARY KEY (region, code)
+        )`,
+		`CREATE TABLE test_stock (
+            region TEXT,
+            code TEXT,
+            quantity INTEGER,
+            FOREIGN KEY (region, code) REFERENCES test_warehouses(region, code)
+        )`,
+		`CREATE TABLE payments (id INTEGER PRIMARY KEY, test_order_id INTEGER)`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Execute(statement); err != nil {
+			cleanup()
+			t.Fatalf("Failed to create test table: %v", err)
+		}
+	}
+
+	return db, cleanup
+}
+
+func TestFindJoinPaths(t *testing.T) {
+	db, cleanup := setupJoinTestDB(t)
+	defer cleanup()
+
+	paths, err := db.FindJoinPaths("test_users", "test_shipments", models.JoinPathOptions{})
+	if err != nil {
+		t.Fatalf("FindJoinPaths failed: %v", err)
+	}
+
+	if len(paths) != 1 {
+		t.Fatalf("Expected 1 path, got %d", len(paths))
+	}
+
+	expected := "FROM test_users\nJOIN test_orders ON test_orders.user_id = test_users.id\nJOIN test_shipments ON test_shipments.order_id = test_orders.id"
+	if paths[0].SQL != expected {
+		t.Errorf("Unexpected join SQL:\n%s", paths[0].SQL)
+	}
+}
+
+func TestFindJoinPaths_MultiColumn(t *testing.T) {
+	db, cleanup := setupJoinTestDB(t)
+	defer cleanup()
+
+	paths, err := db.FindJoinPaths("test_stock", "test_warehouses", models.JoinPathOptions{})
+	if err != nil {
+		t.Fatalf("FindJoinPaths failed: %v", err)
+	}
+
+	if len(paths) != 1 {
+		t.Fatalf("Expected 1 path, got %d", len(paths))
+	}
+
+	on := paths[0].Steps[0].On
+	if on != "test_stock.region = test_warehouses.region AND test_stock.code = test_warehouses.code" {
+		t.Errorf("Unexpected multi-column join condition: %s", on)
+	}
+}
+
+func TestFindJoinPaths_ReorderedCompositeKey(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// The key order (code, region) differs from the column order
+	statements := []string{
+		`CREATE TABLE bins (region TEXT, code TEXT, PRIMARY KEY (code, region))`,
+		`CREATE TABLE picks (id INTEGER PRIMARY KEY, bin_code TEXT, bin_region TEXT, FOREIGN KEY (bin_code, bin_region) REFERENCES bins)`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Execute(statement); err != nil {
+			t.Fatalf("Failed to create test table: %v", err)
+		}
+	}
+
+	paths, err := db.FindJoinPaths("picks", "bins", models.JoinPathOptions{})
+	if err != nil {
+		t.Fatalf("FindJoinPaths failed: %v", err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("Expected 1 path, got %d", len(paths))
+	}
+
+	on := paths[0].Steps[0].On
+	if on != "picks.bin_code = bins.code AND picks.bin_region = bins.region" {
+		t.Errorf("Expected the child columns to pair with the key in key order, got: %s", on)
+	}
+}
+
+func TestFindJoinPaths_KeywordNames(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.Execute(`CREATE TABLE "order" (id INTEGER PRIMARY KEY, "group" INTEGER REFERENCES test_users(id))`); err != nil {
+		t.Fatalf("Failed to create test table: %v", err)
+	}
+
+	paths, err := db.FindJoinPaths("order", "test_users", models.JoinPathOptions{})
+	if err != nil {
+		t.Fatalf("FindJoinPaths failed: %v", err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("Expected 1 path, got %d", len(paths))
+	}
+
+	expected := "FROM \"order\"\nJOIN test_users ON \"order\".\"group\" = test_users.id"
+	if paths[0].SQL != expected {
+		t.Errorf("Expected keywords to be quoted, got:\n%s", paths[0].SQL)
+	}
+	if _, err := db.Query("SELECT COUNT(*) " + paths[0].SQL); err != nil {
+		t.Errorf("Expected the join to run, got %v", err)
+	}
+}
+
+func TestFindJoinPaths_Inferred(t *testing.T) {
+	db, cleanup := setupJoinTestDB(t)
+	defer cleanup()
+
+	paths, err := db.FindJoinPaths("payments", "test_users", models.JoinPathOptions{})
+	if err != nil {
+		t.Fatalf("FindJoinPaths failed: %v", err)
+	}
+	if len(paths) != 0 {
+		t.Fatalf("Expected no declared path, got %d", len(paths))
+	}
+
+	paths, err = db.FindJoinPaths("payments", "test_users", models.JoinPathOptions{IncludeInferred: true})
+	if err != nil {
+		t.Fatalf("FindJoinPaths failed: %v", err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("Expected 1 inferred path, got %d", len(paths))
+	}
+	if !paths[0].Inferred {
+		t.Error("Expected path to be marked as inferred")
+	}
+	if !strings.Contains(paths[0].SQL, "payments.test_order_id = test_orders.id") {
+		t.Errorf("Expected inferred join on test_order_id, got:\n%s", paths[0].SQL)
+	}
+}
+
+func TestFindJoinPaths_MissingTable(t *testing.T) {
+	db, cleanup := setupJoinTestDB(t)
+	defer cleanup()
+
+	if _, err := db.FindJoinPaths("test_users", "missing", models.JoinPathOptions{}); err == nil {
+		t.Error("Expected error for missing table")
+	}
+}
@@ -1,10 +1,38 @@
 package repository
 
-import "github.com/rvarun11/sqlite-mcp/internal/models"
+import (
+	"io"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
 
 type Repository interface {
 	GetSchema() ([]models.Table, error)
 	Query(sqlQuery string) (*models.QueryResult, error)
 	Execute(sqlQuery string) (*models.ExecuteResult, error)
+	ProfileTable(tableName string, opts models.ProfileOptions) (*models.TableProfile, error)
+	SampleRows(tableName string, opts models.SampleOptions) (*models.SampleResult, error)
+	InferRelationships(opts models.InferenceOptions) ([]models.Relationship, error)
+	FindJoinPaths(fromTable, toTable string, opts models.JoinPathOptions) ([]models.JoinPath, error)
+	ListAnnotations() ([]models.Annotation, error)
+	Annotate(update models.Annotation) (*models.Annotation, error)
+	RemoveAnnotation(table, column string) error
+	GetSchemaObjects() ([]models.SchemaObject, error)
+	DiffSchemaWithFile(otherPath string, fromOther bool) (*models.SchemaDiff, error)
+	DiffDataWithFile(otherPath string, opts models.DataDiffOptions) (*models.DataDiff, error)
+	AlterTable(spec models.AlterTableSpec) (*models.AlterTableResult, error)
+	ImportCSV(opts models.CSVImportOptions) (*models.ImportResult, error)
+	ImportJSON(opts models.JSONImportOptions) (*models.ImportResult, error)
+	ExportQuery(opts models.ExportOptions) (*models.ExportResult, error)
+	Backup(opts models.BackupOptions) (*models.BackupResult, error)
+	ListBackups() ([]models.Backup, error)
+	Restore(name string) (*models.RestoreResult, error)
+	Dump(w io.Writer, opts models.DumpOptions) (*models.DumpResult, error)
+	DumpToFile(path string, overwrite bool, opts models.DumpOptions) (*models.DumpResult, error)
+	LoadDump(r io.Reader) (*models.LoadResult, error)
+	ListMigrations() ([]models.Migration, error)
+	CreateMigration(name, up, down string) (*models.Migration, error)
+	ApplyMigrations(target int64) ([]models.Migration, error)
+	RevertMigrations(steps int) ([]models.Migration, error)
 	Close() error
 }
@@ -0,0 +1,492 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+// InsertRow inserts a row with the given column values, the other columns
+// take their default
+func (s *SQLiteDB) InsertRow(tableName string, values map[string]any) (*models.ExecuteResult, error) {
+	s.logger.Debugf("Inserting row into %s", tableName)
+
+	table, err := s.rowTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	columns, args, err := rowValues(table, values)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.execRow(insertStatement(table.Name, columns, "", nil, false), "insert", args...)
+	if err != nil {
+		return nil, err
+	}
+	result.Message = fmt.Sprintf("Inserted a row into %s", table.Name)
+	return result, nil
+}
+
+// maxInsertRows is the number of rows InsertRows takes at once
+const maxInsertRows = 1000
+
+// InsertRows inserts rows given as column values in one transaction, so
+// either all rows are written or none. Rows may give different columns.
+func (s *SQLiteDB) InsertRows(opts models.InsertRowsOptions) (*models.InsertRowsResult, error) {
+	s.logger.Debugf("Inserting %d rows into %s", len(opts.Rows), opts.Table)
+
+	if len(opts.Rows) == 0 {
+		return nil, errors.New("provide at least one row")
+	}
+	if len(opts.Rows) > maxInsertRows {
+		return nil, fmt.Errorf("at most %d rows can be inserted at once, got %d", maxInsertRows, len(opts.Rows))
+	}
+	table, err := s.rowTable(opts.Table)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateConflict(table, opts); err != nil {
+		return nil, err
+	}
+	withRowid, err := s.hasRowid(table.Name)
+	if err != nil {
+		s.logger.Errorf("Failed to look up table %s: %v", table.Name, err)
+		return nil, fmt.Errorf("failed to retrieve table information")
+	}
+
+	limits := s.Limits()
+	ctx, cancel := statementContext(limits)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, limitError(ctx, limits, "failed to start transaction")
+	}
+	defer tx.Rollback()
+
+	result := &models.InsertRowsResult{Table: table.Name}
+	for i, row := range opts.Rows {
+		columns, args, err := rowValues(table, row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		query := insertStatement(table.Name, columns, opts.OnConflict, opts.ConflictColumns, withRowid)
+		action := fmt.Sprintf("insert of row %d", i)
+
+		written := true
+		if withRowid {
+			var rowid int64
+			err = tx.QueryRowContext(ctx, query, args...).Scan(&rowid)
+			if errors.Is(err, sql.ErrNoRows) {
+				written, err = false, nil
+			} else if err == nil {
+				result.RowIDs = append(result.RowIDs, rowid)
+			}
+		} else {
+			var res sql.Result
+			if res, err = tx.ExecContext(ctx, query, args...); err == nil {
+				n, _ := res.RowsAffected()
+				written = n > 0
+			}
+		}
+		if err != nil {
+			return nil, s.rowError(ctx, limits, query, action, err)
+		}
+		if written {
+			result.Written++
+		} else {
+			result.Skipped = append(result.Skipped, i)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, s.rowError(ctx, limits, "", "commit", err)
+	}
+
+	s.logger.Infof("Inserted rows into %s, written: %d, skipped: %d", table.Name, result.Written, len(result.Skipped))
+	return result, nil
+}
+
+// validateConflict checks the conflict mode and, for an upsert, that the
+// conflict columns form a primary key or unique index
+func (s *SQLiteDB) validateConflict(table *models.Table, opts models.InsertRowsOptions) error {
+	switch opts.OnConflict {
+	case "", models.OnConflictIgnore:
+	case models.OnConflictReplace:
+		// REPLACE deletes the conflicting rows without asking the authorizer
+		if s.Policy() == models.PolicyAppendOnly {
+			return fmt.Errorf("%w: the %s policy of this database rejects replacing rows", ErrNotAllowed, models.PolicyAppendOnly)
+		}
+	case models.OnConflictUpsert:
+		if len(opts.ConflictColumns) == 0 {
+			return errors.New("an upsert needs the conflict columns to match rows on")
+		}
+		for _, column := range opts.ConflictColumns {
+			if !slices.ContainsFunc(table.Columns, func(c models.Column) bool { return c.Name == column }) {
+				return fmt.Errorf("table %s has no column %s", table.Name, column)
+			}
+		}
+		unique, err := s.isUniqueKey(table, opts.ConflictColumns)
+		if err != nil {
+			s.logger.Errorf("Failed to look up the indexes of %s: %v", table.Name, err)
+			return fmt.Errorf("failed to retrieve table information")
+		}
+		if !unique {
+			return fmt.Errorf("the conflict columns %s are not the primary key or a unique index of %s", strings.Join(opts.ConflictColumns, ", "), table.Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid on_conflict %q, expected %s, %s or %s", opts.OnConflict,
+			models.OnConflictIgnore, models.OnConflictReplace, models.OnConflictUpsert)
+	}
+	if len(opts.ConflictColumns) > 0 {
+		return fmt.Errorf("conflict columns only apply to %s", models.OnConflictUpsert)
+	}
+	return nil
+}
+
+// isUniqueKey reports whether the columns are exactly the primary key or the
+// columns of a unique index that is not partial, as ON CONFLICT requires
+func (s *SQLiteDB) isUniqueKey(table *models.Table, columns []string) (bool, error) {
+	sameColumns := func(other []string) bool {
+		return len(other) == len(columns) && !slices.ContainsFunc(columns, func(c string) bool { return !slices.Contains(other, c) })
+	}
+
+	var key []string
+	for _, column := range table.Columns {
+		if column.PrimaryKey {
+			key = append(key, column.Name)
+		}
+	}
+	if sameColumns(key) {
+		return true, nil
+	}
+
+	rows, err := s.reader.Query(`SELECT name FROM pragma_index_list(?) WHERE "unique" = 1 AND partial = 0`, table.Name)
+	if err != nil {
+		return false, err
+	}
+	var indexes []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return false, err
+		}
+		indexes = append(indexes, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	for _, index := range indexes {
+		rows, err := s.reader.Query("SELECT name FROM pragma_index_info(?)", index)
+		if err != nil {
+			return false, err
+		}
+		var indexColumns []string
+		for rows.Next() {
+			var name sql.NullString // NULL for expressions
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return false, err
+			}
+			indexColumns = append(indexColumns, name.String)
+		}
+		rows.Close()
+		if sameColumns(indexColumns) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasRowid reports whether a table has a rowid, which WITHOUT ROWID tables lack
+func (s *SQLiteDB) hasRowid(tableName string) (bool, error) {
+	var withoutRowid bool
+	err := s.reader.QueryRow("SELECT wr FROM pragma_table_list WHERE schema = 'main' AND name = ?", tableName).Scan(&withoutRowid)
+	return !withoutRowid, err
+}
+
+// insertStatement builds an INSERT of the columns with a conflict mode,
+// returning the rowid of the written row when asked
+func insertStatement(table string, columns []string, onConflict string, conflictColumns []string, returning bool) string {
+	var b strings.Builder
+	switch onConflict {
+	case models.OnConflictIgnore:
+		b.WriteString("INSERT OR IGNORE INTO ")
+	case models.OnConflictReplace:
+		b.WriteString("INSERT OR REPLACE INTO ")
+	default:
+		b.WriteString("INSERT INTO ")
+	}
+	b.WriteString(quoteIdent(table))
+
+	if len(columns) == 0 {
+		b.WriteString(" DEFAULT VALUES")
+	} else {
+		quoted := make([]string, len(columns))
+		for i, column := range columns {
+			quoted[i] = quoteIdent(column)
+		}
+		fmt.Fprintf(&b, " (%s) VALUES (%s)", strings.Join(quoted, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "))
+	}
+
+	// An upsert clause cannot follow DEFAULT VALUES, such a row is inserted as is
+	if onConflict == models.OnConflictUpsert && len(columns) > 0 {
+		var target, updates []string
+		for _, column := range conflictColumns {
+			target = append(target, quoteIdent(column))
+		}
+		for _, column := range columns {
+			if !slices.Contains(conflictColumns, column) {
+				updates = append(updates, quoteIdent(column)+" = excluded."+quoteIdent(column))
+			}
+		}
+		fmt.Fprintf(&b, " ON CONFLICT (%s) DO ", strings.Join(target, ", "))
+		if len(updates) == 0 {
+			b.WriteString("NOTHING")
+		} else {
+			b.WriteString("UPDATE SET " + strings.Join(updates, ", "))
+		}
+	}
+
+	if returning {
+		b.WriteString(" RETURNING rowid")
+	}
+	return b.String()
+}
+
+// GetRowByPK returns the row whose primary key columns have the given values
+func (s *SQLiteDB) GetRowByPK(tableName string, key map[string]any) (*models.QueryResult, error) {
+	s.logger.Debugf("Getting row of %s by primary key", tableName)
+
+	table, err := s.rowTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	where, args, err := keyCondition(table, key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.runQuery("SELECT * FROM "+quoteIdent(table.Name)+" WHERE "+where, args...)
+}
+
+// UpdateRowByPK changes the given columns of the row identified by the
+// primary key columns among the values
+func (s *SQLiteDB) UpdateRowByPK(tableName string, values map[string]any) (*models.ExecuteResult, error) {
+	s.logger.Debugf("Updating row of %s by primary key", tableName)
+
+	table, err := s.rowTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	where, keyArgs, err := keyCondition(table, values, false)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]any)
+	for name, value := range values {
+		if !isPrimaryKey(table, name) {
+			changes[name] = value
+		}
+	}
+	if len(changes) == 0 {
+		return nil, errors.New("provide at least one column to change besides the primary key")
+	}
+	columns, args, err := rowValues(table, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := make([]string, len(columns))
+	for i, column := range columns {
+		assignments[i] = quoteIdent(column) + " = ?"
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", quoteIdent(table.Name), strings.Join(assignments, ", "), where)
+
+	result, err := s.execRow(query, "update", append(args, keyArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	result.Message = rowMessage(result.RowsAffected, "Updated", table.Name)
+	return result, nil
+}
+
+// DeleteRowByPK deletes the row whose primary key columns have the given values
+func (s *SQLiteDB) DeleteRowByPK(tableName string, key map[string]any) (*models.ExecuteResult, error) {
+	s.logger.Debugf("Deleting row of %s by primary key", tableName)
+
+	table, err := s.rowTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	where, args, err := keyCondition(table, key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.execRow("DELETE FROM "+quoteIdent(table.Name)+" WHERE "+where, "delete", args...)
+	if err != nil {
+		return nil, err
+	}
+	result.Message = rowMessage(result.RowsAffected, "Deleted", table.Name)
+	return result, nil
+}
+
+// rowTable describes a table of the main database the row operations work on
+func (s *SQLiteDB) rowTable(tableName string) (*models.Table, error) {
+	if strings.HasPrefix(tableName, "_mcp_") {
+		return nil, fmt.Errorf("table %s does not exist", tableName)
+	}
+	exists, err := s.tableExists(tableName)
+	if err != nil {
+		s.logger.Errorf("Failed to look up table %s: %v", tableName, err)
+		return nil, fmt.Errorf("failed to retrieve table information")
+	}
+	if !exists {
+		return nil, fmt.Errorf("table %s does not exist", tableName)
+	}
+
+	table, err := s.getTableInfo(tableName)
+	if err != nil {
+		s.logger.Errorf("Failed to get table info for table %s: %v", tableName, err)
+		return nil, fmt.Errorf("failed to retrieve table information")
+	}
+	return table, nil
+}
+
+// execRow runs a statement changing rows within the query time limit. Errors
+// caused by the data, such as constraint violations, are reported with
+// SQLite's message as the caller can fix them.
+func (s *SQLiteDB) execRow(query, action string, args ...any) (*models.ExecuteResult, error) {
+	limits := s.Limits()
+	ctx, cancel := statementContext(limits)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, s.rowError(ctx, limits, query, action, err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	lastInsertId, _ := result.LastInsertId()
+	s.logger.Infof("Row %s executed successfully, rows_affected: %d", action, rowsAffected)
+	return &models.ExecuteResult{RowsAffected: rowsAffected, LastInsertId: lastInsertId}, nil
+}
+
+func (s *SQLiteDB) rowError(ctx context.Context, limits models.Limits, query, action string, err error) error {
+	s.logger.Errorf("Row %s failed: %v", action, err)
+	if notAllowed := s.statementError(query, err); notAllowed != nil {
+		return notAllowed
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrConstraint || sqliteErr.Code == sqlite3.ErrMismatch) {
+		return fmt.Errorf("%s failed: %v", action, err)
+	}
+	return limitError(ctx, limits, action+" failed")
+}
+
+// rowValues checks that the values name columns of the table and returns the
+// columns in table order with their values to bind
+func rowValues(table *models.Table, values map[string]any) ([]string, []any, error) {
+	for name := range values {
+		if !slices.ContainsFunc(table.Columns, func(c models.Column) bool { return c.Name == name }) {
+			return nil, nil, fmt.Errorf("table %s has no column %s", table.Name, name)
+		}
+	}
+
+	var columns []string
+	var args []any
+	for _, column := range table.Columns {
+		value, ok := values[column.Name]
+		if !ok {
+			continue
+		}
+		bound, err := bindValue(column.Name, value)
+		if err != nil {
+			return nil, nil, err
+		}
+		columns = append(columns, column.Name)
+		args = append(args, bound)
+	}
+	return columns, args, nil
+}
+
+// keyCondition builds the WHERE condition matching the primary key columns
+// among the values. With exact, no other columns may be given.
+func keyCondition(table *models.Table, values map[string]any, exact bool) (string, []any, error) {
+	var conditions []string
+	var args []any
+	for _, column := range table.Columns {
+		if !column.PrimaryKey {
+			continue
+		}
+		value, ok := values[column.Name]
+		if !ok || value == nil {
+			return "", nil, fmt.Errorf("primary key column %s is required", column.Name)
+		}
+		bound, err := bindValue(column.Name, value)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, quoteIdent(column.Name)+" = ?")
+		args = append(args, bound)
+	}
+	if len(conditions) == 0 {
+		return "", nil, fmt.Errorf("table %s has no primary key", table.Name)
+	}
+
+	if exact {
+		for name := range values {
+			if !isPrimaryKey(table, name) {
+				return "", nil, fmt.Errorf("%s is not a primary key column of %s", name, table.Name)
+			}
+		}
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+func isPrimaryKey(table *models.Table, name string) bool {
+	return slices.ContainsFunc(table.Columns, func(c models.Column) bool { return c.Name == name && c.PrimaryKey })
+}
+
+// bindValue converts a value decoded from JSON to one SQLite binds: whole
+// numbers become integers and objects and arrays are stored as JSON text
+func bindValue(column string, value any) (any, error) {
+	switch v := value.(type) {
+	case nil, string, bool, int, int64:
+		return v, nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v), nil
+		}
+		return v, nil
+	case map[string]any, []any:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", column, err)
+		}
+		return string(encoded), nil
+	}
+	return nil, fmt.Errorf("column %s: unsupported value %v", column, value)
+}
+
+func rowMessage(rowsAffected int64, action, table string) string {
+	if rowsAffected == 0 {
+		return fmt.Sprintf("No row of %s has this primary key", table)
+	}
+	return fmt.Sprintf("%s %d row of %s", action, rowsAffected, table)
+}
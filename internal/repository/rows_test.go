@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func TestRows_ByPrimaryKey(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// JSON numbers arrive as float64, objects are stored as JSON text
+	result, err := db.InsertRow("test_users", map[string]any{"name": "Ann", "email": map[string]any{"work": "ann@example.com"}})
+	if err != nil {
+		t.Fatalf("InsertRow failed: %v", err)
+	}
+	id := float64(result.LastInsertId)
+
+	rows, err := db.GetRowByPK("test_users", map[string]any{"id": id})
+	if err != nil {
+		t.Fatalf("GetRowByPK failed: %v", err)
+	}
+	if rows.Count != 1 || rows.Rows[0]["email"] != `{"work":"ann@example.com"}` || rows.Rows[0]["created_at"] == nil {
+		t.Errorf("Expected the inserted row with its default, got %+v", rows.Rows)
+	}
+
+	result, err = db.UpdateRowByPK("test_users", map[string]any{"id": id, "name": "Anna"})
+	if err != nil || result.RowsAffected != 1 {
+		t.Fatalf("UpdateRowByPK failed: %+v (%v)", result, err)
+	}
+	result, err = db.UpdateRowByPK("test_users", map[string]any{"id": 999, "name": "Nobody"})
+	if err != nil || result.RowsAffected != 0 {
+		t.Errorf("Expected no row to be updated, got %+v (%v)", result, err)
+	}
+
+	if _, err := db.InsertRow("test_users", map[string]any{"email": "x@example.com"}); err == nil || !strings.Contains(err.Error(), "NOT NULL") {
+		t.Errorf("Expected the NOT NULL violation to be reported, got %v", err)
+	}
+	if _, err := db.InsertRow("test_users", map[string]any{"name": "Bob", "age": 3}); err == nil || !strings.Contains(err.Error(), "no column age") {
+		t.Errorf("Expected an error for an unknown column, got %v", err)
+	}
+	if _, err := db.UpdateRowByPK("test_users", map[string]any{"id": id}); err == nil {
+		t.Error("Expected an error for an update without columns to change")
+	}
+	if _, err := db.GetRowByPK("test_users", map[string]any{"name": "Anna"}); err == nil {
+		t.Error("Expected an error for a lookup without the primary key")
+	}
+	if _, err := db.InsertRow("_mcp_annotations", map[string]any{}); err == nil {
+		t.Error("Expected internal tables to be refused")
+	}
+
+	result, err = db.DeleteRowByPK("test_users", map[string]any{"id": id})
+	if err != nil || result.RowsAffected != 1 {
+		t.Fatalf("DeleteRowByPK failed: %+v (%v)", result, err)
+	}
+}
+
+func TestRows_RespectPolicy(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := db.SetPolicy(models.PolicyAppendOnly); err != nil {
+		t.Fatalf("SetPolicy failed: %v", err)
+	}
+	result, err := db.InsertRow("test_users", map[string]any{"name": "Ann"})
+	if err != nil {
+		t.Fatalf("InsertRow failed: %v", err)
+	}
+	if _, err := db.DeleteRowByPK("test_users", map[string]any{"id": result.LastInsertId}); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Expected the append-only policy to refuse the delete, got %v", err)
+	}
+}
+
+func TestInsertRows(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	result, err := db.InsertRows(models.InsertRowsOptions{
+		Table: "test_users",
+		Rows: []map[string]any{
+			{"name": "Ann", "email": "ann@example.com"},
+			{"name": "Bob"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("InsertRows failed: %v", err)
+	}
+	if result.Written != 2 || len(result.RowIDs) != 2 || result.RowIDs[1] != result.RowIDs[0]+1 {
+		t.Errorf("Expected two rowids, got %+v", result)
+	}
+
+	// A conflict without a mode fails the whole batch
+	_, err = db.InsertRows(models.InsertRowsOptions{
+		Table: "test_users",
+		Rows:  []map[string]any{{"name": "Cid"}, {"name": "Ann 2", "email": "ann@example.com"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "row 1") || !strings.Contains(err.Error(), "UNIQUE") {
+		t.Errorf("Expected the UNIQUE violation of row 1, got %v", err)
+	}
+
+	result, err = db.InsertRows(models.InsertRowsOptions{
+		Table:      "test_users",
+		Rows:       []map[string]any{{"name": "Cid"}, {"name": "Ann 2", "email": "ann@example.com"}},
+		OnConflict: models.OnConflictIgnore,
+	})
+	if err != nil {
+		t.Fatalf("InsertRows failed: %v", err)
+	}
+	if result.Written != 1 || len(result.Skipped) != 1 || result.Skipped[0] != 1 {
+		t.Errorf("Expected the conflicting row to be skipped, got %+v", result)
+	}
+
+	result, err = db.InsertRows(models.InsertRowsOptions{
+		Table:           "test_users",
+		Rows:            []map[string]any{{"name": "Annie", "email": "ann@example.com"}},
+		OnConflict:      models.OnConflictUpsert,
+		ConflictColumns: []string{"email"},
+	})
+	if err != nil {
+		t.Fatalf("InsertRows failed: %v", err)
+	}
+	if len(result.RowIDs) != 1 || result.RowIDs[0] != 1 {
+		t.Errorf("Expected the rowid of the updated row, got %+v", result)
+	}
+	rows, err := db.Query("SELECT name FROM test_users WHERE email = 'ann@example.com'")
+	if err != nil || rows.Count != 1 || rows.Rows[0]["name"] != "Annie" {
+		t.Errorf("Expected the upsert to update the name, got %+v (%v)", rows, err)
+	}
+
+	for _, opts := range []models.InsertRowsOptions{
+		{Table: "test_users", Rows: []map[string]any{{"nickname": "x"}}},
+		{Table: "test_users", Rows: []map[string]any{{"name": "x"}}, OnConflict: "merge"},
+		{Table: "test_users", Rows: []map[string]any{{"name": "x"}}, OnConflict: models.OnConflictUpsert},
+		{Table: "test_users", Rows: []map[string]any{{"name": "x"}}, OnConflict: models.OnConflictUpsert, ConflictColumns: []string{"name"}},
+		{Table: "test_users", Rows: []map[string]any{{"name": "x"}}, ConflictColumns: []string{"email"}},
+		{Table: "missing", Rows: []map[string]any{{"name": "x"}}},
+	} {
+		if _, err := db.InsertRows(opts); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+}
+
+func TestInsertRows_WithoutRowid(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.Execute("CREATE TABLE settings (key TEXT PRIMARY KEY, value TEXT) WITHOUT ROWID"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	opts := models.InsertRowsOptions{
+		Table:           "settings",
+		Rows:            []map[string]any{{"key": "theme", "value": "dark"}},
+		OnConflict:      models.OnConflictUpsert,
+		ConflictColumns: []string{"key"},
+	}
+	for range 2 {
+		result, err := db.InsertRows(opts)
+		if err != nil {
+			t.Fatalf("InsertRows failed: %v", err)
+		}
+		if result.Written != 1 || len(result.RowIDs) != 0 {
+			t.Errorf("Expected one written row without rowid, got %+v", result)
+		}
+	}
+
+	if err := db.SetPolicy(models.PolicyAppendOnly); err != nil {
+		t.Fatalf("SetPolicy failed: %v", err)
+	}
+	opts.OnConflict, opts.ConflictColumns = models.OnConflictReplace, nil
+	if _, err := db.InsertRows(opts); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Expected the append-only policy to refuse replacing rows, got %v", err)
+	}
+}
@@ -0,0 +1,384 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+const (
+	defaultSampleLimit          = 10
+	maxSampleLimit              = 100
+	defaultSampleMaxValueLength = 200
+	// sampleProbeFactor bounds the number of random rowid probes per requested row
+	sampleProbeFactor = 10
+)
+
+// SampleRows returns a random sample of rows from a table. Rowid tables are
+// sampled by probing random positions in the rowid range, which uses the
+// rowid b-tree instead of sorting the whole table. Tables without rowid fall
+// back to ORDER BY RANDOM(). With StratifyBy set, rows are drawn from each
+// distinct value of that column in proportion to its frequency.
+func (s *SQLiteDB) SampleRows(tableName string, opts models.SampleOptions) (*models.SampleResult, error) {
+	s.logger.Debugf("Sampling rows from table: %s", tableName)
+
+	opts = normalizeSampleOptions(opts)
+
+	exists, err := s.tableExists(tableName)
+	if err != nil {
+		s.logger.Errorf("Failed to look up table %s: %v", tableName, err)
+		return nil, fmt.Errorf("failed to retrieve table information")
+	}
+	if !exists {
+		return nil, fmt.Errorf("table %s does not exist", tableName)
+	}
+
+	if opts.StratifyBy != "" {
+		tableInfo, err := s.getTableInfo(tableName)
+		if err != nil {
+			s.logger.Errorf("Failed to get table info for table %s: %v", tableName, err)
+			return nil, fmt.Errorf("failed to retrieve table information")
+		}
+		if !hasColumn(tableInfo, opts.StratifyBy) {
+			return nil, fmt.Errorf("column %s does not exist in table %s", opts.StratifyBy, tableName)
+		}
+	}
+
+	sampler := &rowSampler{
+		db:             s.reader,
+		table:          quoteIdent(tableName),
+		maxValueLength: opts.MaxValueLength,
+	}
+	sampler.hasRowid = sampler.checkRowid()
+
+	result := &models.SampleResult{
+		Table:        tableName,
+		StratifiedBy: opts.StratifyBy,
+		Method:       "random_order",
+	}
+	if sampler.hasRowid {
+		result.Method = "rowid"
+	}
+
+	if err := s.reader.QueryRow("SELECT COUNT(*) FROM " + sampler.table).Scan(&result.TotalRows); err != nil {
+		s.logger.Errorf("Failed to count rows of table %s: %v", tableName, err)
+		return nil, fmt.Errorf("failed to sample rows")
+	}
+	if result.TotalRows <= int64(opts.Limit) {
+		result.Method = "all"
+	}
+
+	if opts.StratifyBy == "" {
+		err = sampler.sample("", nil, opts.Limit, result)
+	} else {
+		err = sampler.sampleStratified(quoteIdent(opts.StratifyBy), opts.Limit, result)
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to sample rows from table %s: %v", tableName, err)
+		return nil, fmt.Errorf("failed to sample rows")
+	}
+
+	result.Count = len(result.Rows)
+	s.logger.Infof("Sampled table %s, rows_returned: %d, method: %s", tableName, result.Count, result.Method)
+	return result, nil
+}
+
+type rowSampler struct {
+	db             *sql.DB
+	table          string
+	hasRowid       bool
+	maxValueLength int
+}
+
+// checkRowid reports whether the table has a rowid (i.e. is not WITHOUT ROWID)
+func (rs *rowSampler) checkRowid() bool {
+	rows, err := rs.db.Query("SELECT rowid FROM " + rs.table + " LIMIT 0")
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
+
+// sampleStratified splits the limit across the distinct values of column
+// proportionally to their frequency, giving every stratum at least one row
+// while the limit allows
+func (rs *rowSampler) sampleStratified(column string, limit int, result *models.SampleResult) error {
+	rows, err := rs.db.Query(fmt.Sprintf(
+		"SELECT %s, COUNT(*) AS frequency FROM %s GROUP BY %s ORDER BY frequency DESC LIMIT ?",
+		column, rs.table, column), limit)
+	if err != nil {
+		return err
+	}
+
+	var strata []models.ValueFrequency
+	var total int64
+	for rows.Next() {
+		var stratum models.ValueFrequency
+		if err := rows.Scan(&stratum.Value, &stratum.Count); err != nil {
+			rows.Close()
+			return err
+		}
+		strata = append(strata, stratum)
+		total += stratum.Count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	quotas := allocateQuotas(strata, total, limit)
+	for i, stratum := range strata {
+		before := len(result.Rows)
+		if err := rs.sample(column+" IS ?", []any{stratum.Value}, quotas[i], result); err != nil {
+			return err
+		}
+		result.Strata = append(result.Strata, models.ValueFrequency{
+			Value: normalizeValue(stratum.Value),
+			Count: int64(len(result.Rows) - before),
+		})
+	}
+	return nil
+}
+
+// sample appends up to n random rows matching filter to result
+func (rs *rowSampler) sample(filter string, args []any, n int, result *models.SampleResult) error {
+	if n <= 0 {
+		return nil
+	}
+
+	where := ""
+	if filter != "" {
+		where = " WHERE " + filter
+	}
+
+	if !rs.hasRowid {
+		query := fmt.Sprintf("SELECT * FROM %s%s ORDER BY RANDOM() LIMIT %d", rs.table, where, n)
+		return rs.collect(query, args, false, nil, result)
+	}
+
+	var count int64
+	var minRowid, maxRowid sql.NullInt64
+	query := fmt.Sprintf("SELECT COUNT(*), MIN(rowid), MAX(rowid) FROM %s%s", rs.table, where)
+	if err := rs.db.QueryRow(query, args...).Scan(&count, &minRowid, &maxRowid); err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	if count <= int64(n) {
+		return rs.collect(fmt.Sprintf("SELECT rowid, * FROM %s%s ORDER BY rowid", rs.table, where), args, true, nil, result)
+	}
+
+	// Probe random points in the rowid range and take the next row at or after each.
+	// Gaps in the rowid sequence make rows after a gap slightly more likely.
+	probe := fmt.Sprintf("SELECT rowid, * FROM %s WHERE rowid >= ?", rs.table)
+	if filter != "" {
+		probe += " AND " + filter
+	}
+	probe += " ORDER BY rowid LIMIT 1"
+
+	seen := make(map[int64]bool, n)
+	span := maxRowid.Int64 - minRowid.Int64 + 1
+	for attempt := 0; attempt < n*sampleProbeFactor && len(seen) < n; attempt++ {
+		start := minRowid.Int64 + rand.Int64N(span)
+		if err := rs.collect(probe, append([]any{start}, args...), true, seen, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sampleRowids picks up to n distinct random rowids of the table with the
+// probing of sample, running each round of probes as a single query. It is
+// used to sample more rows than sample returns, such as for profiles.
+func (rs *rowSampler) sampleRowids(n int) ([]int64, error) {
+	var minRowid, maxRowid sql.NullInt64
+	if err := rs.db.QueryRow("SELECT MIN(rowid), MAX(rowid) FROM "+rs.table).Scan(&minRowid, &maxRowid); err != nil {
+		return nil, err
+	}
+	if !minRowid.Valid {
+		return nil, nil
+	}
+
+	probe := fmt.Sprintf("SELECT DISTINCT (SELECT rowid FROM %s WHERE rowid >= p.value ORDER BY rowid LIMIT 1) FROM json_each(?) p", rs.table)
+	seen := make(map[int64]bool, n)
+	rowids := make([]int64, 0, n)
+	span := maxRowid.Int64 - minRowid.Int64 + 1
+	for round := 0; round < sampleProbeFactor && len(rowids) < n; round++ {
+		starts := make([]int64, n-len(rowids))
+		for i := range starts {
+			starts[i] = minRowid.Int64 + rand.Int64N(span)
+		}
+		points, err := json.Marshal(starts)
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := rs.db.Query(probe, string(points))
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var rowid int64
+			if err := rows.Scan(&rowid); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if !seen[rowid] {
+				seen[rowid] = true
+				rowids = append(rowids, rowid)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return rowids, nil
+}
+
+// collect runs query and appends its rows to result. When withRowid is set the
+// first column is the rowid, which is used for de-duplication via seen and is
+// not included in the returned row.
+func (rs *rowSampler) collect(query string, args []any, withRowid bool, seen map[int64]bool, result *models.SampleResult) error {
+	rows, err := rs.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if withRowid {
+		columns = columns[1:]
+	}
+	if result.Columns == nil {
+		result.Columns = columns
+	}
+
+	for rows.Next() {
+		values := make([]any, len(columns))
+		valuePtrs := make([]any, 0, len(columns)+1)
+
+		var rowid int64
+		if withRowid {
+			valuePtrs = append(valuePtrs, &rowid)
+		}
+		for i := range values {
+			valuePtrs = append(valuePtrs, &values[i])
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return err
+		}
+
+		if seen != nil {
+			if seen[rowid] {
+				continue
+			}
+			seen[rowid] = true
+		}
+
+		row := make(map[string]any, len(columns))
+		for i, col := range columns {
+			row[col] = truncateValue(values[i], rs.maxValueLength)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	return rows.Err()
+}
+
+// allocateQuotas distributes limit rows over strata proportionally to their
+// counts using the largest remainder method, then moves rows from the largest
+// quotas so every stratum gets at least one row while the limit allows
+func allocateQuotas(strata []models.ValueFrequency, total int64, limit int) []int {
+	quotas := make([]int, len(strata))
+	if total == 0 {
+		return quotas
+	}
+
+	assigned := 0
+	remainders := make([]float64, len(strata))
+	for i, stratum := range strata {
+		share := float64(limit) * float64(stratum.Count) / float64(total)
+		quotas[i] = int(share)
+		remainders[i] = share - float64(quotas[i])
+		assigned += quotas[i]
+	}
+
+	for ; assigned < limit; assigned++ {
+		best := 0
+		for i := range remainders {
+			if remainders[i] > remainders[best] {
+				best = i
+			}
+		}
+		quotas[best]++
+		remainders[best] = -1
+	}
+
+	for i := range quotas {
+		if quotas[i] > 0 {
+			continue
+		}
+		largest := 0
+		for j := range quotas {
+			if quotas[j] > quotas[largest] {
+				largest = j
+			}
+		}
+		if quotas[largest] <= 1 {
+			break
+		}
+		quotas[largest]--
+		quotas[i] = 1
+	}
+
+	return quotas
+}
+
+// truncateValue shortens long text values and replaces BLOBs with a size marker
+func truncateValue(value any, maxLength int) any {
+	switch v := value.(type) {
+	case []byte:
+		return fmt.Sprintf("<BLOB %d bytes>", len(v))
+	case string:
+		if utf8.RuneCountInString(v) <= maxLength {
+			return v
+		}
+		runes := []rune(v)
+		return string(runes[:maxLength]) + fmt.Sprintf("... (%d chars)", len(runes))
+	}
+	return value
+}
+
+func hasColumn(table *models.Table, column string) bool {
+	for _, col := range table.Columns {
+		if strings.EqualFold(col.Name, column) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeSampleOptions(opts models.SampleOptions) models.SampleOptions {
+	if opts.Limit <= 0 {
+		opts.Limit = defaultSampleLimit
+	}
+	if opts.Limit > maxSampleLimit {
+		opts.Limit = maxSampleLimit
+	}
+	if opts.MaxValueLength <= 0 {
+		opts.MaxValueLength = defaultSampleMaxValueLength
+	}
+	return opts
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func seedSampleEvents(t *testing.T, db *SQLiteDB, n int) {
+	_, err := db.Execute("CREATE TABLE test_events (id INTEGER PRIMARY KEY, kind TEXT, payload TEXT)")
+	if err != nil {
+		t.Fatalf("Failed to create test table: %v", err)
+	}
+
+	_, err = db.db.Exec(`
+        WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < ?)
+        INSERT INTO test_events (kind, payload) SELECT CASE WHEN n % 10 = 0 THEN 'rare' ELSE 'common' END, 'event' || n FROM seq
+    `, n)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %v", err)
+	}
+}
+
+func TestSampleRows(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	seedSampleEvents(t, db, 1000)
+
+	result, err := db.SampleRows("test_events", models.SampleOptions{Limit: 20})
+	if err != nil {
+		t.Fatalf("SampleRows failed: %v", err)
+	}
+
+	if result.Count != 20 {
+		t.Errorf("Expected 20 rows, got %d", result.Count)
+	}
+	if result.TotalRows != 1000 {
+		t.Errorf("Expected total rows 1000, got %d", result.TotalRows)
+	}
+	if result.Method != "rowid" {
+		t.Errorf("Expected rowid sampling, got %s", result.Method)
+	}
+
+	ids := make(map[any]bool)
+	for _, row := range result.Rows {
+		if ids[row["id"]] {
+			t.Errorf("Duplicate row %v in sample", row["id"])
+		}
+		ids[row["id"]] = true
+	}
+}
+
+func TestSampleRows_SmallTable(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	seedSampleEvents(t, db, 5)
+
+	result, err := db.SampleRows("test_events", models.SampleOptions{})
+	if err != nil {
+		t.Fatalf("SampleRows failed: %v", err)
+	}
+
+	if result.Count != 5 || result.Method != "all" {
+		t.Errorf("Expected all 5 rows, got %d (method %s)", result.Count, result.Method)
+	}
+}
+
+func TestSampleRows_Stratified(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	seedSampleEvents(t, db, 1000)
+
+	result, err := db.SampleRows("test_events", models.SampleOptions{Limit: 10, StratifyBy: "kind"})
+	if err != nil {
+		t.Fatalf("SampleRows failed: %v", err)
+	}
+
+	if result.Count != 10 {
+		t.Errorf("Expected 10 rows, got %d", result.Count)
+	}
+
+	strata := make(map[any]int64)
+	for _, stratum := range result.Strata {
+		strata[stratum.Value] = stratum.Count
+	}
+	if strata["common"] != 9 || strata["rare"] != 1 {
+		t.Errorf("Expected proportional strata common=9 rare=1, got %v", strata)
+	}
+	for _, row := range result.Rows[9:] {
+		if row["kind"] != "rare" {
+			t.Errorf("Expected last row from the rare stratum, got %v", row["kind"])
+		}
+	}
+}
+
+func TestSampleRows_Truncation(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.db.Exec("INSERT INTO test_users (name, email) VALUES (?, ?)", strings.Repeat("x", 500), []byte{1, 2, 3}); err != nil {
+		t.Fatalf("Failed to insert test data: %v", err)
+	}
+
+	result, err := db.SampleRows("test_users", models.SampleOptions{MaxValueLength: 10})
+	if err != nil {
+		t.Fatalf("SampleRows failed: %v", err)
+	}
+
+	name := result.Rows[0]["name"].(string)
+	if !strings.HasPrefix(name, strings.Repeat("x", 10)+"...") {
+		t.Errorf("Expected truncated name, got %q", name)
+	}
+	if result.Rows[0]["email"] != "<BLOB 3 bytes>" {
+		t.Errorf("Expected BLOB marker, got %v", result.Rows[0]["email"])
+	}
+}
+
+func TestSampleRows_InvalidColumn(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.SampleRows("test_users", models.SampleOptions{StratifyBy: "missing"}); err == nil {
+		t.Error("Expected error for unknown stratify column")
+	}
+}
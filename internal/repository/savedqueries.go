@@ -0,0 +1,449 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// queriesTable is the sidecar table used when no saved query file is configured
+const queriesTable = "_mcp_queries"
+
+var (
+	savedQueryNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,47}$`)
+	// sqlLiteralPattern matches string literals, quoted identifiers and
+	// comments, which may contain text that looks like a parameter
+	sqlLiteralPattern = regexp.MustCompile(`'(?:[^']|'')*'|"(?:[^"]|"")*"|--[^\n]*|/\*(?:[^*]|\*[^/])*\*/`)
+	parameterPattern  = regexp.MustCompile(`[:@$]([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// QueryStore persists saved queries
+type QueryStore interface {
+	List() ([]models.SavedQuery, error)
+	Set(query models.SavedQuery) error
+	Delete(name string) error
+}
+
+var (
+	_ QueryStore = (*tableQueryStore)(nil)
+	_ QueryStore = (*fileQueryStore)(nil)
+)
+
+// SetQueryStore replaces the default _mcp_queries table store
+func (s *SQLiteDB) SetQueryStore(store QueryStore) {
+	s.queries = store
+}
+
+// ListSavedQueries returns the saved queries ordered by name
+func (s *SQLiteDB) ListSavedQueries() ([]models.SavedQuery, error) {
+	queries, err := s.queries.List()
+	if err != nil {
+		s.logger.Errorf("Failed to list saved queries: %v", err)
+		return nil, fmt.Errorf("failed to retrieve saved queries")
+	}
+	// Stores decode numbers as they please, e.g. float64 from JSON
+	for _, query := range queries {
+		for i, param := range query.Parameters {
+			if value, err := parameterValue(param, param.Default); err == nil {
+				query.Parameters[i].Default = value
+			}
+		}
+	}
+	return queries, nil
+}
+
+// SaveQuery validates a query against the database and stores it, replacing
+// a saved query of the same name
+func (s *SQLiteDB) SaveQuery(query models.SavedQuery) (*models.SavedQuery, error) {
+	s.logger.Debugf("Saving query %s", query.Name)
+
+	if err := s.validateSavedQuery(&query); err != nil {
+		return nil, err
+	}
+	if err := s.queries.Set(query); err != nil {
+		s.logger.Errorf("Failed to store saved query %s: %v", query.Name, err)
+		return nil, fmt.Errorf("failed to store saved query")
+	}
+
+	s.logger.Infof("Saved query %s with %d parameters", query.Name, len(query.Parameters))
+	return &query, nil
+}
+
+// RemoveSavedQuery deletes a saved query
+func (s *SQLiteDB) RemoveSavedQuery(name string) error {
+	if _, err := s.savedQuery(name); err != nil {
+		return err
+	}
+	if err := s.queries.Delete(name); err != nil {
+		s.logger.Errorf("Failed to remove saved query %s: %v", name, err)
+		return fmt.Errorf("failed to remove saved query")
+	}
+	s.logger.Infof("Removed saved query %s", name)
+	return nil
+}
+
+// RunSavedQuery runs a saved query with the arguments bound to its parameters.
+// Omitted parameters take their default, or NULL without one.
+func (s *SQLiteDB) RunSavedQuery(name string, args map[string]any) (*models.QueryResult, error) {
+	s.logger.Debugf("Running saved query %s", name)
+
+	query, err := s.savedQuery(name)
+	if err != nil {
+		return nil, err
+	}
+	for arg := range args {
+		if !slices.ContainsFunc(query.Parameters, func(p models.QueryParameter) bool { return p.Name == arg }) {
+			return nil, fmt.Errorf("saved query %s has no parameter %s", name, arg)
+		}
+	}
+
+	named := make([]any, 0, len(query.Parameters))
+	for _, param := range query.Parameters {
+		value, ok := args[param.Name]
+		if !ok || value == nil {
+			if param.Required {
+				return nil, fmt.Errorf("parameter %s is required", param.Name)
+			}
+			value = param.Default
+		}
+		if value, err = parameterValue(param, value); err != nil {
+			return nil, err
+		}
+		named = append(named, sql.Named(param.Name, value))
+	}
+
+	return s.runQuery(query.SQL, named...)
+}
+
+func (s *SQLiteDB) savedQuery(name string) (*models.SavedQuery, error) {
+	queries, err := s.ListSavedQueries()
+	if err != nil {
+		return nil, err
+	}
+	for _, query := range queries {
+		if query.Name == name {
+			return &query, nil
+		}
+	}
+	return nil, fmt.Errorf("saved query %s does not exist", name)
+}
+
+// validateSavedQuery checks the name, parameters and SQL of a query, which
+// must be a SELECT using exactly the declared parameters, and normalizes the
+// parameter defaults to their type
+func (s *SQLiteDB) validateSavedQuery(query *models.SavedQuery) error {
+	if !savedQueryNamePattern.MatchString(query.Name) {
+		return fmt.Errorf("invalid saved query name %q, use lower case letters, digits and '_' starting with a letter", query.Name)
+	}
+	if strings.TrimSpace(query.Description) == "" {
+		return fmt.Errorf("saved query %s needs a description", query.Name)
+	}
+	if !isSelectQuery(query.SQL) {
+		return fmt.Errorf("saved query %s must be a SELECT, WITH or EXPLAIN query", query.Name)
+	}
+
+	declared := make(map[string]bool)
+	for i := range query.Parameters {
+		param := &query.Parameters[i]
+		if !plainIdentPattern.MatchString(param.Name) {
+			return fmt.Errorf("invalid parameter name %q", param.Name)
+		}
+		if declared[param.Name] {
+			return fmt.Errorf("parameter %s is declared twice", param.Name)
+		}
+		declared[param.Name] = true
+		switch param.Type {
+		case models.ParameterString, models.ParameterInteger, models.ParameterNumber, models.ParameterBoolean:
+		default:
+			return fmt.Errorf("parameter %s has invalid type %q, expected %s, %s, %s or %s", param.Name, param.Type,
+				models.ParameterString, models.ParameterInteger, models.ParameterNumber, models.ParameterBoolean)
+		}
+		if param.Default != nil {
+			value, err := parameterValue(*param, param.Default)
+			if err != nil {
+				return fmt.Errorf("default of %w", err)
+			}
+			param.Default = value
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, match := range parameterPattern.FindAllStringSubmatch(sqlLiteralPattern.ReplaceAllString(query.SQL, ""), -1) {
+		if !declared[match[1]] {
+			return fmt.Errorf("the SQL uses parameter %s, which is not declared", match[1])
+		}
+		used[match[1]] = true
+	}
+	for _, param := range query.Parameters {
+		if !used[param.Name] {
+			return fmt.Errorf("parameter %s is not used in the SQL, refer to it as :%s", param.Name, param.Name)
+		}
+	}
+
+	// Preparing checks the syntax and that the tables and columns exist
+	stmt, err := s.reader.PrepareContext(context.Background(), query.SQL)
+	if err != nil {
+		return fmt.Errorf("invalid SQL for saved query %s: %v", query.Name, err)
+	}
+	stmt.Close()
+	return nil
+}
+
+// parameterValue converts an argument, decoded from JSON or YAML, to the type
+// of its parameter
+func parameterValue(param models.QueryParameter, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch param.Type {
+	case models.ParameterString:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case models.ParameterInteger:
+		switch v := value.(type) {
+		case int:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+				return int64(v), nil
+			}
+		}
+	case models.ParameterNumber:
+		switch v := value.(type) {
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+	case models.ParameterBoolean:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("parameter %s must be of type %s, got %v", param.Name, param.Type, value)
+}
+
+// tableQueryStore keeps saved queries in the _mcp_queries table of the
+// database itself. The table is only created on the first write.
+type tableQueryStore struct {
+	db     *sql.DB // Writer
+	reader *sql.DB
+}
+
+func (t *tableQueryStore) exists() (bool, error) {
+	var count int
+	err := t.reader.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name = ?", queriesTable).Scan(&count)
+	return count > 0, err
+}
+
+func (t *tableQueryStore) List() ([]models.SavedQuery, error) {
+	exists, err := t.exists()
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	rows, err := t.reader.Query("SELECT name, description, sql, parameters FROM " + queriesTable + " ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var queries []models.SavedQuery
+	for rows.Next() {
+		var q models.SavedQuery
+		var parameters sql.NullString
+		if err := rows.Scan(&q.Name, &q.Description, &q.SQL, &parameters); err != nil {
+			return nil, err
+		}
+		if parameters.Valid && parameters.String != "" {
+			if err := json.Unmarshal([]byte(parameters.String), &q.Parameters); err != nil {
+				return nil, fmt.Errorf("invalid parameters for saved query %s: %w", q.Name, err)
+			}
+		}
+		queries = append(queries, q)
+	}
+
+	return queries, rows.Err()
+}
+
+func (t *tableQueryStore) Set(q models.SavedQuery) error {
+	_, err := t.db.Exec(`CREATE TABLE IF NOT EXISTS ` + queriesTable + ` (
+		name TEXT PRIMARY KEY,
+		description TEXT NOT NULL,
+		sql TEXT NOT NULL,
+		parameters TEXT
+	)`)
+	if err != nil {
+		return err
+	}
+
+	var parameters sql.NullString
+	if len(q.Parameters) > 0 {
+		encoded, err := json.Marshal(q.Parameters)
+		if err != nil {
+			return err
+		}
+		parameters = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	_, err = t.db.Exec(`INSERT OR REPLACE INTO `+queriesTable+` (name, description, sql, parameters) VALUES (?, ?, ?, ?)`,
+		q.Name, q.Description, q.SQL, parameters)
+	return err
+}
+
+func (t *tableQueryStore) Delete(name string) error {
+	exists, err := t.exists()
+	if err != nil || !exists {
+		return err
+	}
+	_, err = t.db.Exec("DELETE FROM "+queriesTable+" WHERE name = ?", name)
+	return err
+}
+
+// fileQueryStore keeps saved queries in a YAML file of the form
+//
+//	queries:
+//	  orders_by_status:
+//	    description: Orders with a status, newest first
+//	    sql: SELECT * FROM orders WHERE status = :status ORDER BY created_at DESC
+//	    parameters:
+//	      - name: status
+//	        type: string
+//	        required: true
+type fileQueryStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+type queryFile struct {
+	Queries map[string]*savedQueryEntry `yaml:"queries"`
+}
+
+type savedQueryEntry struct {
+	Description string                `yaml:"description"`
+	SQL         string                `yaml:"sql"`
+	Parameters  []queryParameterEntry `yaml:"parameters,omitempty"`
+}
+
+type queryParameterEntry struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"`
+	Description string `yaml:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+	Default     any    `yaml:"default,omitempty"`
+}
+
+// NewFileQueryStore returns a store backed by a YAML file, which is created
+// on the first write if it does not exist
+func NewFileQueryStore(path string) QueryStore {
+	return &fileQueryStore{path: path}
+}
+
+func (f *fileQueryStore) List() ([]models.SavedQuery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []models.SavedQuery
+	for _, name := range sortedKeys(file.Queries) {
+		entry := file.Queries[name]
+		if entry == nil {
+			continue
+		}
+		query := models.SavedQuery{Name: name, Description: entry.Description, SQL: entry.SQL}
+		for _, p := range entry.Parameters {
+			query.Parameters = append(query.Parameters, models.QueryParameter(p))
+		}
+		queries = append(queries, query)
+	}
+	return queries, nil
+}
+
+func (f *fileQueryStore) Set(q models.SavedQuery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	entry := &savedQueryEntry{Description: q.Description, SQL: q.SQL}
+	for _, p := range q.Parameters {
+		entry.Parameters = append(entry.Parameters, queryParameterEntry(p))
+	}
+	file.Queries[q.Name] = entry
+
+	return f.save(file)
+}
+
+func (f *fileQueryStore) Delete(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Queries[name]; !ok {
+		return nil
+	}
+	delete(file.Queries, name)
+
+	return f.save(file)
+}
+
+func (f *fileQueryStore) load() (*queryFile, error) {
+	file := &queryFile{}
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		file.Queries = make(map[string]*savedQueryEntry)
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("invalid saved query file %s: %w", f.path, err)
+	}
+	if file.Queries == nil {
+		file.Queries = make(map[string]*savedQueryEntry)
+	}
+	return file, nil
+}
+
+func (f *fileQueryStore) save(file *queryFile) error {
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated file behind
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func usersByNameQuery() models.SavedQuery {
+	return models.SavedQuery{
+		Name:        "users_by_name",
+		Description: "Users whose name starts with a prefix",
+		SQL:         "SELECT id, name FROM test_users WHERE name LIKE :prefix || '%' ORDER BY id LIMIT :max",
+		Parameters: []models.QueryParameter{
+			{Name: "prefix", Type: models.ParameterString, Required: true},
+			{Name: "max", Type: models.ParameterInteger, Default: 10},
+		},
+	}
+}
+
+func TestSavedQueries_TableStore(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.Execute("INSERT INTO test_users (name) VALUES ('Ann'), ('Andy'), ('Bob')"); err != nil {
+		t.Fatalf("Failed to insert rows: %v", err)
+	}
+
+	saved, err := db.SaveQuery(usersByNameQuery())
+	if err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+	if saved.Parameters[1].Default != int64(10) {
+		t.Errorf("Expected the default to be normalized to int64, got %T", saved.Parameters[1].Default)
+	}
+
+	// JSON numbers arrive as float64
+	result, err := db.RunSavedQuery("users_by_name", map[string]any{"prefix": "An", "max": float64(1)})
+	if err != nil {
+		t.Fatalf("RunSavedQuery failed: %v", err)
+	}
+	if result.Count != 1 || result.Rows[0]["name"] != "Ann" {
+		t.Errorf("Expected Ann only, got %+v", result.Rows)
+	}
+
+	result, err = db.RunSavedQuery("users_by_name", map[string]any{"prefix": "An"})
+	if err != nil {
+		t.Fatalf("RunSavedQuery failed: %v", err)
+	}
+	if result.Count != 2 {
+		t.Errorf("Expected the default limit to apply, got %d rows", result.Count)
+	}
+
+	if _, err := db.RunSavedQuery("users_by_name", map[string]any{}); err == nil {
+		t.Error("Expected an error for a missing required parameter")
+	}
+	if _, err := db.RunSavedQuery("users_by_name", map[string]any{"prefix": "A", "max": 1.5}); err == nil {
+		t.Error("Expected an error for a fractional integer")
+	}
+	if _, err := db.RunSavedQuery("users_by_name", map[string]any{"prefix": "A", "other": 1}); err == nil {
+		t.Error("Expected an error for an unknown parameter")
+	}
+
+	tables, err := db.GetSchema()
+	if err != nil {
+		t.Fatalf("GetSchema failed: %v", err)
+	}
+	if len(tables) != 1 {
+		t.Errorf("Expected the saved query table to be hidden, got %d tables", len(tables))
+	}
+
+	if err := db.RemoveSavedQuery("users_by_name"); err != nil {
+		t.Fatalf("RemoveSavedQuery failed: %v", err)
+	}
+	queries, err := db.ListSavedQueries()
+	if err != nil || len(queries) != 0 {
+		t.Errorf("Expected no saved queries after removal, got %v (%v)", queries, err)
+	}
+	if err := db.RemoveSavedQuery("users_by_name"); err == nil {
+		t.Error("Expected an error removing a missing saved query")
+	}
+}
+
+func TestSavedQueries_Validation(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tests := []struct {
+		name   string
+		modify func(q *models.SavedQuery)
+		want   string
+	}{
+		{"invalid name", func(q *models.SavedQuery) { q.Name = "Users-By-Name" }, "invalid saved query name"},
+		{"no description", func(q *models.SavedQuery) { q.Description = " " }, "needs a description"},
+		{"not a select", func(q *models.SavedQuery) { q.SQL = "DELETE FROM test_users WHERE name = :prefix AND id < :max" }, "must be a SELECT"},
+		{"unknown type", func(q *models.SavedQuery) { q.Parameters[0].Type = "date" }, "invalid type"},
+		{"duplicate parameter", func(q *models.SavedQuery) { q.Parameters[1].Name = "prefix" }, "declared twice"},
+		{"bad default", func(q *models.SavedQuery) { q.Parameters[1].Default = "ten" }, "must be of type integer"},
+		{"undeclared placeholder", func(q *models.SavedQuery) { q.SQL += " OFFSET :skip" }, "not declared"},
+		{"unused parameter", func(q *models.SavedQuery) { q.SQL = "SELECT * FROM test_users WHERE name = :prefix" }, "not used"},
+		{"missing table", func(q *models.SavedQuery) { q.SQL = strings.Replace(q.SQL, "test_users", "nope", 1) }, "invalid SQL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := usersByNameQuery()
+			tt.modify(&query)
+			_, err := db.SaveQuery(query)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Placeholders inside literals and comments are not parameters
+	query := usersByNameQuery()
+	query.SQL = "SELECT ':skip' AS s, name FROM test_users -- :other\nWHERE name LIKE :prefix LIMIT :max"
+	if _, err := db.SaveQuery(query); err != nil {
+		t.Errorf("Expected placeholders in literals to be ignored, got %v", err)
+	}
+}
+
+func TestSavedQueries_FileStore(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "queries.yaml")
+	db.SetQueryStore(NewFileQueryStore(path))
+
+	if _, err := db.SaveQuery(usersByNameQuery()); err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read saved query file: %v", err)
+	}
+	if !strings.Contains(string(data), "users_by_name:") || !strings.Contains(string(data), "default: 10") {
+		t.Errorf("Unexpected saved query file:\n%s", data)
+	}
+
+	// A hand written file is read back with its parameters
+	other := NewFileQueryStore(path)
+	queries, err := other.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(queries) != 1 || len(queries[0].Parameters) != 2 || !queries[0].Parameters[0].Required {
+		t.Errorf("Expected the saved query with its parameters, got %+v", queries)
+	}
+
+	if _, err := db.RunSavedQuery("users_by_name", map[string]any{"prefix": "x"}); err != nil {
+		t.Errorf("RunSavedQuery failed with a default read from the file: %v", err)
+	}
+
+	if err := db.RemoveSavedQuery("users_by_name"); err != nil {
+		t.Fatalf("RemoveSavedQuery failed: %v", err)
+	}
+	if queries, _ := other.List(); len(queries) != 0 {
+		t.Errorf("Expected no saved queries after removal, got %+v", queries)
+	}
+}
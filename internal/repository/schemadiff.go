@@ -0,0 +1,473 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"go.uber.org/zap"
+)
+
+// NewReadOnlySQLiteDB opens an existing database file in read-only mode
+func NewReadOnlySQLiteDB(dbPath string, logger *zap.SugaredLogger) (*SQLiteDB, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("database file %s does not exist", dbPath)
+	}
+
+	db, err := NewSQLiteDB(readOnlyDSN(dbPath), logger)
+	if err != nil {
+		return nil, err
+	}
+	db.path = dbPath
+	return db, nil
+}
+
+// readOnlyDSN builds a SQLite URI filename opening path read-only
+func readOnlyDSN(path string) string {
+	return fileDSN(path, "ro")
+}
+
+// fileDSN builds a SQLite URI filename opening path in a mode such as ro or rw
+func fileDSN(path, mode string) string {
+	escaped := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(path)
+	return "file:" + escaped + "?mode=" + mode
+}
+
+// Path returns the database file the repository was opened with
+func (s *SQLiteDB) Path() string {
+	return s.path
+}
+
+// DiffSchemaWithFile compares this database with another database file. By
+// default the migration turns this database's schema into the other's, with
+// fromOther set it turns the other schema into this one.
+func (s *SQLiteDB) DiffSchemaWithFile(otherPath string, fromOther bool) (*models.SchemaDiff, error) {
+	other, err := NewReadOnlySQLiteDB(otherPath, s.logger)
+	if err != nil {
+		s.logger.Errorf("Failed to open database %s: %v", otherPath, err)
+		return nil, fmt.Errorf("failed to open database %s", otherPath)
+	}
+	defer other.Close()
+
+	if fromOther {
+		return other.DiffSchema(s)
+	}
+	return s.DiffSchema(other)
+}
+
+// DiffSchema compares the schema of s (source) with target and generates the
+// migration that brings the source schema to the target schema. Changes that
+// ALTER TABLE cannot express are migrated with the table rebuild procedure.
+func (s *SQLiteDB) DiffSchema(target *SQLiteDB) (*models.SchemaDiff, error) {
+	s.logger.Debugf("Diffing schema of %s against %s", s.path, target.path)
+
+	source, err := s.schemaSnapshot()
+	if err != nil {
+		s.logger.Errorf("Failed to read schema of %s: %v", s.path, err)
+		return nil, fmt.Errorf("failed to retrieve schema of %s", s.path)
+	}
+	dest, err := target.schemaSnapshot()
+	if err != nil {
+		s.logger.Errorf("Failed to read schema of %s: %v", target.path, err)
+		return nil, fmt.Errorf("failed to retrieve schema of %s", target.path)
+	}
+
+	diff, err := diffSchemas(source, dest)
+	if err != nil {
+		s.logger.Errorf("Failed to generate migration: %v", err)
+		return nil, fmt.Errorf("failed to generate migration")
+	}
+	diff.Source = s.path
+	diff.Target = target.path
+
+	s.logger.Infof("Schema diff computed, identical: %t, migration_statements: %d", diff.Identical, len(diff.Migration))
+	return diff, nil
+}
+
+// GetSchemaObjects returns the SQL definitions of all user tables, indexes,
+// triggers and views, excluding internal and automatically created objects
+func (s *SQLiteDB) GetSchemaObjects() ([]models.SchemaObject, error) {
+	rows, err := s.reader.Query(`SELECT type, name, tbl_name, sql FROM sqlite_master
+		WHERE sql IS NOT NULL
+		AND name NOT LIKE 'sqlite_%' AND tbl_name NOT LIKE 'sqlite_%'
+		AND name NOT LIKE '\_mcp\_%' ESCAPE '\' AND tbl_name NOT LIKE '\_mcp\_%' ESCAPE '\'
+		ORDER BY CASE type WHEN 'table' THEN 0 WHEN 'index' THEN 1 WHEN 'trigger' THEN 2 ELSE 3 END, name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []models.SchemaObject
+	for rows.Next() {
+		var object models.SchemaObject
+		if err := rows.Scan(&object.Type, &object.Name, &object.TableName, &object.SQL); err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, rows.Err()
+}
+
+type schemaSnapshot struct {
+	tables      map[string]models.Table
+	objects     map[string]models.SchemaObject // keyed by type and lower-cased name
+	foreignKeys bool                           // Connections to the database enforce foreign keys
+}
+
+func (s *SQLiteDB) schemaSnapshot() (*schemaSnapshot, error) {
+	tables, err := s.mainTables()
+	if err != nil {
+		return nil, err
+	}
+	objects, err := s.GetSchemaObjects()
+	if err != nil {
+		return nil, err
+	}
+	var foreignKeys bool
+	if err := s.reader.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		return nil, err
+	}
+
+	snapshot := &schemaSnapshot{
+		tables:      make(map[string]models.Table, len(tables)),
+		objects:     make(map[string]models.SchemaObject, len(objects)),
+		foreignKeys: foreignKeys,
+	}
+	for _, table := range tables {
+		snapshot.tables[strings.ToLower(table.Name)] = table
+	}
+	for _, object := range objects {
+		snapshot.objects[objectKey(object)] = object
+	}
+	return snapshot, nil
+}
+
+func (snapshot *schemaSnapshot) tableSQL(name string) string {
+	return snapshot.objects["table:"+strings.ToLower(name)].SQL
+}
+
+// tableObjects returns the definitions of one type attached to a table
+func (snapshot *schemaSnapshot) tableObjects(objectType, table string) []string {
+	var definitions []string
+	for _, key := range sortedKeys(snapshot.objects) {
+		object := snapshot.objects[key]
+		if object.Type == objectType && strings.EqualFold(object.TableName, table) {
+			definitions = append(definitions, object.SQL)
+		}
+	}
+	return definitions
+}
+
+func diffSchemas(source, target *schemaSnapshot) (*models.SchemaDiff, error) {
+	diff := &models.SchemaDiff{}
+
+	for _, name := range sortedKeys(target.tables) {
+		if _, ok := source.tables[name]; !ok {
+			diff.AddedTables = append(diff.AddedTables, target.tables[name].Name)
+		}
+	}
+	for _, name := range sortedKeys(source.tables) {
+		if _, ok := target.tables[name]; !ok {
+			diff.RemovedTables = append(diff.RemovedTables, source.tables[name].Name)
+		}
+	}
+
+	rebuilt := make(map[string]bool)
+	for _, name := range sortedKeys(source.tables) {
+		dest, ok := target.tables[name]
+		if !ok {
+			continue
+		}
+		tableDiff := compareTables(source.tables[name], dest, source.tableSQL(name), target.tableSQL(name))
+		if tableDiff == nil {
+			continue
+		}
+		diff.ChangedTables = append(diff.ChangedTables, *tableDiff)
+		if tableDiff.RequiresRebuild {
+			rebuilt[name] = true
+		}
+	}
+
+	for _, key := range sortedKeys(target.objects) {
+		object := target.objects[key]
+		if object.Type == "table" {
+			continue
+		}
+		existing, ok := source.objects[key]
+		switch {
+		case !ok:
+			diff.AddedObjects = append(diff.AddedObjects, object)
+		case normalizeSQL(existing.SQL) != normalizeSQL(object.SQL):
+			diff.ChangedObjects = append(diff.ChangedObjects, object)
+		}
+	}
+	for _, key := range sortedKeys(source.objects) {
+		object := source.objects[key]
+		if _, ok := target.objects[key]; !ok && object.Type != "table" {
+			diff.RemovedObjects = append(diff.RemovedObjects, object)
+		}
+	}
+
+	diff.Identical = len(diff.AddedTables) == 0 && len(diff.RemovedTables) == 0 && len(diff.ChangedTables) == 0 &&
+		len(diff.AddedObjects) == 0 && len(diff.RemovedObjects) == 0 && len(diff.ChangedObjects) == 0
+	if diff.Identical {
+		return diff, nil
+	}
+
+	migration, err := migrationStatements(diff, source, target, rebuilt)
+	if err != nil {
+		return nil, err
+	}
+	diff.Migration = wrapMigration(migration, len(rebuilt) > 0, source.foreignKeys)
+
+	return diff, nil
+}
+
+// migrationStatements orders the DDL so that dependent objects are dropped
+// before and recreated after the tables they belong to
+func migrationStatements(diff *models.SchemaDiff, source, target *schemaSnapshot, rebuilt map[string]bool) ([]string, error) {
+	var statements []string
+
+	removedTables := make(map[string]bool)
+	for _, name := range diff.RemovedTables {
+		removedTables[strings.ToLower(name)] = true
+	}
+
+	// Views may reference rebuilt tables, so with any rebuild all views are recreated
+	var views []models.SchemaObject
+	if len(rebuilt) > 0 {
+		for _, key := range sortedKeys(source.objects) {
+			if object := source.objects[key]; object.Type == "view" {
+				views = append(views, object)
+			}
+		}
+	} else {
+		views = append(views, diff.RemovedObjects...)
+		views = append(views, diff.ChangedObjects...)
+	}
+	for _, object := range views {
+		if object.Type == "view" {
+			statements = append(statements, "DROP VIEW IF EXISTS "+quoteIdent(object.Name))
+		}
+	}
+
+	// Indexes and triggers of removed or rebuilt tables go away with the table
+	dropped := append(append([]models.SchemaObject{}, diff.RemovedObjects...), diff.ChangedObjects...)
+	for _, object := range dropped {
+		table := strings.ToLower(object.TableName)
+		if object.Type == "view" || removedTables[table] || rebuilt[table] {
+			continue
+		}
+		statements = append(statements, fmt.Sprintf("DROP %s IF EXISTS %s", strings.ToUpper(object.Type), quoteIdent(object.Name)))
+	}
+
+	for _, name := range diff.RemovedTables {
+		statements = append(statements, "DROP TABLE IF EXISTS "+quoteIdent(name))
+	}
+
+	for _, name := range diff.AddedTables {
+		statements = append(statements, target.tableSQL(name))
+	}
+
+	for _, tableDiff := range diff.ChangedTables {
+		name := strings.ToLower(tableDiff.Name)
+		if !tableDiff.RequiresRebuild {
+			for _, col := range tableDiff.AddedColumns {
+				definition, ok := addedColumnDefinition(target.tableSQL(name), col.Name)
+				if !ok {
+					definition = columnDefinition(col)
+				}
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", quoteIdent(tableDiff.Name), definition))
+			}
+			continue
+		}
+
+		rebuild := tableRebuild{
+			Table:     tableDiff.Name,
+			CreateSQL: target.tableSQL(name),
+			Indexes:   target.tableObjects("index", name),
+			Triggers:  target.tableObjects("trigger", name),
+		}
+		// Copy the columns both definitions share
+		sourceTable := source.tables[name]
+		for _, col := range target.tables[name].Columns {
+			if hasColumn(&sourceTable, col.Name) {
+				rebuild.Columns = append(rebuild.Columns, col.Name)
+				rebuild.Sources = append(rebuild.Sources, quoteIdent(col.Name))
+			}
+		}
+		rebuildStatements, err := rebuild.statements()
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, rebuildStatements...)
+	}
+
+	created := append(append([]models.SchemaObject{}, diff.AddedObjects...), diff.ChangedObjects...)
+	for _, object := range created {
+		if object.Type == "view" || rebuilt[strings.ToLower(object.TableName)] {
+			continue
+		}
+		statements = append(statements, object.SQL)
+	}
+
+	if len(rebuilt) > 0 {
+		views = nil
+		for _, key := range sortedKeys(target.objects) {
+			if object := target.objects[key]; object.Type == "view" {
+				views = append(views, object)
+			}
+		}
+	} else {
+		views = created
+	}
+	for _, object := range views {
+		if object.Type == "view" {
+			statements = append(statements, object.SQL)
+		}
+	}
+
+	return statements, nil
+}
+
+// compareTables returns the differences between two versions of a table, or nil if equal
+func compareTables(source, target models.Table, sourceSQL, targetSQL string) *models.TableDiff {
+	diff := &models.TableDiff{Name: target.Name}
+
+	for _, col := range target.Columns {
+		if !hasColumn(&source, col.Name) {
+			diff.AddedColumns = append(diff.AddedColumns, col)
+		}
+	}
+	for _, col := range source.Columns {
+		if !hasColumn(&target, col.Name) {
+			diff.RemovedColumns = append(diff.RemovedColumns, col.Name)
+			continue
+		}
+		dest := findColumnByName(target, col.Name)
+		if from, to := describeColumn(col), describeColumn(dest); from != to {
+			diff.ChangedColumns = append(diff.ChangedColumns, models.ColumnChange{Name: col.Name, From: from, To: to})
+		}
+	}
+
+	diff.ForeignKeysChanged = strings.Join(describeForeignKeys(source), "\n") != strings.Join(describeForeignKeys(target), "\n")
+
+	if sourceSQL != "" && targetSQL != "" {
+		a, errA := renameCreateTable(sourceSQL, "t")
+		b, errB := renameCreateTable(targetSQL, "t")
+		diff.DefinitionChanged = errA == nil && errB == nil && normalizeSQL(a) != normalizeSQL(b)
+	}
+
+	structural := len(diff.AddedColumns) > 0 || len(diff.RemovedColumns) > 0 || len(diff.ChangedColumns) > 0 || diff.ForeignKeysChanged
+	if !structural && !diff.DefinitionChanged {
+		return nil
+	}
+
+	// Only pure column additions that ADD COLUMN supports avoid a rebuild. A
+	// definition change without column differences means constraints changed.
+	diff.RequiresRebuild = len(diff.RemovedColumns) > 0 || len(diff.ChangedColumns) > 0 || diff.ForeignKeysChanged ||
+		(!structural && diff.DefinitionChanged)
+	for _, col := range diff.AddedColumns {
+		if _, ok := addedColumnDefinition(targetSQL, col.Name); !ok || !canAddColumn(col) {
+			diff.RequiresRebuild = true
+		}
+	}
+
+	return diff
+}
+
+// canAddColumn applies the restrictions of ALTER TABLE ADD COLUMN
+func canAddColumn(col models.Column) bool {
+	if col.PrimaryKey {
+		return false
+	}
+	if col.DefaultValue == nil {
+		return !col.NotNull
+	}
+	value := strings.ToUpper(strings.TrimSpace(*col.DefaultValue))
+	if value == "NULL" && col.NotNull {
+		return false
+	}
+	// Non-constant defaults are not allowed
+	return !strings.HasPrefix(value, "(") && !strings.HasPrefix(value, "CURRENT_")
+}
+
+// addedColumnDefinition returns the definition of a column as written in a
+// CREATE TABLE statement, keeping constraints such as CHECK and COLLATE. ok is
+// false when the definition is not found or uses a constraint ADD COLUMN rejects.
+func addedColumnDefinition(createSQL, column string) (string, bool) {
+	def, err := parseTableDefinition(createSQL)
+	if err != nil {
+		return "", false
+	}
+	i := def.column(column)
+	if i < 0 {
+		return "", false
+	}
+	name, rest, _ := splitColumnDefinition(def.defs[i])
+	for tail := rest; tail != ""; {
+		var token string
+		token, tail = readToken(tail)
+		if keyword := strings.ToUpper(token); keyword == "UNIQUE" || keyword == "STORED" {
+			return "", false
+		}
+	}
+	return strings.TrimSpace(quoteIdent(name) + " " + rest), true
+}
+
+// columnDefinition renders a column as used in CREATE TABLE or ADD COLUMN
+func columnDefinition(col models.Column) string {
+	definition := quoteIdent(col.Name)
+	if col.Type != "" {
+		definition += " " + col.Type
+	}
+	if col.PrimaryKey {
+		definition += " PRIMARY KEY"
+	}
+	if col.NotNull {
+		definition += " NOT NULL"
+	}
+	if col.DefaultValue != nil {
+		definition += " DEFAULT " + *col.DefaultValue
+	}
+	return definition
+}
+
+func describeColumn(col models.Column) string {
+	description := strings.ToUpper(col.Type)
+	if col.NotNull {
+		description += " NOT NULL"
+	}
+	if col.DefaultValue != nil {
+		description += " DEFAULT " + *col.DefaultValue
+	}
+	if col.PrimaryKey {
+		description += " PRIMARY KEY"
+	}
+	return strings.TrimSpace(description)
+}
+
+func describeForeignKeys(table models.Table) []string {
+	descriptions := make([]string, 0, len(table.ForeignKeys))
+	for _, fk := range table.ForeignKeys {
+		descriptions = append(descriptions, strings.ToLower(fmt.Sprintf("%d:%s->%s(%s) %s %s %s",
+			fk.Seq, fk.From, fk.Table, fk.To, fk.OnUpdate, fk.OnDelete, fk.Match)))
+	}
+	sort.Strings(descriptions)
+	return descriptions
+}
+
+func findColumnByName(table models.Table, name string) models.Column {
+	for _, col := range table.Columns {
+		if strings.EqualFold(col.Name, name) {
+			return col
+		}
+	}
+	return models.Column{}
+}
+
+func objectKey(object models.SchemaObject) string {
+	return object.Type + ":" + strings.ToLower(object.Name)
+}
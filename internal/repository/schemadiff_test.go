@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+)
+
+func newTestDBWithSchema(t *testing.T, name string, statements ...string) *SQLiteDB {
+	t.Helper()
+
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), name), logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, statement := range statements {
+		if _, err := db.db.Exec(statement); err != nil {
+			t.Fatalf("Failed to execute %q: %v", statement, err)
+		}
+	}
+	return db
+}
+
+func applyMigration(t *testing.T, db *SQLiteDB, statements []string) {
+	t.Helper()
+
+	// Pin one connection, the migration relies on PRAGMA and transaction state
+	conn, err := db.db.Conn(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	defer conn.Close()
+
+	for _, statement := range statements {
+		if _, err := conn.ExecContext(t.Context(), statement); err != nil {
+			t.Fatalf("Migration statement %q failed: %v", statement, err)
+		}
+	}
+}
+
+func TestDiffSchema(t *testing.T) {
+	source := newTestDBWithSchema(t, "source.db",
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age TEXT)`,
+		`CREATE TABLE legacy (id INTEGER PRIMARY KEY)`,
+		`CREATE INDEX idx_users_name ON users(name)`,
+		`CREATE VIEW adults AS SELECT * FROM users WHERE age >= 18`,
+		`INSERT INTO users (name, age) VALUES ('John', '30')`,
+	)
+	target := newTestDBWithSchema(t, "target.db",
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INTEGER)`,
+		`CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id), note TEXT)`,
+		`CREATE INDEX idx_users_name ON users(name)`,
+		`CREATE INDEX idx_orders_user ON orders(user_id)`,
+		`CREATE VIEW adults AS SELECT * FROM users WHERE age >= 18`,
+		`CREATE TRIGGER users_touch AFTER UPDATE ON users BEGIN SELECT 1; END`,
+	)
+
+	diff, err := source.DiffSchema(target)
+	if err != nil {
+		t.Fatalf("DiffSchema failed: %v", err)
+	}
+
+	if diff.Identical {
+		t.Fatal("Expected schemas to differ")
+	}
+	if len(diff.AddedTables) != 1 || diff.AddedTables[0] != "orders" {
+		t.Errorf("Expected orders to be added, got %v", diff.AddedTables)
+	}
+	if len(diff.RemovedTables) != 1 || diff.RemovedTables[0] != "legacy" {
+		t.Errorf("Expected legacy to be removed, got %v", diff.RemovedTables)
+	}
+	if len(diff.ChangedTables) != 1 || !diff.ChangedTables[0].RequiresRebuild {
+		t.Fatalf("Expected users to require a rebuild, got %+v", diff.ChangedTables)
+	}
+	if len(diff.ChangedTables[0].ChangedColumns) != 2 {
+		t.Errorf("Expected name and age to change, got %+v", diff.ChangedTables[0].ChangedColumns)
+	}
+
+	applyMigration(t, source, diff.Migration)
+
+	after, err := source.DiffSchema(target)
+	if err != nil {
+		t.Fatalf("DiffSchema failed: %v", err)
+	}
+	if !after.Identical {
+		t.Errorf("Expected schemas to match after migration, got %+v", after)
+	}
+
+	result, err := source.Query("SELECT name, age FROM users")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if result.Count != 1 || result.Rows[0]["age"] != int64(30) {
+		t.Errorf("Expected data to survive the rebuild with converted types, got %v", result.Rows)
+	}
+}
+
+func TestDiffSchema_AddColumn(t *testing.T) {
+	source := newTestDBWithSchema(t, "source.db",
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)`,
+	)
+	target := newTestDBWithSchema(t, "target.db",
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, active INTEGER NOT NULL DEFAULT 1)`,
+	)
+
+	diff, err := source.DiffSchema(target)
+	if err != nil {
+		t.Fatalf("DiffSchema failed: %v", err)
+	}
+
+	if len(diff.ChangedTables) != 1 || diff.ChangedTables[0].RequiresRebuild {
+		t.Fatalf("Expected a column addition without rebuild, got %+v", diff.ChangedTables)
+	}
+
+	expected := []string{
+		"BEGIN TRANSACTION",
+		`ALTER TABLE "users" ADD COLUMN "active" INTEGER NOT NULL DEFAULT 1`,
+		"COMMIT",
+	}
+	if len(diff.Migration) != len(expected) {
+		t.Fatalf("Expected migration %v, got %v", expected, diff.Migration)
+	}
+	for i := range expected {
+		if diff.Migration[i] != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], diff.Migration[i])
+		}
+	}
+}
+
+func TestDiffSchema_ForeignKeyCheck(t *testing.T) {
+	schema := []string{
+		`CREATE TABLE parents (id INTEGER PRIMARY KEY)`,
+		`CREATE TABLE children (id INTEGER PRIMARY KEY, parent_id INTEGER REFERENCES parents(id))`,
+	}
+	path := newTestDBWithSchema(t, "source.db", append(schema,
+		`INSERT INTO parents VALUES (1)`,
+		`INSERT INTO children VALUES (1, 1)`,
+	)...).Path()
+	// The rebuilt children reference a new, empty table
+	target := newTestDBWithSchema(t, "target.db",
+		`CREATE TABLE parents (id INTEGER PRIMARY KEY)`,
+		`CREATE TABLE owners (id INTEGER PRIMARY KEY)`,
+		`CREATE TABLE children (id INTEGER PRIMARY KEY, parent_id INTEGER REFERENCES owners(id))`,
+	)
+
+	// Foreign keys are only switched off and on again where they are enforced
+	plain := newTestDBWithSchema(t, "plain.db", schema...)
+	diff, err := plain.DiffSchema(target)
+	if err != nil {
+		t.Fatalf("DiffSchema failed: %v", err)
+	}
+	for _, statement := range diff.Migration {
+		if strings.HasPrefix(statement, "PRAGMA foreign_keys") {
+			t.Errorf("Expected no foreign_keys pragma without enforcement, got %q", statement)
+		}
+	}
+
+	source := openWithOptions(t, path, models.DatabaseOptions{Connection: models.ConnectionOptions{ForeignKeys: true}})
+	diff, err = source.DiffSchema(target)
+	if err != nil {
+		t.Fatalf("DiffSchema failed: %v", err)
+	}
+	if first, last := diff.Migration[0], diff.Migration[len(diff.Migration)-1]; first != "PRAGMA foreign_keys=OFF" || last != "PRAGMA foreign_keys=ON" {
+		t.Errorf("Expected foreign keys to be switched off around the migration, got %v", diff.Migration)
+	}
+
+	conn, err := source.db.Conn(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	defer conn.Close()
+	var failed error
+	for _, statement := range diff.Migration {
+		if _, failed = conn.ExecContext(t.Context(), statement); failed != nil {
+			break
+		}
+	}
+	if failed == nil || !strings.Contains(failed.Error(), "foreign_key_violations") {
+		t.Fatalf("Expected the foreign key check to fail the migration, got %v", failed)
+	}
+	conn.ExecContext(t.Context(), "ROLLBACK")
+	conn.ExecContext(t.Context(), "PRAGMA foreign_keys=ON")
+
+	if exists, _ := source.tableExists("owners"); exists {
+		t.Error("Expected the failed migration not to be committed")
+	}
+}
+
+func TestDiffSchemaWithFile(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	diff, err := db.DiffSchemaWithFile(db.Path(), false)
+	if err != nil {
+		t.Fatalf("DiffSchemaWithFile failed: %v", err)
+	}
+	if !diff.Identical {
+		t.Errorf("Expected a database to be identical to itself, got %+v", diff)
+	}
+
+	if _, err := db.DiffSchemaWithFile(filepath.Join(os.TempDir(), "does-not-exist.db"), false); err == nil {
+		t.Error("Expected error for missing database file")
+	}
+}
+
+func TestRenameCreateTable(t *testing.T) {
+	cases := map[string]string{
+		`CREATE TABLE users (id INTEGER)`:                   `CREATE TABLE "tmp" (id INTEGER)`,
+		`CREATE TABLE IF NOT EXISTS "my users"(id INTEGER)`: `CREATE TABLE IF NOT EXISTS "tmp"(id INTEGER)`,
+		"create table `users` (id)":                         `create table "tmp" (id)`,
+		`CREATE TABLE [users] (id)`:                         `CREATE TABLE "tmp" (id)`,
+	}
+	for input, expected := range cases {
+		renamed, err := renameCreateTable(input, "tmp")
+		if err != nil {
+			t.Fatalf("renameCreateTable(%q) failed: %v", input, err)
+		}
+		if renamed != expected {
+			t.Errorf("renameCreateTable(%q) = %q, expected %q", input, renamed, expected)
+		}
+	}
+}
+
+func TestDiffSchema_AddColumnConstraints(t *testing.T) {
+	source := newTestDBWithSchema(t, "source.db",
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)`,
+		`INSERT INTO users (name) VALUES ('Ann')`,
+	)
+	target := newTestDBWithSchema(t, "target.db",
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, code TEXT CHECK (length(code) < 5) COLLATE NOCASE)`,
+	)
+
+	diff, err := source.DiffSchema(target)
+	if err != nil {
+		t.Fatalf("DiffSchema failed: %v", err)
+	}
+	if len(diff.ChangedTables) != 1 || diff.ChangedTables[0].RequiresRebuild {
+		t.Fatalf("Expected a column addition without rebuild, got %+v", diff.ChangedTables)
+	}
+	want := `ALTER TABLE "users" ADD COLUMN "code" TEXT CHECK (length(code) < 5) COLLATE NOCASE`
+	if len(diff.Migration) != 3 || diff.Migration[1] != want {
+		t.Fatalf("Expected %q, got %v", want, diff.Migration)
+	}
+
+	applyMigration(t, source, diff.Migration)
+	if _, err := source.Execute("UPDATE users SET code = 'toolong'"); err == nil {
+		t.Error("Expected the CHECK constraint to be added with the column")
+	}
+	source.Execute("UPDATE users SET code = 'abc'")
+	result, err := source.Query("SELECT name FROM users WHERE code = 'ABC'")
+	if err != nil || result.Count != 1 {
+		t.Errorf("Expected the NOCASE collation to be added with the column, got %+v (%v)", result, err)
+	}
+
+	// UNIQUE columns cannot be added and need a rebuild
+	unique := newTestDBWithSchema(t, "unique.db",
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, code TEXT UNIQUE)`,
+	)
+	diff, err = newTestDBWithSchema(t, "plain.db", `CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)`).DiffSchema(unique)
+	if err != nil {
+		t.Fatalf("DiffSchema failed: %v", err)
+	}
+	if len(diff.ChangedTables) != 1 || !diff.ChangedTables[0].RequiresRebuild {
+		t.Errorf("Expected a UNIQUE column to require a rebuild, got %+v", diff.ChangedTables)
+	}
+}
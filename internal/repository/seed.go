@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/rvarun11/sqlite-mcp/internal/models"
+	"go.uber.org/zap"
+)
+
+// CreateDatabase creates a database file that does not exist yet and runs the
+// seed scripts in it in order. If a script fails the file is removed again, so
+// the next attempt starts from scratch rather than from a half-seeded database.
+func CreateDatabase(dbPath string, scripts []string, logger *zap.SugaredLogger) (*models.LoadResult, error) {
+	if _, err := os.Stat(dbPath); err == nil {
+		return nil, fmt.Errorf("database %s already exists", dbPath)
+	}
+
+	db, err := NewSQLiteDB(dbPath, logger)
+	if err != nil {
+		return nil, err
+	}
+	result, err := db.Seed(scripts)
+	db.Close()
+	if err != nil {
+		removeDatabaseFiles(dbPath)
+		return nil, err
+	}
+	return result, nil
+}
+
+// Seed runs SQL scripts in order, e.g. to fill a new or in-memory database.
+// The scripts come from the server's configuration, so the database's policy
+// does not apply to them.
+func (s *SQLiteDB) Seed(scripts []string) (*models.LoadResult, error) {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to get connection: %v", err)
+		return nil, fmt.Errorf("failed to seed database")
+	}
+	defer conn.Close()
+
+	result := &models.LoadResult{}
+	err = s.withoutAuthorizer(conn, func() error {
+		for _, script := range scripts {
+			f, err := os.Open(script)
+			if err != nil {
+				return fmt.Errorf("failed to open seed script: %w", err)
+			}
+			count, err := s.execScript(ctx, conn, f, "seed script")
+			f.Close()
+			result.Statements += count
+			if err != nil {
+				return fmt.Errorf("%s: %w", script, err)
+			}
+			s.logger.Infof("Ran seed script %s, %d statements", script, count)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Tables = s.countTables(ctx, conn)
+	return result, nil
+}
+
+// removeDatabaseFiles removes a database file with its journal and WAL files
+func removeDatabaseFiles(dbPath string) {
+	for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+		os.Remove(dbPath + suffix)
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rvarun11/sqlite-mcp/internal/logger"
+)
+
+func writeScript(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	return path
+}
+
+func TestCreateDatabase_RunsSeedScripts(t *testing.T) {
+	dir := t.TempDir()
+	schema := writeScript(t, dir, "01_schema.sql", "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);\nCREATE INDEX idx_users_name ON users (name);\n")
+	data := writeScript(t, dir, "02_data.sql", "-- Seed data\nINSERT INTO users (name) VALUES ('Percy'), ('Annabeth');\n")
+	dbPath := filepath.Join(dir, "app.db")
+
+	result, err := CreateDatabase(dbPath, []string{schema, data}, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("CreateDatabase failed: %v", err)
+	}
+	if result.Statements != 3 || result.Tables != 1 {
+		t.Errorf("Expected 3 statements and 1 table, got %+v", result)
+	}
+
+	db, err := NewSQLiteDB(dbPath, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	if n := countRows(t, db, "SELECT COUNT(*) FROM users"); n != 2 {
+		t.Errorf("Expected 2 users, got %d", n)
+	}
+
+	if _, err := CreateDatabase(dbPath, nil, logger.NewTestLogger()); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected an error for an existing database, got: %v", err)
+	}
+}
+
+func TestCreateDatabase_RemovesDatabaseWhenSeedFails(t *testing.T) {
+	dir := t.TempDir()
+	schema := writeScript(t, dir, "schema.sql", "CREATE TABLE users (id INTEGER PRIMARY KEY);\n")
+	broken := writeScript(t, dir, "broken.sql", "INSERT INTO users VALUES (1);\nINSERT INTO missing VALUES (1);\n")
+	dbPath := filepath.Join(dir, "app.db")
+
+	_, err := CreateDatabase(dbPath, []string{schema, broken}, logger.NewTestLogger())
+	if err == nil || !strings.Contains(err.Error(), "broken.sql") || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("Expected the error to name the script and line, got: %v", err)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the database to be removed, got: %v", err)
+	}
+
+	// Without scripts the database is created empty
+	if _, err := CreateDatabase(dbPath, nil, logger.NewTestLogger()); err != nil {
+		t.Fatalf("CreateDatabase failed: %v", err)
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Errorf("Expected the database to exist, got: %v", err)
+	}
+}
@@ -1,53 +1,127 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/rvarun11/sqlite-mcp/internal/models"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 )
 
 var _ Repository = (*SQLiteDB)(nil)
 
+// ErrTimeout is returned for statements interrupted by the query timeout
+var ErrTimeout = errors.New("time limit exceeded")
+
 type SQLiteDB struct {
+	// db holds the single writer connection, so writers queue here instead
+	// of failing with SQLITE_BUSY; reader is a pool of query_only connections
 	db     *sql.DB
+	reader *sql.DB
 	logger *zap.SugaredLogger
+	path   string
+
+	// versionConn is a dedicated connection that never writes, so its
+	// PRAGMA data_version changes whenever any other connection commits
+	versionMu   sync.Mutex
+	versionConn *sql.Conn
+
+	// memoryConn keeps an in-memory database alive, SQLite drops it when
+	// its last connection closes
+	memoryConn *sql.Conn
+
+	profileMu    sync.Mutex
+	profileCache map[string]*models.TableProfile
+
+	annotations AnnotationStore
+	queries     QueryStore
+
+	migrationsDir string
+	importDirs    []string
+	exportDir     string
+	backupDir     string
+	backupKeep    int
+
+	readOnly    bool
+	access      *accessControl // Holds the policy read by every connection's authorizer
+	attachments []models.Attachment
+	connection  models.ConnectionOptions
+
+	// limits is shared with the database's branches, so changing the limits
+	// of a database also applies to them
+	limits *atomic.Pointer[models.Limits]
 }
 
 func NewSQLiteDB(dbPath string, logger *zap.SugaredLogger) (*SQLiteDB, error) {
+	return NewSQLiteDBWithOptions(dbPath, models.DatabaseOptions{}, logger)
+}
+
+func openSQLiteDB(writerDriver, readerDriver driver.Driver, dsn string, readers int, logger *zap.SugaredLogger) (*SQLiteDB, error) {
 	// Open SQLite database directly
-	db, err := sql.Open("sqlite3", dbPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
+	db := sql.OpenDB(&connector{driver: writerDriver, dsn: dsn})
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
 		db.Close() // Clean up on ping failure
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
+	db.SetMaxOpenConns(1)
 
-	// Configure connection pool
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
+	reader := sql.OpenDB(&connector{driver: readerDriver, dsn: dsn})
+	if err := reader.Ping(); err != nil {
+		db.Close()
+		reader.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	reader.SetMaxOpenConns(readers + pinnedReaders)
+	reader.SetMaxIdleConns(min(readers, maxIdleReaders) + pinnedReaders)
 
-	logger.Infof("Connected to SQLite database: %v", dbPath)
+	logger.Infof("Connected to SQLite database: %v", dsn)
 
 	return &SQLiteDB{
-		db:     db,
-		logger: logger,
+		db:           db,
+		reader:       reader,
+		logger:       logger,
+		path:         dsn,
+		profileCache: make(map[string]*models.TableProfile),
+		annotations:  &tableAnnotationStore{db: db, reader: reader},
+		queries:      &tableQueryStore{db: db, reader: reader},
+		limits:       &atomic.Pointer[models.Limits]{},
 	}, nil
 }
 
+// GetSchema describes the tables of the database followed by the tables of
+// the attached databases, which are named alias.table
 func (s *SQLiteDB) GetSchema() ([]models.Table, error) {
 	s.logger.Debug("Get database schema")
 
+	tables, err := s.mainTables()
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range s.attachments {
+		attached, err := s.attachedTables(attachment.Alias)
+		if err != nil {
+			s.logger.Errorf("Failed to retrieve tables of attached database %s: %v", attachment.Alias, err)
+			return nil, fmt.Errorf("failed to retrieve table information")
+		}
+		tables = append(tables, attached...)
+	}
+	return tables, nil
+}
+
+// mainTables describes the tables of the main database only
+func (s *SQLiteDB) mainTables() ([]models.Table, error) {
 	var tableNames []string
 
-	rows, err := s.db.Query("SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'")
+	rows, err := s.reader.Query(`SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' AND name NOT LIKE '\_mcp\_%' ESCAPE '\'`)
 	if err != nil {
 		s.logger.Errorf("Failed to retrieve table names: %v", err)
 		return nil, fmt.Errorf("failed to retrieve table information")
@@ -81,14 +155,32 @@ func (s *SQLiteDB) GetSchema() ([]models.Table, error) {
 		tables = append(tables, *tableInfo)
 	}
 
+	annotations, err := s.annotations.List()
+	if err != nil {
+		s.logger.Errorf("Failed to load annotations: %v", err)
+	} else {
+		applyAnnotations(tables, annotations)
+	}
+
 	s.logger.Infof("Successfully retrieved table information, table_count: %d", len(tables))
 	return tables, nil
 }
 
 func (s *SQLiteDB) getTableInfo(tableName string) (*models.Table, error) {
+	return s.schemaTableInfo("", tableName)
+}
+
+// schemaTableInfo describes a table of an attached schema, or the table found
+// by unqualified lookup when schema is empty
+func (s *SQLiteDB) schemaTableInfo(schema, tableName string) (*models.Table, error) {
+	pragma := "PRAGMA "
+	if schema != "" {
+		pragma += quoteIdent(schema) + "."
+	}
+
 	// Get column information
 	var columns []models.Column
-	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", tableName))
+	rows, err := s.reader.Query(fmt.Sprintf(pragma+"table_info(%s)", quoteIdent(tableName)))
 	if err != nil {
 		return nil, err
 	}
@@ -106,10 +198,11 @@ func (s *SQLiteDB) getTableInfo(tableName string) (*models.Table, error) {
 		}
 
 		column := models.Column{
-			Name:       name,
-			Type:       dataType,
-			NotNull:    notNull == 1,
-			PrimaryKey: pk == 1,
+			Name:            name,
+			Type:            dataType,
+			NotNull:         notNull == 1,
+			PrimaryKey:      pk > 0,
+			PrimaryKeyIndex: pk, // pk is the 1-based position within a composite key
 		}
 
 		if defaultValue.Valid {
@@ -121,7 +214,7 @@ func (s *SQLiteDB) getTableInfo(tableName string) (*models.Table, error) {
 
 	// Get index information
 	var indexes []string
-	indexRows, err := s.db.Query(fmt.Sprintf("PRAGMA index_list(%s)", tableName))
+	indexRows, err := s.reader.Query(fmt.Sprintf(pragma+"index_list(%s)", quoteIdent(tableName)))
 	if err != nil {
 		return nil, err
 	}
@@ -145,7 +238,7 @@ func (s *SQLiteDB) getTableInfo(tableName string) (*models.Table, error) {
 	}
 
 	var foreignKeys []models.ForeignKey
-	fkRows, err := s.db.Query(fmt.Sprintf("PRAGMA foreign_key_list(%s)", tableName))
+	fkRows, err := s.reader.Query(fmt.Sprintf(pragma+"foreign_key_list(%s)", quoteIdent(tableName)))
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +246,8 @@ func (s *SQLiteDB) getTableInfo(tableName string) (*models.Table, error) {
 
 	for fkRows.Next() {
 		var id, seq int
-		var table, from, to, onUpdate, onDelete, match string
+		var table, from, onUpdate, onDelete, match string
+		var to sql.NullString // NULL when the parent's primary key is referenced implicitly
 
 		err := fkRows.Scan(&id, &seq, &table, &from, &to, &onUpdate, &onDelete, &match)
 		if err != nil {
@@ -165,7 +259,7 @@ func (s *SQLiteDB) getTableInfo(tableName string) (*models.Table, error) {
 			Seq:      seq,
 			Table:    table,
 			From:     from,
-			To:       to,
+			To:       to.String,
 			OnUpdate: onUpdate,
 			OnDelete: onDelete,
 			Match:    match,
@@ -188,11 +282,19 @@ func (s *SQLiteDB) Query(sqlQuery string) (*models.QueryResult, error) {
 	if !isSelectQuery(sqlQuery) {
 		return nil, fmt.Errorf("only SELECT queries are allowed for query operations")
 	}
+	return s.runQuery(sqlQuery)
+}
 
-	rows, err := s.db.Query(sqlQuery)
+// runQuery runs a query on the read pool within the limits, binding args
+func (s *SQLiteDB) runQuery(sqlQuery string, args ...any) (*models.QueryResult, error) {
+	limits := s.Limits()
+	ctx, cancel := statementContext(limits)
+	defer cancel()
+
+	rows, err := s.reader.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		s.logger.Errorf("Query execution failed: %v", err)
-		return nil, fmt.Errorf("query execution failed")
+		return nil, limitError(ctx, limits, "query execution failed")
 	}
 	defer rows.Close()
 
@@ -202,7 +304,13 @@ func (s *SQLiteDB) Query(sqlQuery string) (*models.QueryResult, error) {
 	}
 
 	var results []map[string]any
+	truncated := false
 	for rows.Next() {
+		if limits.MaxRows > 0 && len(results) == limits.MaxRows {
+			truncated = true
+			break
+		}
+
 		values := make([]any, len(columns))
 		valuePtrs := make([]any, len(columns))
 
@@ -220,11 +328,16 @@ func (s *SQLiteDB) Query(sqlQuery string) (*models.QueryResult, error) {
 		}
 		results = append(results, row)
 	}
+	if err := rows.Err(); err != nil {
+		s.logger.Errorf("Query execution failed: %v", err)
+		return nil, limitError(ctx, limits, "query execution failed")
+	}
 
 	result := &models.QueryResult{
-		Columns: columns,
-		Rows:    results,
-		Count:   len(results),
+		Columns:   columns,
+		Rows:      results,
+		Count:     len(results),
+		Truncated: truncated,
 	}
 
 	s.logger.Infof("Query executed successfully, rows_returned: %d", len(results))
@@ -238,10 +351,30 @@ func (s *SQLiteDB) Execute(sqlQuery string) (*models.ExecuteResult, error) {
 		return nil, fmt.Errorf("SELECT queries should use the query operation instead")
 	}
 
-	result, err := s.db.Exec(sqlQuery)
+	if err := s.checkClientStatement(sqlQuery); err != nil {
+		return nil, err
+	}
+
+	limits := s.Limits()
+	ctx, cancel := statementContext(limits)
+	defer cancel()
+
+	var result sql.Result
+	var err error
+	if vacuumPattern.MatchString(sqlQuery) {
+		result, err = s.vacuum()
+	} else {
+		err = s.asClient(ctx, func(conn *sql.Conn) error {
+			result, err = conn.ExecContext(ctx, sqlQuery)
+			return err
+		})
+	}
 	if err != nil {
 		s.logger.Errorf("Statement execution failed: %v", err)
-		return nil, fmt.Errorf("statement execution failed")
+		if notAllowed := s.statementError(sqlQuery, err); notAllowed != nil {
+			return nil, notAllowed
+		}
+		return nil, limitError(ctx, limits, "statement execution failed")
 	}
 
 	rowsAffected, _ := result.RowsAffected()
@@ -258,7 +391,75 @@ func (s *SQLiteDB) Execute(sqlQuery string) (*models.ExecuteResult, error) {
 	return executeResult, nil
 }
 
+// Limits returns the limits of the query and execute operations
+func (s *SQLiteDB) Limits() models.Limits {
+	if limits := s.limits.Load(); limits != nil {
+		return *limits
+	}
+	return models.Limits{}
+}
+
+// SetLimits replaces the limits of the query and execute operations, which
+// applies to the statements started afterwards
+func (s *SQLiteDB) SetLimits(limits models.Limits) {
+	s.limits.Store(&limits)
+}
+
+// statementContext returns the context a statement runs in, which is canceled
+// after the query timeout
+func statementContext(limits models.Limits) (context.Context, context.CancelFunc) {
+	if limits.QueryTimeout > 0 {
+		return context.WithTimeout(context.Background(), limits.QueryTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// limitError returns ErrTimeout when a statement was interrupted by the query
+// timeout and an error with the message otherwise
+func limitError(ctx context.Context, limits models.Limits, message string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: the statement ran longer than %s", ErrTimeout, limits.QueryTimeout)
+	}
+	return errors.New(message)
+}
+
+// dataVersion returns the current PRAGMA data_version as seen by the dedicated version connection
+func (s *SQLiteDB) dataVersion() (int64, error) {
+	s.versionMu.Lock()
+	defer s.versionMu.Unlock()
+
+	ctx := context.Background()
+	if s.versionConn == nil {
+		conn, err := s.reader.Conn(ctx)
+		if err != nil {
+			return 0, err
+		}
+		s.versionConn = conn
+	}
+
+	var version int64
+	if err := s.versionConn.QueryRowContext(ctx, "PRAGMA data_version").Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
 func (s *SQLiteDB) Close() error {
+	s.versionMu.Lock()
+	if s.versionConn != nil {
+		s.versionConn.Close()
+		s.versionConn = nil
+	}
+	s.versionMu.Unlock()
+
+	if s.memoryConn != nil {
+		s.memoryConn.Close()
+		s.memoryConn = nil
+	}
+
+	if s.reader != nil {
+		s.reader.Close()
+	}
 	if s.db != nil {
 		return s.db.Close()
 	}
@@ -273,6 +474,29 @@ func isSelectQuery(query string) bool {
 		strings.HasPrefix(trimmed, "EXPLAIN")
 }
 
+// quoteIdent quotes an SQLite identifier so it can be safely embedded in a statement
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// tableExists reports whether a user table with the given name exists
+func (s *SQLiteDB) tableExists(tableName string) (bool, error) {
+	var count int
+	err := s.reader.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name = ?", tableName).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SchemaVersion returns the schema version of the main database, which
+// changes whenever its schema does
+func (s *SQLiteDB) SchemaVersion() (int64, error) {
+	var version int64
+	err := s.reader.QueryRow("PRAGMA schema_version").Scan(&version)
+	return version, err
+}
+
 // TODO: To be improved with more complex sanitization logic
 // sanitizeQuery sanitizes the SQL query string
 func sanitizeQuery(query string) string {
@@ -1,10 +1,13 @@
 package repository
 
 import (
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/rvarun11/sqlite-mcp/internal/logger"
+	"github.com/rvarun11/sqlite-mcp/internal/models"
 )
 
 func setupTestDB(t *testing.T) (*SQLiteDB, func()) {
@@ -125,3 +128,35 @@ func TestQueryValidation(t *testing.T) {
 		t.Error("Expected error for SELECT in ExecuteDatabase")
 	}
 }
+
+func TestQueryLimits(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, name := range []string{"a", "b", "c"} {
+		if _, err := db.Execute("INSERT INTO test_users (name) VALUES ('" + name + "')"); err != nil {
+			t.Fatalf("Failed to insert test data: %v", err)
+		}
+	}
+
+	db.SetLimits(models.Limits{MaxRows: 2})
+	result, err := db.Query("SELECT * FROM test_users")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if result.Count != 2 || !result.Truncated {
+		t.Errorf("Expected 2 rows marked truncated, got %d (truncated: %t)", result.Count, result.Truncated)
+	}
+	if result, err := db.Query("SELECT * FROM test_users LIMIT 2"); err != nil || result.Truncated {
+		t.Errorf("Expected a result within the limit not to be truncated, got %+v (%v)", result, err)
+	}
+
+	db.SetLimits(models.Limits{QueryTimeout: 50 * time.Millisecond})
+	slow := "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n) SELECT COUNT(*) FROM n"
+	if _, err := db.Query(slow); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected the query to time out, got: %v", err)
+	}
+	if _, err := db.Execute("CREATE TABLE big AS " + slow); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected the statement to time out, got: %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tableConstraintKeywords start a table constraint rather than a column definition
+var tableConstraintKeywords = map[string]bool{
+	"CONSTRAINT": true, "PRIMARY": true, "UNIQUE": true, "CHECK": true, "FOREIGN": true,
+}
+
+// columnConstraintKeywords end the type name of a column definition
+var columnConstraintKeywords = map[string]bool{
+	"CONSTRAINT": true, "PRIMARY": true, "NOT": true, "NULL": true, "UNIQUE": true, "CHECK": true,
+	"DEFAULT": true, "COLLATE": true, "REFERENCES": true, "GENERATED": true, "AS": true,
+}
+
+// tableDefinition is a CREATE TABLE statement split into its column
+// definitions and table constraints, keeping their original text
+type tableDefinition struct {
+	prefix string   // "CREATE TABLE name ("
+	defs   []string // Column definitions followed by table constraints
+	suffix string   // ")" and table options such as WITHOUT ROWID or STRICT
+}
+
+func parseTableDefinition(createSQL string) (*tableDefinition, error) {
+	loc := createTablePrefix.FindStringIndex(createSQL)
+	if loc == nil {
+		return nil, fmt.Errorf("not a CREATE TABLE statement: %s", sanitizeQuery(createSQL))
+	}
+
+	open := loc[1]
+	for open < len(createSQL) && isSpace(createSQL[open]) {
+		open++
+	}
+	if open >= len(createSQL) || createSQL[open] != '(' {
+		return nil, fmt.Errorf("CREATE TABLE ... AS SELECT is not supported")
+	}
+
+	def := &tableDefinition{prefix: createSQL[:open+1]}
+	depth, start := 1, open+1
+	for i := open + 1; i < len(createSQL); i++ {
+		switch c := createSQL[i]; c {
+		case '\'', '"', '`', '[':
+			i = skipQuoted(createSQL, i)
+		case '-':
+			if strings.HasPrefix(createSQL[i:], "--") {
+				if end := strings.IndexByte(createSQL[i:], '\n'); end >= 0 {
+					i += end
+				} else {
+					i = len(createSQL)
+				}
+			}
+		case '/':
+			if strings.HasPrefix(createSQL[i:], "/*") {
+				if end := strings.Index(createSQL[i+2:], "*/"); end >= 0 {
+					i += end + 3
+				} else {
+					i = len(createSQL)
+				}
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				def.defs = append(def.defs, strings.TrimSpace(createSQL[start:i]))
+				def.suffix = createSQL[i:]
+				return def, nil
+			}
+		case ',':
+			if depth == 1 {
+				def.defs = append(def.defs, strings.TrimSpace(createSQL[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("unbalanced parentheses in CREATE TABLE statement")
+}
+
+func (d *tableDefinition) String() string {
+	return d.prefix + "\n  " + strings.Join(d.defs, ",\n  ") + "\n" + d.suffix
+}
+
+// column returns the index of the definition of the named column, or -1
+func (d *tableDefinition) column(name string) int {
+	for i, def := range d.defs {
+		if colName, _, ok := splitColumnDefinition(def); ok && strings.EqualFold(colName, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// columnNames returns the column names in definition order
+func (d *tableDefinition) columnNames() []string {
+	var names []string
+	for _, def := range d.defs {
+		if name, _, ok := splitColumnDefinition(def); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// insertColumn adds a column definition after the existing columns, before any table constraint
+func (d *tableDefinition) insertColumn(def string) {
+	i := 0
+	for i < len(d.defs) {
+		if _, _, ok := splitColumnDefinition(d.defs[i]); !ok {
+			break
+		}
+		i++
+	}
+	d.defs = append(d.defs[:i], append([]string{def}, d.defs[i:]...)...)
+}
+
+// splitColumnDefinition returns the unquoted column name and the rest of a
+// column definition; ok is false for table constraints
+func splitColumnDefinition(def string) (name, rest string, ok bool) {
+	token, rest := readToken(def)
+	if token == "" || tableConstraintKeywords[strings.ToUpper(token)] {
+		return "", "", false
+	}
+	return unquoteIdent(token), strings.TrimSpace(rest), true
+}
+
+// splitColumnType splits the part of a column definition after its name into
+// the type name and the column constraints
+func splitColumnType(rest string) (typeName, constraints string) {
+	remaining := strings.TrimSpace(rest)
+	end := 0
+	for {
+		token, after := readToken(remaining[end:])
+		if token == "" || columnConstraintKeywords[strings.ToUpper(token)] {
+			break
+		}
+		end = len(remaining) - len(after)
+		// Type arguments such as VARCHAR(10) or DECIMAL(10, 2)
+		trimmed := strings.TrimLeft(remaining[end:], " \t\r\n")
+		if strings.HasPrefix(trimmed, "(") {
+			if closing := strings.IndexByte(trimmed, ')'); closing >= 0 {
+				end = len(remaining) - len(trimmed) + closing + 1
+			}
+		}
+	}
+	return strings.TrimSpace(remaining[:end]), strings.TrimSpace(remaining[end:])
+}
+
+// constraintName returns the name given with CONSTRAINT name, if any
+func constraintName(def string) string {
+	token, rest := readToken(def)
+	if !strings.EqualFold(token, "CONSTRAINT") {
+		return ""
+	}
+	name, _ := readToken(rest)
+	return unquoteIdent(name)
+}
+
+// referencesIdent reports whether an identifier appears as a token in a definition
+func referencesIdent(def, name string) bool {
+	remaining := def
+	for {
+		token, rest := readToken(remaining)
+		if rest == remaining {
+			return false
+		}
+		if token != "" && strings.EqualFold(unquoteIdent(token), name) {
+			return true
+		}
+		remaining = rest
+	}
+}
+
+// readToken reads one identifier, keyword or quoted token, skipping whitespace
+// and comments. Punctuation is skipped and returned as an empty token.
+func readToken(s string) (token, rest string) {
+	i := skipSpaceAndComments(s, 0)
+	if i >= len(s) {
+		return "", ""
+	}
+
+	switch c := s[i]; {
+	case c == '"' || c == '`' || c == '[' || c == '\'':
+		end := skipQuoted(s, i) + 1
+		if c == '\'' {
+			return "", s[end:]
+		}
+		return s[i:end], s[end:]
+	case isWordChar(c):
+		end := i
+		for end < len(s) && isWordChar(s[end]) {
+			end++
+		}
+		return s[i:end], s[end:]
+	}
+	return "", s[i+1:]
+}
+
+// skipQuoted returns the index of the character closing the quoted token starting at i
+func skipQuoted(s string, i int) int {
+	closing := s[i]
+	if closing == '[' {
+		closing = ']'
+	}
+	for j := i + 1; j < len(s); j++ {
+		if s[j] != closing {
+			continue
+		}
+		// Doubled quotes escape themselves
+		if closing != ']' && j+1 < len(s) && s[j+1] == closing {
+			j++
+			continue
+		}
+		return j
+	}
+	return len(s) - 1
+}
+
+func unquoteIdent(token string) string {
+	if len(token) < 2 {
+		return token
+	}
+	switch first, last := token[0], token[len(token)-1]; {
+	case first == '"' && last == '"':
+		return strings.ReplaceAll(token[1:len(token)-1], `""`, `"`)
+	case first == '`' && last == '`':
+		return strings.ReplaceAll(token[1:len(token)-1], "``", "`")
+	case first == '[' && last == ']':
+		return token[1 : len(token)-1]
+	}
+	return token
+}
+
+func skipSpaceAndComments(s string, i int) int {
+	for i < len(s) {
+		switch {
+		case isSpace(s[i]):
+			i++
+		case strings.HasPrefix(s[i:], "--"):
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				return len(s)
+			}
+			i += end + 1
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return len(s)
+			}
+			i += end + 4
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}